@@ -0,0 +1,27 @@
+//kage:unit pixels
+
+//go:build ignore
+
+package main
+
+// Direction is either (1, 0) for a horizontal pass or (0, 1) for a vertical pass.
+var Direction vec2
+var Radius float
+
+func Fragment(_ vec4, srcPos vec2, color vec4) vec4 {
+	sigma := max(Radius*0.5, 0.001)
+	k := -1.0 / (2.0 * sigma * sigma)
+
+	sum := vec4(0)
+	weightSum := 0.0
+	for i := -16; i <= 16; i++ {
+		x := float(i)
+		if abs(x) > Radius {
+			continue
+		}
+		w := exp(x * x * k)
+		sum += imageSrc0At(srcPos+Direction*x) * w
+		weightSum += w
+	}
+	return (sum / weightSum) * color
+}
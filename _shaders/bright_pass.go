@@ -0,0 +1,17 @@
+//kage:unit pixels
+
+//go:build ignore
+
+package main
+
+var Threshold float
+
+func Fragment(_ vec4, srcPos vec2, _ vec4) vec4 {
+	c := imageSrc0UnsafeAt(srcPos)
+	brightness := max(max(c.r, c.g), c.b)
+	if brightness <= Threshold {
+		return vec4(0)
+	}
+	// Keep only the part of the color that exceeds the threshold.
+	return c * ((brightness - Threshold) / brightness)
+}
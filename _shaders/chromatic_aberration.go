@@ -0,0 +1,19 @@
+//kage:unit pixels
+
+//go:build ignore
+
+package main
+
+var Offset float
+
+func Fragment(_ vec4, srcPos vec2, color vec4) vec4 {
+	// The shift is increasing towards the image edges.
+	size := imageSrc0Size()
+	dir := (srcPos - imageSrc0Origin() - size*0.5) / (size * 0.5)
+	shift := dir * Offset
+
+	c := imageSrc0At(srcPos)
+	r := imageSrc0At(srcPos + shift).r
+	b := imageSrc0At(srcPos - shift).b
+	return vec4(r, c.g, b, c.a) * color
+}
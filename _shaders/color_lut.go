@@ -0,0 +1,33 @@
+//kage:unit pixels
+
+//go:build ignore
+
+package main
+
+// CellSize is a number of colors per channel in the LUT.
+// The LUT image is expected to be a (CellSize*CellSize)x(CellSize) strip.
+var CellSize float
+var Intensity float
+
+func Fragment(_ vec4, srcPos vec2, color vec4) vec4 {
+	c := imageSrc0UnsafeAt(srcPos)
+	if c.a == 0 {
+		return vec4(0)
+	}
+	rgb := clamp(c.rgb/c.a, 0, 1)
+
+	maxIndex := CellSize - 1
+	blue := rgb.b * maxIndex
+	cell1 := floor(blue)
+	cell2 := min(cell1+1, maxIndex)
+
+	origin := imageSrc1Origin()
+	// Sample the texel centers to avoid bleeding between the cells.
+	xy := rgb.rg*maxIndex + 0.5
+	graded1 := imageSrc1At(origin + vec2(cell1*CellSize+xy.x, xy.y)).rgb
+	graded2 := imageSrc1At(origin + vec2(cell2*CellSize+xy.x, xy.y)).rgb
+	graded := mix(graded1, graded2, fract(blue))
+
+	result := mix(rgb, graded, Intensity)
+	return vec4(result*c.a, c.a) * color
+}
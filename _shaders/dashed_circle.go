@@ -13,7 +13,7 @@ var OutlineColor vec4
 var FillColor vec4
 var FillOffset float
 
-func Fragment(_ vec4, pos vec2, _ vec4) vec4 {
+func Fragment(_ vec4, pos vec2, color vec4) vec4 {
 	origin := imageSrc0Origin()
 	zpos := pos - origin
 	r := Radius
@@ -34,7 +34,7 @@ func Fragment(_ vec4, pos vec2, _ vec4) vec4 {
 		return vec4(0)
 	}
 	if dist >= r-OutlineWidth {
-		return OutlineColor
+		return OutlineColor * color
 	}
-	return FillColor
+	return FillColor * color
 }
@@ -10,7 +10,7 @@ var DotRadius float
 var DotSpacing float
 var Color vec4
 
-func Fragment(pos vec4, _ vec2, _ vec4) vec4 {
+func Fragment(pos vec4, _ vec2, color vec4) vec4 {
 	origin := imageDstOrigin()
 	zpos := quantizeToPixel(pos.xy - origin)
 
@@ -30,7 +30,7 @@ func Fragment(pos vec4, _ vec2, _ vec4) vec4 {
 
 	distanceToDot := distance(zpos, dotCenter)
 	if distanceToDot <= r {
-		return Color
+		return Color * color
 	}
 	return vec4(0)
 }
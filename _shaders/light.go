@@ -0,0 +1,20 @@
+//kage:unit pixels
+
+//go:build ignore
+
+package main
+
+var Radius float
+var Falloff float
+var Color vec4
+
+func Fragment(_ vec4, pos vec2, color vec4) vec4 {
+	origin := imageSrc0Origin()
+	zpos := pos - origin
+
+	dist := distance(zpos, vec2(Radius, Radius))
+	if dist >= Radius {
+		return vec4(0)
+	}
+	return Color * pow(1-dist/Radius, Falloff) * color
+}
@@ -0,0 +1,64 @@
+//kage:unit pixels
+
+//go:build ignore
+
+package main
+
+// Keep in sync with MaxNormalMapLights.
+const maxLights = 8
+
+var LightCount int
+var LightPos [maxLights]vec2
+var LightColor [maxLights]vec3
+var LightRadius [maxLights]float
+var LightHeight float
+
+var Ambient vec3
+var Specular float
+var Shininess float
+
+// DstToWorld and DstToWorldOffset map the dst pixel position
+// (relative to the dst image origin) to the world coordinates.
+// DstToWorld holds the 2x2 matrix rows.
+var DstToWorld vec4
+var DstToWorldOffset vec2
+
+// NormalMatrix maps the texel-space normals to the world space.
+// It holds the 2x2 matrix rows.
+var NormalMatrix vec4
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	c := imageSrc0UnsafeAt(srcPos)
+	if c.a == 0 {
+		return vec4(0)
+	}
+
+	// The normal map uses the "Y up" convention,
+	// while the screen Y axis points down.
+	n := imageSrc1UnsafeAt(srcPos).xyz*2 - 1
+	n.y = -n.y
+	n.xy = vec2(dot(NormalMatrix.xy, n.xy), dot(NormalMatrix.zw, n.xy))
+	n = normalize(n)
+
+	p := dstPos.xy - imageDstOrigin()
+	worldPos := vec2(dot(DstToWorld.xy, p), dot(DstToWorld.zw, p)) + DstToWorldOffset
+	diffuse := Ambient
+	spec := vec3(0)
+	for i := 0; i < maxLights; i++ {
+		if i >= LightCount {
+			break
+		}
+		d := LightPos[i] - worldPos
+		dist := length(d)
+		if dist >= LightRadius[i] {
+			continue
+		}
+		att := 1 - dist/LightRadius[i]
+		l := normalize(vec3(d, LightHeight))
+		diffuse += LightColor[i] * max(dot(n, l), 0) * att
+		h := normalize(l + vec3(0, 0, 1))
+		spec += LightColor[i] * pow(max(dot(n, h), 0), Shininess) * Specular * att
+	}
+
+	return vec4(c.rgb*diffuse+spec*c.a, c.a) * color
+}
@@ -0,0 +1,16 @@
+//kage:unit pixels
+
+//go:build ignore
+
+package main
+
+var PixelSize float
+
+func Fragment(_ vec4, srcPos vec2, color vec4) vec4 {
+	origin := imageSrc0Origin()
+	p := srcPos - origin
+	// Sample the center of the big pixel.
+	center := floor(p/PixelSize)*PixelSize + PixelSize*0.5
+	center = min(center, imageSrc0Size()-0.5)
+	return imageSrc0UnsafeAt(origin+center) * color
+}
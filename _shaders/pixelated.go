@@ -0,0 +1,38 @@
+//kage:unit pixels
+
+//go:build ignore
+
+package main
+
+// Scale is a number of dst pixels per texel.
+var Scale vec2
+
+// Repeat is 1 when the texture should be tiled (wrapped)
+// around the source image region.
+var Repeat float
+
+func wrapTexel(p vec2) vec2 {
+	if Repeat == 0 {
+		return p
+	}
+	origin := imageSrc0Origin()
+	return mod(p-origin, imageSrc0Size()) + origin
+}
+
+func Fragment(_ vec4, srcPos vec2, color vec4) vec4 {
+	// Sample the 4 texels around the dst pixel footprint.
+	// They're only blended near the texel edges,
+	// so the image stays crisp otherwise.
+	p0 := srcPos - 0.5/Scale
+	p1 := srcPos + 0.5/Scale
+	rate := clamp(fract(p1)*Scale, 0, 1)
+
+	p0 = wrapTexel(p0)
+	p1 = wrapTexel(p1)
+	c0 := imageSrc0At(p0)
+	c1 := imageSrc0At(vec2(p1.x, p0.y))
+	c2 := imageSrc0At(vec2(p0.x, p1.y))
+	c3 := imageSrc0At(p1)
+
+	return mix(mix(c0, c1, rate.x), mix(c2, c3, rate.x), rate.y) * color
+}
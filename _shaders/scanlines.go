@@ -0,0 +1,18 @@
+//kage:unit pixels
+
+//go:build ignore
+
+package main
+
+var Spacing float
+var Intensity float
+
+func Fragment(_ vec4, srcPos vec2, color vec4) vec4 {
+	c := imageSrc0UnsafeAt(srcPos)
+
+	y := srcPos.y - imageSrc0Origin().y
+	// A smooth wave: 0 at the line center, 1 between the lines.
+	wave := 0.5 + 0.5*cos((y/Spacing)*2*3.14159265)
+	shade := 1 - Intensity*wave
+	return vec4(c.rgb*shade, c.a) * color
+}
@@ -0,0 +1,14 @@
+//kage:unit pixels
+
+//go:build ignore
+
+package main
+
+var Matrix mat3
+var Intensity float
+
+func Fragment(_ vec4, srcPos vec2, color vec4) vec4 {
+	c := imageSrc0UnsafeAt(srcPos)
+	toned := clamp(Matrix*c.rgb, 0, c.a)
+	return vec4(mix(c.rgb, toned, Intensity), c.a) * color
+}
@@ -0,0 +1,22 @@
+//kage:unit pixels
+
+//go:build ignore
+
+package main
+
+var Radius float
+var Softness float
+var Strength float
+
+func Fragment(_ vec4, srcPos vec2, color vec4) vec4 {
+	c := imageSrc0UnsafeAt(srcPos)
+
+	// Normalize the coordinates, so the center is at (0, 0)
+	// and the corners are at the unit distance.
+	size := imageSrc0Size()
+	p := (srcPos - imageSrc0Origin() - size*0.5) / (size * 0.5)
+	dist := length(p) / sqrt(2.0)
+
+	v := smoothstep(Radius, Radius+Softness, dist) * Strength
+	return vec4(c.rgb*(1-v), c.a) * color
+}
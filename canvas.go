@@ -1,10 +1,49 @@
 package graphics
 
 import (
+	"math"
+
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/quasilyte/gmath"
 )
 
+// CanvasMode controls when the [Canvas] image is redrawn.
+type CanvasMode uint8
+
+const (
+	// CanvasModeRedrawAlways clears the canvas image and redraws
+	// all canvas objects on every Draw call.
+	// It's a default canvas mode.
+	CanvasModeRedrawAlways CanvasMode = iota
+
+	// CanvasModeRedrawDirty is like CanvasModeRedrawAlways, but the
+	// image is redrawn only after the canvas is marked as dirty.
+	// Adding a child or resizing the canvas marks it as dirty automatically,
+	// other changes require a [Canvas.MarkDirty] call.
+	//
+	// This mode is useful for the rarely changing contents.
+	CanvasModeRedrawDirty
+
+	// CanvasModePersistent never clears the canvas image.
+	// The newly added objects are drawn onto the image once
+	// and then they're removed from the canvas (but not disposed).
+	// This way, the drawings accumulate over time.
+	//
+	// This mode is useful for the paint and decal canvases,
+	// like blood splatters and tire tracks.
+	// Use [Canvas.Clear] to wipe the image.
+	CanvasModePersistent
+)
+
+// Canvas renders its objects onto an image and then
+// draws that image like a sprite.
+//
+// The image can be assigned explicitly via SetDstImage or
+// allocated by the canvas itself (see SetSize and SetAutoSize).
+// The canvas objects positions are in the image pixels coordinates.
+//
+// A low-resolution canvas can be upscaled using SetScale;
+// the nearest filter is used by default, so the pixels stay crisp.
 type Canvas struct {
 	Pos gmath.Pos
 
@@ -13,7 +52,12 @@ type Canvas struct {
 	spr       *Sprite
 	container *Container
 
+	mode CanvasMode
+
 	offscreen bool
+	dirty     bool
+	autoSize  bool
+	ownsImage bool
 }
 
 func NewCanvas() *Canvas {
@@ -25,16 +69,130 @@ func NewCanvas() *Canvas {
 	return c
 }
 
+// SetDstImage assigns the image the canvas objects are drawn onto.
+// The canvas doesn't take the ownership of this image,
+// but the image allocated by the canvas itself is released.
 func (c *Canvas) SetDstImage(img *ebiten.Image) {
+	if prev := c.spr.GetImage(); prev != nil && prev != img && c.ownsImage {
+		prev.Deallocate()
+	}
 	c.spr.SetImage(img)
+	c.ownsImage = false
+	c.dirty = true
+}
+
+// GetImage returns the canvas image.
+// It's nil if the canvas has no image yet.
+func (c *Canvas) GetImage() *ebiten.Image {
+	return c.spr.GetImage()
+}
+
+// SetSize makes the canvas allocate an image of the specified size.
+// The previously allocated image is released.
+//
+// In the persistent mode, the current image contents are preserved
+// (they're cropped if the new size is smaller).
+func (c *Canvas) SetSize(width, height int) {
+	if width <= 0 || height <= 0 {
+		panic("canvas size should be positive")
+	}
+	c.resize(width, height)
+}
+
+// IsAutoSize reports whether the canvas image size is managed automatically.
+// Use SetAutoSize to change this flag value.
+func (c *Canvas) IsAutoSize() bool {
+	return c.autoSize
+}
+
+// SetAutoSize changes the AutoSize flag value.
+// Use IsAutoSize to get the current flag value.
+//
+// An auto-sized canvas allocates its image to fit the contents:
+// the image is grown to contain the bounds of all objects that
+// have a BoundsRect method (the [Container] objects are ignored).
+// The image is never shrunk automatically.
+//
+// The image origin is always the canvas (0, 0) point,
+// so the contents at the negative coordinates are clipped.
+func (c *Canvas) SetAutoSize(enabled bool) {
+	c.autoSize = enabled
+	c.dirty = true
+}
+
+// GetMode returns the current canvas redraw mode.
+// Use SetMode to change it.
+func (c *Canvas) GetMode() CanvasMode {
+	return c.mode
+}
+
+// SetMode changes the canvas redraw mode.
+// See [CanvasMode] for more info.
+func (c *Canvas) SetMode(mode CanvasMode) {
+	c.mode = mode
+	c.dirty = true
+}
+
+// MarkDirty makes the canvas redraw its image during the next Draw call.
+// It's only needed for the [CanvasModeRedrawDirty] mode.
+func (c *Canvas) MarkDirty() {
+	c.dirty = true
+}
+
+// Clear wipes the canvas image contents.
+// It also marks the canvas as dirty.
+func (c *Canvas) Clear() {
+	if img := c.spr.GetImage(); img != nil {
+		img.Clear()
+	}
+	c.dirty = true
+}
+
+// GetScale returns the current canvas image scaling factor.
+// Use SetScale to change it.
+func (c *Canvas) GetScale() gmath.Vec {
+	return gmath.Vec{X: c.spr.GetScaleX(), Y: c.spr.GetScaleY()}
+}
+
+// SetScale changes the canvas image scaling factor.
+// Use GetScale to retrieve the current value.
+//
+// The scaling only affects the way the image is drawn,
+// the image itself keeps its size.
+// The image is scaled around the canvas Pos.
+func (c *Canvas) SetScale(scale gmath.Vec) {
+	c.spr.SetScaleX(scale.X)
+	c.spr.SetScaleY(scale.Y)
+}
+
+// GetFilter returns the current canvas image filter.
+// Use SetFilter to change it.
+func (c *Canvas) GetFilter() Filter {
+	return c.spr.GetFilter()
+}
+
+// SetFilter changes the filter used to draw the scaled canvas image.
+// The default filter is FilterNearest.
+func (c *Canvas) SetFilter(f Filter) {
+	c.spr.SetFilter(f)
 }
 
 func (c *Canvas) IsDisposed() bool {
 	return c.container.IsDisposed()
 }
 
+// Dispose marks the canvas as disposed.
+// The image allocated by the canvas itself is released,
+// while the image assigned via SetDstImage is left intact.
 func (c *Canvas) Dispose() {
 	c.container.Dispose()
+	if c.ownsImage {
+		if img := c.spr.GetImage(); img != nil {
+			img.Deallocate()
+		}
+		c.spr.image = nil
+		c.ownsImage = false
+	}
 }
 
 func (c *Canvas) IsVisible() bool {
@@ -53,12 +211,50 @@ func (c *Canvas) SetOffscreen(offscreen bool) {
 	c.offscreen = offscreen
 }
 
+// SetClipRect assigns a clipping rectangle to the canvas objects.
+// The rect is in the canvas image coordinates.
+// See [Container.SetClipRect].
+func (c *Canvas) SetClipRect(rect gmath.Rect) {
+	c.container.SetClipRect(rect)
+}
+
+// GetClipRect returns the current clipping rectangle.
+// Use SetClipRect to change it.
+func (c *Canvas) GetClipRect() gmath.Rect {
+	return c.container.GetClipRect()
+}
+
+// SetMask assigns an alpha mask to the canvas objects.
+// See [Container.SetMask].
+func (c *Canvas) SetMask(mask Object) {
+	c.container.SetMask(mask)
+}
+
+// GetMask returns the current canvas alpha mask.
+// Use SetMask to change it.
+func (c *Canvas) GetMask() Object {
+	return c.container.GetMask()
+}
+
+// IsMaskInverted reports whether the canvas mask is inverted.
+// Use SetMaskInverted to change this flag value.
+func (c *Canvas) IsMaskInverted() bool {
+	return c.container.IsMaskInverted()
+}
+
+// SetMaskInverted changes the mask Inverted flag value.
+// See [Container.SetMaskInverted].
+func (c *Canvas) SetMaskInverted(inverted bool) {
+	c.container.SetMaskInverted(inverted)
+}
+
 func (c *Canvas) Draw(dst *ebiten.Image) {
 	c.DrawWithOptions(dst, DrawOptions{})
 }
 
 func (c *Canvas) AddChild(o DisposableObject) {
 	c.container.AddChild(o)
+	c.dirty = true
 }
 
 func (c *Canvas) DrawWithOptions(dst *ebiten.Image, opts DrawOptions) {
@@ -66,14 +262,87 @@ func (c *Canvas) DrawWithOptions(dst *ebiten.Image, opts DrawOptions) {
 		return
 	}
 
-	c.spr.GetImage().Clear()
-	c.container.Draw(c.spr.GetImage())
+	c.render()
+	if c.spr.GetImage() == nil {
+		return
+	}
 
 	if !c.offscreen {
-		opts.Offset = opts.Offset.Add(c.Pos.Resolve())
+		var rotation gmath.Rad
 		if c.Rotation != nil {
-			opts.Rotation += *c.Rotation
+			rotation = *c.Rotation
 		}
+		opts = opts.combine(c.Pos.Resolve(), rotation, gmath.Vec{X: 1, Y: 1}, gmath.Vec{}, defaultColorScale)
 		c.spr.DrawWithOptions(dst, opts)
 	}
 }
+
+// render updates the canvas image according to the canvas mode.
+func (c *Canvas) render() {
+	if c.mode != CanvasModeRedrawAlways && !c.dirty {
+		return
+	}
+	if c.autoSize {
+		c.fitContents()
+	}
+	img := c.spr.GetImage()
+	if img == nil {
+		return
+	}
+	c.dirty = false
+
+	if c.mode == CanvasModePersistent {
+		c.container.Draw(img)
+		c.container.removeChildren()
+		return
+	}
+	img.Clear()
+	c.container.Draw(img)
+}
+
+func (c *Canvas) fitContents() {
+	var size gmath.Vec
+	if img := c.spr.GetImage(); img != nil {
+		size = gmath.Vec{X: float64(img.Bounds().Dx()), Y: float64(img.Bounds().Dy())}
+	}
+	needResize := false
+	for _, o := range c.container.objects {
+		b, ok := o.(interface{ BoundsRect() gmath.Rect })
+		if !ok || o.IsDisposed() {
+			continue
+		}
+		rect := b.BoundsRect()
+		if rect.Max.X > size.X {
+			size.X = math.Ceil(rect.Max.X)
+			needResize = true
+		}
+		if rect.Max.Y > size.Y {
+			size.Y = math.Ceil(rect.Max.Y)
+			needResize = true
+		}
+	}
+	if needResize && size.X > 0 && size.Y > 0 {
+		c.resize(int(size.X), int(size.Y))
+	}
+}
+
+func (c *Canvas) resize(width, height int) {
+	prev := c.spr.GetImage()
+	if prev != nil {
+		bounds := prev.Bounds()
+		if bounds.Dx() == width && bounds.Dy() == height {
+			return
+		}
+	}
+
+	img := ebiten.NewImage(width, height)
+	if prev != nil && c.mode == CanvasModePersistent {
+		img.DrawImage(prev, nil)
+	}
+	if prev != nil && c.ownsImage {
+		prev.Deallocate()
+	}
+	c.spr.SetImage(img)
+	c.ownsImage = true
+	c.dirty = true
+}
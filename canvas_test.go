@@ -0,0 +1,151 @@
+package graphics
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestCanvasRedrawModes(t *testing.T) {
+	dst := ebiten.NewImage(32, 32)
+
+	t.Run("always", func(t *testing.T) {
+		var drawLog []string
+		c := NewCanvas()
+		c.SetSize(16, 16)
+		c.AddChild(&testObject{name: "a", drawLog: &drawLog})
+		for i := 0; i < 3; i++ {
+			c.Draw(dst)
+		}
+		if len(drawLog) != 3 {
+			t.Fatalf("have %d redraws, want 3", len(drawLog))
+		}
+	})
+
+	t.Run("dirty", func(t *testing.T) {
+		var drawLog []string
+		c := NewCanvas()
+		c.SetMode(CanvasModeRedrawDirty)
+		c.AddChild(&testObject{name: "a", drawLog: &drawLog})
+
+		// Without an image, there is nothing to render yet.
+		c.Draw(dst)
+		if len(drawLog) != 0 {
+			t.Fatalf("canvas without an image is rendered")
+		}
+
+		steps := []struct {
+			name   string
+			action func()
+			redraw bool
+		}{
+			{"set size", func() { c.SetSize(16, 16) }, true},
+			{"no changes", func() {}, false},
+			{"mark dirty", c.MarkDirty, true},
+			{"same size", func() { c.SetSize(16, 16) }, false},
+			{"resize", func() { c.SetSize(8, 8) }, true},
+			{"add child", func() { c.AddChild(&testObject{name: "b", drawLog: &drawLog}) }, true},
+			{"offscreen", func() { c.SetOffscreen(true) }, false},
+			{"offscreen dirty", c.MarkDirty, true},
+			{"clear", c.Clear, true},
+			{"set dst image", func() { c.SetDstImage(ebiten.NewImage(4, 4)) }, true},
+		}
+		for _, step := range steps {
+			drawLog = drawLog[:0]
+			step.action()
+			c.Draw(dst)
+			c.Draw(dst)
+			redrawn := len(drawLog) != 0
+			if redrawn != step.redraw {
+				t.Fatalf("%s: have redraw=%v, want %v", step.name, redrawn, step.redraw)
+			}
+			if redrawn && len(drawLog) != len(c.container.objects) {
+				t.Fatalf("%s: the canvas is redrawn more than once: %v", step.name, drawLog)
+			}
+		}
+	})
+
+	t.Run("persistent", func(t *testing.T) {
+		var drawLog []string
+		c := NewCanvas()
+		c.SetMode(CanvasModePersistent)
+		c.SetSize(16, 16)
+		a := &testObject{name: "a", drawLog: &drawLog}
+		c.AddChild(a)
+
+		c.Draw(dst)
+		c.Draw(dst)
+		if len(drawLog) != 1 {
+			t.Fatalf("have %d draws, want 1", len(drawLog))
+		}
+		if len(c.container.objects) != 0 {
+			t.Fatal("the drawn object is not removed from the canvas")
+		}
+		if a.IsDisposed() {
+			t.Fatal("the drawn object is disposed")
+		}
+
+		// The object can be added again to draw it once more.
+		c.AddChild(a)
+		c.AddChild(&testObject{name: "b", drawLog: &drawLog})
+		c.Draw(dst)
+		c.Draw(dst)
+		if len(drawLog) != 3 {
+			t.Fatalf("have %d draws, want 3", len(drawLog))
+		}
+
+		// Marking the canvas as dirty doesn't redraw the removed objects.
+		c.MarkDirty()
+		c.Draw(dst)
+		if len(drawLog) != 3 {
+			t.Fatalf("have %d draws after MarkDirty, want 3", len(drawLog))
+		}
+	})
+}
+
+func TestCanvasImageOwnership(t *testing.T) {
+	c := NewCanvas()
+	c.SetSize(16, 16)
+	if !c.ownsImage {
+		t.Fatal("allocated image is not owned")
+	}
+
+	img := ebiten.NewImage(8, 8)
+	c.SetDstImage(img)
+	if c.ownsImage || c.GetImage() != img {
+		t.Fatal("SetDstImage didn't replace the owned image")
+	}
+
+	// Re-assigning the same image is a no-op.
+	c.SetDstImage(img)
+	if c.GetImage() != img {
+		t.Fatal("SetDstImage with the same image replaced it")
+	}
+
+	c.SetSize(4, 4)
+	if !c.ownsImage || c.GetImage() == img {
+		t.Fatal("SetSize didn't allocate a new image")
+	}
+}
+
+func TestCanvasDispose(t *testing.T) {
+	owned := NewCanvas()
+	owned.SetSize(16, 16)
+	owned.Dispose()
+	if !owned.IsDisposed() {
+		t.Fatal("the canvas is not disposed")
+	}
+	if owned.ownsImage || owned.GetImage() != nil {
+		t.Fatal("the owned image is not released")
+	}
+	// Drawing a disposed canvas is a no-op.
+	owned.Draw(ebiten.NewImage(4, 4))
+
+	img := ebiten.NewImage(8, 8)
+	borrowed := NewCanvas()
+	borrowed.SetDstImage(img)
+	borrowed.Dispose()
+	if borrowed.GetImage() != img {
+		t.Fatal("the image assigned via SetDstImage is released")
+	}
+}
@@ -88,6 +88,24 @@ func (c *Circle) BoundsRect() gmath.Rect {
 	}
 }
 
+// ContainsPoint reports whether the circle contains the pos.
+// The pos is in the same coordinates as the circle Pos.
+// An invisible circle contains no points.
+//
+// The outline is treated as a part of the circle,
+// even if the circle has no fill.
+func (c *Circle) ContainsPoint(pos gmath.Vec) bool {
+	if !c.visible {
+		return false
+	}
+	center := c.Pos.Resolve()
+	r := float64(c.radius)
+	if !c.centered {
+		center = center.Add(gmath.Vec{X: r, Y: r})
+	}
+	return center.DistanceSquaredTo(pos) <= r*r
+}
+
 // Dispose marks this circle for deletion.
 // After calling this method, IsDisposed will report true.
 func (c *Circle) Dispose() {
@@ -216,7 +234,7 @@ func (c *Circle) DrawWithOptions(dst *ebiten.Image, opts DrawOptions) {
 
 	r := float64(c.drawRadius)
 	width := 2 * r
-	pos := opts.Offset.Add(c.Pos.Resolve())
+	pos := c.Pos.Resolve()
 	if c.centered {
 		pos = pos.Sub(gmath.Vec{X: r, Y: r})
 	}
@@ -226,7 +244,9 @@ func (c *Circle) DrawWithOptions(dst *ebiten.Image, opts DrawOptions) {
 	if opts.Blend != nil {
 		drawOptions.Blend = *opts.Blend
 	}
+	opts.applyColorScale(&drawOptions.ColorScale)
 	drawOptions.GeoM.Translate(pos.X, pos.Y)
+	opts.applyTransform(&drawOptions.GeoM)
 	if c.dashLength == 0 {
 		dst.DrawRectShader(int(width), int(width), cache.Global.CircleOutlineShader, &drawOptions)
 	} else {
@@ -1,21 +1,74 @@
 package graphics
 
 import (
+	"image"
+	"math"
+	"slices"
+
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/quasilyte/ebitengine-graphics/internal/cache"
 	"github.com/quasilyte/gmath"
 )
 
+// Container is a group of objects that are drawn together.
+//
+// The container transformation (Pos, Rotation and scale)
+// and its color scale are inherited by all of its objects.
+// The objects positions are relative to the container.
+//
+// Containers can be nested to build a scene graph:
+// a complex entity like a turret can be a container with
+// a base sprite, a barrel container (which has its own muzzle
+// flash sprite) and a health bar.
+//
+// The children are drawn in their z-index order, a lower
+// z-index objects are drawn first. The objects with equal
+// z-index are drawn in their insertion order.
+// The default z-index is 0, so a negative z-index can be used to draw
+// a child behind the parent's main graphics (like a turret base sprite).
 type Container struct {
 	Pos gmath.Pos
 
 	Rotation *gmath.Rad
 
+	// Pivot is a point (relative to the container Pos) around
+	// which the objects are rotated and scaled.
+	// A zero pivot means "rotate and scale around the Pos".
+	Pivot gmath.Vec
+
+	// objects are kept sorted by their z-index.
 	objects []DisposableObject
+	// zIndices is allocated lazily when a non-zero z-index is assigned.
+	// When it's not nil, it has the same length as objects.
+	zIndices []int
+
+	parent *Container
+
+	scale      gmath.Vec
+	colorScale ColorScale
+
+	// mask is allocated lazily when clipping or masking is enabled.
+	mask *containerMaskData
 
 	visible  bool
 	disposed bool
 }
 
+type containerMaskData struct {
+	// A zero clip rect means "no clipping".
+	clipRect gmath.Rect
+
+	mask     Object
+	inverted bool
+
+	buf     pooledBuf
+	maskBuf pooledBuf
+}
+
+func (m *containerMaskData) isEnabled() bool {
+	return m.mask != nil || !m.clipRect.IsZero()
+}
+
 type DisposableObject interface {
 	Object
 
@@ -24,11 +77,42 @@ type DisposableObject interface {
 
 func NewContainer() *Container {
 	return &Container{
-		objects: make([]DisposableObject, 0, 4),
-		visible: true,
+		objects:    make([]DisposableObject, 0, 4),
+		visible:    true,
+		scale:      gmath.Vec{X: 1, Y: 1},
+		colorScale: defaultColorScale,
 	}
 }
 
+// GetScale returns the current container scaling factor.
+// Use SetScale to change it.
+func (c *Container) GetScale() gmath.Vec { return c.scale }
+
+// SetScale changes the container scaling factor.
+// Use GetScale to retrieve the current value.
+//
+// The objects are scaled around the container Pivot.
+func (c *Container) SetScale(scale gmath.Vec) { c.scale = scale }
+
+// GetColorScale is used to retrieve the current color scale value of the container.
+// Use SetColorScale to change it.
+func (c *Container) GetColorScale() ColorScale { return c.colorScale }
+
+// SetColorScale assigns a new ColorScale to this container.
+// Use GetColorScale to retrieve the current color scale.
+//
+// The container color scale is multiplied with
+// the color scales of its objects.
+func (c *Container) SetColorScale(cs ColorScale) { c.colorScale = cs }
+
+// GetAlpha is a shorthand for GetColorScale().A expression.
+// It's mostly provided for a symmetry with SetAlpha.
+func (c *Container) GetAlpha() float32 { return c.colorScale.A }
+
+// SetAlpha is a convenient way to change the alpha value of the ColorScale.
+// This is how the entire container can be faded out.
+func (c *Container) SetAlpha(a float32) { c.colorScale.A = a }
+
 func (c *Container) IsDisposed() bool {
 	return c.disposed
 }
@@ -55,31 +139,559 @@ func (c *Container) SetVisibility(visible bool) {
 	c.visible = visible
 }
 
+// GetClipRect returns the current clipping rectangle.
+// Use SetClipRect to change it.
+func (c *Container) GetClipRect() gmath.Rect {
+	if c.mask == nil {
+		return gmath.Rect{}
+	}
+	return c.mask.clipRect
+}
+
+// SetClipRect assigns a clipping rectangle to the container.
+// Only the parts of the objects that are inside this rectangle are rendered.
+// A zero rect disables the clipping.
+//
+// The rect is relative to the container Pos and it's transformed
+// along with the container objects (see [Container.Pivot]).
+//
+// A non-rotated clip rect is implemented via SubImage and it's cheap.
+// A rotated clip rect requires an offscreen rendering.
+func (c *Container) SetClipRect(rect gmath.Rect) {
+	if c.mask == nil && rect.IsZero() {
+		return
+	}
+	c.getMaskData().clipRect = rect
+}
+
+// GetMask returns the current container alpha mask.
+// Use SetMask to change it.
+func (c *Container) GetMask() Object {
+	if c.mask == nil {
+		return nil
+	}
+	return c.mask.mask
+}
+
+// SetMask assigns an alpha mask to the container.
+// A nil mask removes the mask.
+//
+// Any object can be used as a mask: it's rendered to an offscreen
+// buffer and then its alpha channel is used to filter the container objects.
+// The mask is positioned just like the container objects,
+// so its Pos is relative to the container.
+//
+// See [Container.SetMaskInverted] to learn how to reveal the
+// objects outside of the mask instead (like in fog-of-war effects).
+//
+// The mask object is not disposed along with the container.
+// It's up to the caller to keep it visible.
+func (c *Container) SetMask(mask Object) {
+	if c.mask == nil && mask == nil {
+		return
+	}
+	c.getMaskData().mask = mask
+}
+
+// IsMaskInverted reports whether the container mask is inverted.
+// Use SetMaskInverted to change this flag value.
+func (c *Container) IsMaskInverted() bool {
+	return c.mask != nil && c.mask.inverted
+}
+
+// SetMaskInverted changes the mask Inverted flag value.
+// Use IsMaskInverted to get the current flag value.
+//
+// A normal mask keeps the objects only where the mask is opaque.
+// An inverted mask keeps the objects only where the mask is transparent.
+func (c *Container) SetMaskInverted(inverted bool) {
+	if c.mask == nil && !inverted {
+		return
+	}
+	c.getMaskData().inverted = inverted
+}
+
+func (c *Container) getMaskData() *containerMaskData {
+	if c.mask == nil {
+		c.mask = &containerMaskData{}
+	}
+	return c.mask
+}
+
 func (c *Container) Draw(dst *ebiten.Image) {
 	c.DrawWithOptions(dst, DrawOptions{})
 }
 
+// GetParent returns the container this container was added to.
+// A root container has no parent, nil is returned.
+func (c *Container) GetParent() *Container {
+	return c.parent
+}
+
+// GetChildren returns the container children in their draw order.
+// The returned slice should not be modified.
+//
+// The result may include the objects that were disposed
+// after the last Draw call.
+func (c *Container) GetChildren() []DisposableObject {
+	return c.objects
+}
+
+// AddChild is a shorthand for AddChildWithZIndex(o, 0).
 func (c *Container) AddChild(o DisposableObject) {
-	c.objects = append(c.objects, o)
+	c.AddChildWithZIndex(o, 0)
 }
 
-func (c *Container) DrawWithOptions(dst *ebiten.Image, opts DrawOptions) {
-	if !c.visible {
+// AddChildWithZIndex adds a child object with the specified z-index.
+// The child is drawn after all other children that have the same z-index.
+//
+// If o is a container, it becomes a child node of this container.
+// A container can have only one parent, it panics if
+// o is already added to another container (see [Container.ReparentChild]).
+func (c *Container) AddChildWithZIndex(o DisposableObject, z int) {
+	c.bindChild(o)
+	c.insertAt(c.insertionIndex(z), o, z)
+}
+
+// InsertChildBefore adds a child object right before the sibling.
+// The new child gets the same z-index as the sibling.
+//
+// It panics if sibling is not a child of this container.
+func (c *Container) InsertChildBefore(o, sibling DisposableObject) {
+	i := c.mustFindChild(sibling)
+	c.bindChild(o)
+	c.insertAt(i, o, c.getZIndexAt(i))
+}
+
+// InsertChildAfter adds a child object right after the sibling.
+// The new child gets the same z-index as the sibling.
+//
+// It panics if sibling is not a child of this container.
+func (c *Container) InsertChildAfter(o, sibling DisposableObject) {
+	i := c.mustFindChild(sibling)
+	c.bindChild(o)
+	c.insertAt(i+1, o, c.getZIndexAt(i))
+}
+
+// RemoveChild removes the object from this container without disposing it.
+// It does nothing if o is not a child of this container.
+//
+// Use Dispose on the object itself if you want to remove and destroy it.
+func (c *Container) RemoveChild(o DisposableObject) {
+	i := c.findChild(o)
+	if i < 0 {
 		return
 	}
+	c.removeAt(i)
+	if child, ok := o.(*Container); ok {
+		child.parent = nil
+	}
+}
+
+// ReparentChild moves the child object to the newParent container.
+// The child keeps its z-index.
+//
+// The child position is not adjusted: it's now relative to the newParent.
+// Use [Container.ToWorldPos] and [Container.ToLocalPos] to
+// convert the positions between the containers if needed.
+//
+// It panics if o is not a child of this container.
+func (c *Container) ReparentChild(o DisposableObject, newParent *Container) {
+	i := c.mustFindChild(o)
+	z := c.getZIndexAt(i)
+	// Check the newParent before detaching the child,
+	// so a failed reparenting leaves the tree intact.
+	if child, ok := o.(*Container); ok {
+		newParent.checkSubtree(child)
+	}
+	c.RemoveChild(o)
+	newParent.AddChildWithZIndex(o, z)
+}
+
+// GetChildZIndex returns the z-index of the child object.
+//
+// It panics if o is not a child of this container.
+func (c *Container) GetChildZIndex(o DisposableObject) int {
+	return c.getZIndexAt(c.mustFindChild(o))
+}
+
+// SetChildZIndex changes the z-index of the child object.
+// The child is moved after all other children that have the same z-index.
+//
+// It panics if o is not a child of this container.
+func (c *Container) SetChildZIndex(o DisposableObject, z int) {
+	c.removeAt(c.mustFindChild(o))
+	c.insertAt(c.insertionIndex(z), o, z)
+}
+
+// Walk visits all container descendants in their draw order (depth-first).
+// The nested containers children are visited right after the container itself.
+//
+// If visit returns false for a container, its children are skipped.
+// The disposed objects are not visited.
+func (c *Container) Walk(visit func(o DisposableObject) bool) {
+	for _, o := range c.objects {
+		if o.IsDisposed() {
+			continue
+		}
+		if !visit(o) {
+			continue
+		}
+		if child, ok := o.(*Container); ok {
+			child.Walk(visit)
+		}
+	}
+}
+
+// GetWorldTransform returns the options this container
+// uses to draw its children, assuming that the root container
+// is drawn with the zero DrawOptions.
+//
+// This transformation maps the container local coordinates
+// (the children positions) to the root container coordinates.
+func (c *Container) GetWorldTransform() DrawOptions {
+	var opts DrawOptions
+	if c.parent != nil {
+		opts = c.parent.GetWorldTransform()
+	}
+	return c.combineOptions(opts)
+}
+
+// ToWorldPos converts the container local position into
+// the root container coordinates.
+// See [Container.GetWorldTransform].
+func (c *Container) ToWorldPos(localPos gmath.Vec) gmath.Vec {
+	opts := c.GetWorldTransform()
+	return opts.transformPos(localPos)
+}
+
+// ToLocalPos converts the root container coordinates into
+// this container local coordinates.
+// This is useful for the picking: a cursor position can be
+// converted to the coordinates space of the container children.
+//
+// See [Container.GetWorldTransform].
+func (c *Container) ToLocalPos(worldPos gmath.Vec) gmath.Vec {
+	opts := c.GetWorldTransform()
+	return opts.inverseTransformPos(worldPos)
+}
 
-	opts.Offset = opts.Offset.Add(c.Pos.Resolve())
+// ContainsPoint reports whether any of the container children contains the pos.
+// The pos is in the same coordinates as the container Pos.
+//
+// The container clip rect is respected, but the mask is not.
+// Only the children that implement [HitTester] can be hit.
+// An invisible container contains no points.
+func (c *Container) ContainsPoint(pos gmath.Vec) bool {
+	localPos, ok := c.toHitTestPos(pos)
+	if !ok {
+		return false
+	}
+	for _, o := range c.objects {
+		if hitObject(o, localPos) {
+			return true
+		}
+	}
+	return false
+}
+
+// HitTest appends the container children that contain the pos to dst.
+// The topmost objects go first.
+// The pos is in the same coordinates as the container Pos.
+//
+// The nested containers are reported as a whole:
+// use their HitTest method to find out which of their children were hit.
+//
+// See [Container.ContainsPoint] for more info.
+func (c *Container) HitTest(dst []Object, pos gmath.Vec) []Object {
+	localPos, ok := c.toHitTestPos(pos)
+	if !ok {
+		return dst
+	}
+	for i := len(c.objects) - 1; i >= 0; i-- {
+		o := c.objects[i]
+		if hitObject(o, localPos) {
+			dst = append(dst, o)
+		}
+	}
+	return dst
+}
+
+// toHitTestPos converts the pos into the container local coordinates.
+// It returns false if the container can't be hit at that pos.
+func (c *Container) toHitTestPos(pos gmath.Vec) (gmath.Vec, bool) {
+	if !c.visible || c.colorScale.A == 0 {
+		return gmath.Vec{}, false
+	}
+	opts := c.combineOptions(DrawOptions{})
+	localPos := opts.inverseTransformPos(pos)
+	if c.mask != nil && !c.mask.clipRect.IsZero() && !c.mask.clipRect.Contains(localPos) {
+		return gmath.Vec{}, false
+	}
+	return localPos, true
+}
+
+func (c *Container) combineOptions(opts DrawOptions) DrawOptions {
+	var rotation gmath.Rad
 	if c.Rotation != nil {
-		opts.Rotation += *c.Rotation
+		rotation = *c.Rotation
 	}
+	return opts.combine(c.Pos.Resolve(), rotation, c.scale, c.Pivot, c.colorScale)
+}
 
-	liveObjects := c.objects[:0]
+// removeChildren removes all children without disposing them.
+func (c *Container) removeChildren() {
 	for _, o := range c.objects {
+		if child, ok := o.(*Container); ok {
+			child.parent = nil
+		}
+	}
+	clear(c.objects)
+	c.objects = c.objects[:0]
+	c.zIndices = nil
+}
+
+func (c *Container) bindChild(o DisposableObject) {
+	child, ok := o.(*Container)
+	if !ok {
+		return
+	}
+	if child.parent != nil {
+		panic("container already has a parent")
+	}
+	c.checkSubtree(child)
+	child.parent = c
+}
+
+// checkSubtree panics if c is the child itself or belongs to its subtree.
+func (c *Container) checkSubtree(child *Container) {
+	for p := c; p != nil; p = p.parent {
+		if p == child {
+			panic("can't add a container to its own subtree")
+		}
+	}
+}
+
+func (c *Container) findChild(o DisposableObject) int {
+	for i, child := range c.objects {
+		if child == o {
+			return i
+		}
+	}
+	return -1
+}
+
+func (c *Container) mustFindChild(o DisposableObject) int {
+	i := c.findChild(o)
+	if i < 0 {
+		panic("object is not a child of this container")
+	}
+	return i
+}
+
+func (c *Container) getZIndexAt(i int) int {
+	if c.zIndices == nil {
+		return 0
+	}
+	return c.zIndices[i]
+}
+
+// insertionIndex returns the index after the last child with z-index <= z.
+func (c *Container) insertionIndex(z int) int {
+	if c.zIndices == nil {
+		if z < 0 {
+			return 0
+		}
+		return len(c.objects)
+	}
+	i, _ := slices.BinarySearchFunc(c.zIndices, z, func(childZ, z int) int {
+		if childZ <= z {
+			return -1
+		}
+		return 1
+	})
+	return i
+}
+
+func (c *Container) insertAt(i int, o DisposableObject, z int) {
+	if c.zIndices == nil && z != 0 {
+		c.zIndices = make([]int, len(c.objects), cap(c.objects))
+	}
+	c.objects = slices.Insert(c.objects, i, o)
+	if c.zIndices != nil {
+		c.zIndices = slices.Insert(c.zIndices, i, z)
+	}
+}
+
+func (c *Container) removeAt(i int) {
+	c.objects = slices.Delete(c.objects, i, i+1)
+	if c.zIndices != nil {
+		c.zIndices = slices.Delete(c.zIndices, i, i+1)
+	}
+}
+
+func (c *Container) DrawWithOptions(dst *ebiten.Image, opts DrawOptions) {
+	if !c.visible || c.colorScale.A == 0 {
+		return
+	}
+
+	opts = c.combineOptions(opts)
+
+	if c.mask != nil && c.mask.isEnabled() {
+		c.drawMasked(dst, opts)
+		return
+	}
+
+	c.drawObjects(dst, opts)
+}
+
+func (c *Container) drawObjects(dst *ebiten.Image, opts DrawOptions) {
+	liveObjects := c.objects[:0]
+	for i, o := range c.objects {
 		if o.IsDisposed() {
+			// A disposed container is removed, so it should not
+			// reference this container anymore.
+			if child, ok := o.(*Container); ok && child.parent == c {
+				child.parent = nil
+			}
 			continue
 		}
+		if c.zIndices != nil {
+			c.zIndices[len(liveObjects)] = c.zIndices[i]
+		}
 		liveObjects = append(liveObjects, o)
 		o.DrawWithOptions(dst, opts)
 	}
+	clear(c.objects[len(liveObjects):])
 	c.objects = liveObjects
+	if c.zIndices != nil {
+		c.zIndices = c.zIndices[:len(liveObjects)]
+	}
+}
+
+// drawMasked renders the objects with clipping and/or masking.
+// The opts are expected to be already combined with the container transformation.
+func (c *Container) drawMasked(dst *ebiten.Image, opts DrawOptions) {
+	m := c.mask
+
+	clip := !m.clipRect.IsZero()
+	rotatedClip := clip && opts.Rotation != 0
+
+	// An axis-aligned clipping is a simple sub-image.
+	// For a rotated clip rect, we'll use its bounding box here
+	// and do the actual clipping while drawing the offscreen buffer.
+	clipped := dst
+	if clip {
+		clipRect := transformedRectBounds(m.clipRect, &opts)
+		r := image.Rectangle{
+			Min: image.Point{X: int(math.Floor(clipRect.Min.X)), Y: int(math.Floor(clipRect.Min.Y))},
+			Max: image.Point{X: int(math.Ceil(clipRect.Max.X)), Y: int(math.Ceil(clipRect.Max.Y))},
+		}
+		r = r.Intersect(dst.Bounds())
+		if r.Empty() {
+			return
+		}
+		clipped = dst.SubImage(r).(*ebiten.Image)
+	}
+
+	if m.mask == nil && !rotatedClip {
+		c.drawObjects(clipped, opts)
+		return
+	}
+
+	// The offscreen buffers have their origin at (0, 0).
+	bounds := clipped.Bounds()
+	bufOpts := opts
+	bufOpts.Offset = opts.Offset.Sub(gmath.VecFromStd(bounds.Min))
+	bufOpts.Blend = nil
+	buf := m.buf.get(bounds.Size())
+	buf.Clear()
+	c.drawObjects(buf, bufOpts)
+
+	if m.mask != nil {
+		maskBuf := m.maskBuf.get(bounds.Size())
+		maskBuf.Clear()
+		// The container color should not affect the mask.
+		maskOpts := bufOpts
+		maskOpts.ColorScale = ColorScale{}
+		m.mask.DrawWithOptions(maskBuf, maskOpts)
+		var drawOptions ebiten.DrawImageOptions
+		drawOptions.Blend = ebiten.BlendDestinationIn
+		if m.inverted {
+			drawOptions.Blend = ebiten.BlendDestinationOut
+		}
+		buf.DrawImage(maskBuf, &drawOptions)
+	}
+
+	if rotatedClip {
+		drawRotatedClip(dst, buf, bounds.Min, m.clipRect, opts)
+		return
+	}
+
+	var drawOptions ebiten.DrawImageOptions
+	if opts.Blend != nil {
+		drawOptions.Blend = *opts.Blend
+	}
+	drawOptions.GeoM.Translate(float64(bounds.Min.X), float64(bounds.Min.Y))
+	dst.DrawImage(buf, &drawOptions)
+}
+
+// drawRotatedClip draws the src buffer onto dst through the transformed rect quad.
+// The rect is in the local coordinates, opts define its transformation.
+// bufOrigin is a dst position of the src buffer top-left corner.
+func drawRotatedClip(dst, src *ebiten.Image, bufOrigin image.Point, rect gmath.Rect, opts DrawOptions) {
+	vertices := cache.Global.ScratchVertices[:0]
+	defer func() {
+		cache.Global.ScratchVertices = vertices[:0]
+	}()
+
+	corners := [4]gmath.Vec{
+		rect.Min,
+		{X: rect.Max.X, Y: rect.Min.Y},
+		{X: rect.Min.X, Y: rect.Max.Y},
+		rect.Max,
+	}
+	for _, p := range corners {
+		p = opts.transformPos(p)
+		vertices = append(vertices, ebiten.Vertex{
+			DstX:   float32(p.X),
+			DstY:   float32(p.Y),
+			SrcX:   float32(p.X) - float32(bufOrigin.X),
+			SrcY:   float32(p.Y) - float32(bufOrigin.Y),
+			ColorR: 1,
+			ColorG: 1,
+			ColorB: 1,
+			ColorA: 1,
+		})
+	}
+
+	var drawOptions ebiten.DrawTrianglesOptions
+	if opts.Blend != nil {
+		drawOptions.Blend = *opts.Blend
+	}
+	drawOptions.ColorScaleMode = ebiten.ColorScaleModePremultipliedAlpha
+	dst.DrawTriangles(vertices, quadIndices, src, &drawOptions)
+}
+
+// transformedRectBounds returns an axis-aligned bounding box
+// of the rect transformed by opts.
+func transformedRectBounds(rect gmath.Rect, opts *DrawOptions) gmath.Rect {
+	corners := [4]gmath.Vec{
+		rect.Min,
+		{X: rect.Max.X, Y: rect.Min.Y},
+		{X: rect.Min.X, Y: rect.Max.Y},
+		rect.Max,
+	}
+	result := gmath.Rect{
+		Min: gmath.Vec{X: math.MaxFloat64, Y: math.MaxFloat64},
+		Max: gmath.Vec{X: -math.MaxFloat64, Y: -math.MaxFloat64},
+	}
+	for _, p := range corners {
+		p = opts.transformPos(p)
+		result.Min.X = min(result.Min.X, p.X)
+		result.Min.Y = min(result.Min.Y, p.Y)
+		result.Max.X = max(result.Max.X, p.X)
+		result.Max.Y = max(result.Max.Y, p.Y)
+	}
+	return result
 }
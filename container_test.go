@@ -0,0 +1,306 @@
+package graphics
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/quasilyte/gmath"
+)
+
+// testObject is a container child that records its draw calls.
+type testObject struct {
+	name     string
+	drawLog  *[]string
+	lastOpts DrawOptions
+	disposed bool
+}
+
+func (o *testObject) Draw(dst *ebiten.Image) { o.DrawWithOptions(dst, DrawOptions{}) }
+
+func (o *testObject) DrawWithOptions(dst *ebiten.Image, opts DrawOptions) {
+	o.lastOpts = opts
+	if o.drawLog != nil {
+		*o.drawLog = append(*o.drawLog, o.name)
+	}
+}
+
+func (o *testObject) IsDisposed() bool { return o.disposed }
+
+func (o *testObject) Dispose() { o.disposed = true }
+
+func newTestObjects(drawLog *[]string, names ...string) map[string]*testObject {
+	objects := make(map[string]*testObject, len(names))
+	for _, name := range names {
+		objects[name] = &testObject{name: name, drawLog: drawLog}
+	}
+	return objects
+}
+
+func childNames(c *Container) []string {
+	var names []string
+	for _, o := range c.GetChildren() {
+		switch o := o.(type) {
+		case *testObject:
+			names = append(names, o.name)
+		case *Container:
+			names = append(names, "container")
+		}
+	}
+	return names
+}
+
+func expectPanic(t *testing.T, name string, f func()) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatalf("%s: expected a panic", name)
+		}
+	}()
+	f()
+}
+
+func TestContainerZIndex(t *testing.T) {
+	var drawLog []string
+	objects := newTestObjects(&drawLog, "base", "shadow", "barrel", "flash", "outline")
+
+	c := NewContainer()
+	c.AddChild(objects["base"])
+	c.AddChildWithZIndex(objects["barrel"], 1)
+	c.AddChildWithZIndex(objects["shadow"], -1)
+	c.AddChildWithZIndex(objects["flash"], 1)
+	c.AddChildWithZIndex(objects["outline"], -1)
+
+	// The negative z-index children are drawn behind the parent's main graphics.
+	want := []string{"shadow", "outline", "base", "barrel", "flash"}
+	c.Draw(nil)
+	if !slices.Equal(drawLog, want) {
+		t.Fatalf("draw order:\nhave: %v\nwant: %v", drawLog, want)
+	}
+	if have := childNames(c); !slices.Equal(have, want) {
+		t.Fatalf("children:\nhave: %v\nwant: %v", have, want)
+	}
+	if z := c.GetChildZIndex(objects["outline"]); z != -1 {
+		t.Fatalf("outline z-index: have %d, want -1", z)
+	}
+
+	c.SetChildZIndex(objects["base"], 1)
+	want = []string{"shadow", "outline", "barrel", "flash", "base"}
+	if have := childNames(c); !slices.Equal(have, want) {
+		t.Fatalf("children after SetChildZIndex:\nhave: %v\nwant: %v", have, want)
+	}
+
+	c.SetChildZIndex(objects["flash"], -2)
+	want = []string{"flash", "shadow", "outline", "barrel", "base"}
+	if have := childNames(c); !slices.Equal(have, want) {
+		t.Fatalf("children after SetChildZIndex:\nhave: %v\nwant: %v", have, want)
+	}
+
+	// Disposed objects are removed without breaking the z-indices.
+	objects["shadow"].Dispose()
+	drawLog = drawLog[:0]
+	c.Draw(nil)
+	want = []string{"flash", "outline", "barrel", "base"}
+	if !slices.Equal(drawLog, want) {
+		t.Fatalf("draw order after Dispose:\nhave: %v\nwant: %v", drawLog, want)
+	}
+	for i, z := range []int{-2, -1, 1, 1} {
+		if have := c.GetChildZIndex(c.GetChildren()[i]); have != z {
+			t.Fatalf("child[%d] z-index: have %d, want %d", i, have, z)
+		}
+	}
+}
+
+func TestContainerInsertChild(t *testing.T) {
+	objects := newTestObjects(nil, "a", "b", "c", "d", "e", "f")
+
+	c := NewContainer()
+	c.AddChild(objects["a"])
+	c.AddChildWithZIndex(objects["b"], 5)
+	c.InsertChildBefore(objects["c"], objects["b"])
+	c.InsertChildAfter(objects["d"], objects["a"])
+	c.InsertChildAfter(objects["e"], objects["b"])
+
+	want := []string{"a", "d", "c", "b", "e"}
+	if have := childNames(c); !slices.Equal(have, want) {
+		t.Fatalf("children:\nhave: %v\nwant: %v", have, want)
+	}
+	// The inserted children get the sibling z-index.
+	wantZ := map[string]int{"a": 0, "d": 0, "c": 5, "b": 5, "e": 5}
+	for name, z := range wantZ {
+		if have := c.GetChildZIndex(objects[name]); have != z {
+			t.Fatalf("%s z-index: have %d, want %d", name, have, z)
+		}
+	}
+
+	// A new child with the same z-index goes after the existing ones.
+	c.AddChild(objects["f"])
+	want = []string{"a", "d", "f", "c", "b", "e"}
+	if have := childNames(c); !slices.Equal(have, want) {
+		t.Fatalf("children:\nhave: %v\nwant: %v", have, want)
+	}
+
+	expectPanic(t, "unknown sibling", func() {
+		c.InsertChildBefore(&testObject{}, &testObject{})
+	})
+}
+
+func TestContainerReparent(t *testing.T) {
+	root := NewContainer()
+	a := NewContainer()
+	b := NewContainer()
+	obj := &testObject{name: "obj"}
+
+	root.AddChild(a)
+	root.AddChild(b)
+	a.AddChildWithZIndex(obj, 3)
+	if a.GetParent() != root || b.GetParent() != root {
+		t.Fatal("invalid parent after AddChild")
+	}
+
+	a.ReparentChild(obj, b)
+	if len(a.GetChildren()) != 0 || len(b.GetChildren()) != 1 {
+		t.Fatal("the object is not moved")
+	}
+	if z := b.GetChildZIndex(obj); z != 3 {
+		t.Fatalf("reparented child z-index: have %d, want 3", z)
+	}
+
+	root.ReparentChild(b, a)
+	if b.GetParent() != a {
+		t.Fatal("reparented container has invalid parent")
+	}
+	if have := childNames(root); !slices.Equal(have, []string{"container"}) {
+		t.Fatalf("root children: %v", have)
+	}
+
+	a.RemoveChild(b)
+	if b.GetParent() != nil {
+		t.Fatal("removed container still has a parent")
+	}
+	// Now it can be added to another container.
+	root.AddChild(b)
+	if b.GetParent() != root {
+		t.Fatal("re-added container has invalid parent")
+	}
+
+	expectPanic(t, "second parent", func() {
+		a.AddChild(b)
+	})
+	expectPanic(t, "unknown child", func() {
+		a.ReparentChild(obj, root)
+	})
+}
+
+func TestContainerCycle(t *testing.T) {
+	a := NewContainer()
+	b := NewContainer()
+	c := NewContainer()
+	a.AddChild(b)
+	b.AddChild(c)
+
+	expectPanic(t, "self", func() {
+		a.AddChild(a)
+	})
+	expectPanic(t, "ancestor", func() {
+		c.AddChild(a)
+	})
+
+	// The panic should not corrupt the tree.
+	if a.GetParent() != nil || len(c.GetChildren()) != 0 {
+		t.Fatal("failed AddChild modified the tree")
+	}
+}
+
+func TestContainerReparentCycle(t *testing.T) {
+	root := NewContainer()
+	a := NewContainer()
+	b := NewContainer()
+	root.AddChildWithZIndex(a, 2)
+	a.AddChild(b)
+
+	expectPanic(t, "reparent into descendant", func() {
+		root.ReparentChild(a, b)
+	})
+	expectPanic(t, "reparent into self", func() {
+		root.ReparentChild(a, a)
+	})
+
+	// The failed reparenting should keep the child attached.
+	if a.GetParent() != root {
+		t.Fatal("the child is detached from the old parent")
+	}
+	if len(root.GetChildren()) != 1 || root.GetChildZIndex(a) != 2 {
+		t.Fatal("the child is removed from the old parent")
+	}
+	if len(b.GetChildren()) != 0 {
+		t.Fatal("the child is added to the new parent")
+	}
+}
+
+func TestContainerDisposedChild(t *testing.T) {
+	root := NewContainer()
+	root.Pos.Offset = gmath.Vec{X: 10}
+	child := NewContainer()
+	root.AddChild(child)
+
+	child.Dispose()
+	root.Draw(nil)
+	if len(root.GetChildren()) != 0 {
+		t.Fatal("disposed child is not removed")
+	}
+	if child.GetParent() != nil {
+		t.Fatal("removed disposed child still references its parent")
+	}
+	if child.GetWorldTransform().Offset != (gmath.Vec{}) {
+		t.Fatal("removed disposed child inherits the parent transform")
+	}
+}
+
+func TestContainerWorldTransform(t *testing.T) {
+	root := NewContainer()
+	root.Pos.Offset = gmath.Vec{X: 100, Y: 50}
+	root.SetScale(gmath.Vec{X: 2, Y: 2})
+	root.SetColorScale(ColorScale{R: 1, G: 0.5, B: 1, A: 1})
+
+	child := NewContainer()
+	child.Pos.Offset = gmath.Vec{X: 10}
+	rotation := gmath.Rad(math.Pi / 2)
+	child.Rotation = &rotation
+	child.SetAlpha(0.5)
+	root.AddChild(child)
+
+	obj := &testObject{}
+	child.AddChild(obj)
+
+	tests := []struct {
+		local gmath.Vec
+		world gmath.Vec
+	}{
+		{gmath.Vec{}, gmath.Vec{X: 120, Y: 50}},
+		{gmath.Vec{X: 1}, gmath.Vec{X: 120, Y: 52}},
+		{gmath.Vec{X: 3, Y: 4}, gmath.Vec{X: 112, Y: 56}},
+	}
+	for _, test := range tests {
+		world := child.ToWorldPos(test.local)
+		if !vecApproxEqual(world, test.world) {
+			t.Fatalf("ToWorldPos(%v):\nhave: %v\nwant: %v", test.local, world, test.world)
+		}
+		local := child.ToLocalPos(world)
+		if !vecApproxEqual(local, test.local) {
+			t.Fatalf("ToLocalPos(%v):\nhave: %v\nwant: %v", world, local, test.local)
+		}
+	}
+
+	// The world transform should match the actual draw options.
+	root.Draw(nil)
+	want := child.GetWorldTransform()
+	if obj.lastOpts != want {
+		t.Fatalf("draw options:\nhave: %+v\nwant: %+v", obj.lastOpts, want)
+	}
+	wantColor := ColorScale{R: 1, G: 0.5, B: 1, A: 0.5}
+	if want.ColorScale != wantColor {
+		t.Fatalf("color scale:\nhave: %v\nwant: %v", want.ColorScale, wantColor)
+	}
+}
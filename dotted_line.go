@@ -1,6 +1,8 @@
 package graphics
 
 import (
+	"math"
+
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/quasilyte/ebitengine-graphics/internal/cache"
 	"github.com/quasilyte/gmath"
@@ -18,6 +20,12 @@ type DottedLine struct {
 
 	colorScale ColorScale
 
+	dotRadius  float32
+	dotSpacing float32
+	// drawScale is a parent scaling factor used during the last draw call.
+	// The shader dot radius and spacing are pre-multiplied by it.
+	drawScale float32
+
 	visible  bool
 	disposed bool
 }
@@ -37,12 +45,15 @@ func NewDottedLine(begin, end gmath.Pos) *DottedLine {
 		BeginPos:   begin,
 		EndPos:     end,
 		colorScale: defaultColorScale,
+		dotRadius:  1,
+		dotSpacing: 3,
+		drawScale:  1,
 		visible:    true,
 	}
 
 	l.shaderData = map[string]any{
-		"DotRadius":  float32(1),
-		"DotSpacing": float32(3),
+		"DotRadius":  l.dotRadius,
+		"DotSpacing": l.dotSpacing,
 		"Color":      l.colorScale.AsVec4(),
 		"PointA":     l.beginVec.AsSlice(),
 		"PointB":     l.endVec.AsSlice(),
@@ -90,19 +101,21 @@ func (l *DottedLine) IsVisible() bool { return l.visible }
 func (l *DottedLine) SetVisibility(visible bool) { l.visible = visible }
 
 func (l *DottedLine) GetDotRadius() float64 {
-	return float64(l.shaderData["DotRadius"].(float32))
+	return float64(l.dotRadius)
 }
 
 func (l *DottedLine) SetDotRadius(r float64) {
-	l.shaderData["DotRadius"] = float32(r)
+	l.dotRadius = float32(r)
+	l.shaderData["DotRadius"] = l.dotRadius * l.drawScale
 }
 
 func (l *DottedLine) GetDotSpacing() float64 {
-	return float64(l.shaderData["DotSpacing"].(float32))
+	return float64(l.dotSpacing)
 }
 
 func (l *DottedLine) SetDotSpacing(spacing float64) {
-	l.shaderData["DotSpacing"] = float32(spacing)
+	l.dotSpacing = float32(spacing)
+	l.shaderData["DotSpacing"] = l.dotSpacing * l.drawScale
 }
 
 // GetColorScale is used to retrieve the current color scale value of the line.
@@ -138,19 +151,33 @@ func (l *DottedLine) DrawWithOptions(dst *ebiten.Image, opts DrawOptions) {
 		return
 	}
 
-	bounds := l.BoundsRect()
-	width := bounds.Width()
-	height := bounds.Height()
-	pos := bounds.Min.Add(opts.Offset)
+	// The shader works in dst coordinates,
+	// so the parent transformation is applied to the points directly.
+	begin := opts.transformPos(l.BeginPos.Resolve())
+	end := opts.transformPos(l.EndPos.Resolve())
+	l.beginVec = begin.AsVec32()
+	l.endVec = end.AsVec32()
+
+	// The dots are scaled like the other line primitives widths.
+	// Only do a map write operation if the scale has changed.
+	scale := float32(opts.getUniformScale())
+	if l.drawScale != scale {
+		l.drawScale = scale
+		l.shaderData["DotRadius"] = l.dotRadius * scale
+		l.shaderData["DotSpacing"] = l.dotSpacing * scale
+	}
 
-	l.beginVec = l.BeginPos.Resolve().Add(opts.Offset).AsVec32()
-	l.endVec = l.EndPos.Resolve().Add(opts.Offset).AsVec32()
+	rr := float64(l.dotRadius*scale) + 1
+	pos := gmath.Vec{X: min(begin.X, end.X) - rr, Y: min(begin.Y, end.Y) - rr}
+	width := math.Abs(end.X-begin.X) + 2*rr
+	height := math.Abs(end.Y-begin.Y) + 2*rr
 
 	var drawOptions ebiten.DrawRectShaderOptions
 	drawOptions.Uniforms = l.shaderData
 	if opts.Blend != nil {
 		drawOptions.Blend = *opts.Blend
 	}
+	opts.applyColorScale(&drawOptions.ColorScale)
 	drawOptions.GeoM.Translate(pos.X, pos.Y)
 	dst.DrawRectShader(int(width), int(height), cache.Global.DottedLineShader, &drawOptions)
 }
@@ -0,0 +1,35 @@
+package graphics
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/quasilyte/gmath"
+)
+
+func TestDottedLineScale(t *testing.T) {
+	CompileShaders()
+
+	l := NewDottedLine(gmath.Pos{}, gmath.MakePos(gmath.Vec{X: 20}))
+	l.SetDotRadius(2)
+	l.SetDotSpacing(5)
+	dst := ebiten.NewImage(64, 64)
+
+	check := func(scale float64) {
+		t.Helper()
+		l.DrawWithOptions(dst, DrawOptions{Scale: gmath.Vec{X: scale, Y: scale}})
+		if have := l.shaderData["DotRadius"].(float32); have != float32(2*scale) {
+			t.Fatalf("scale=%v: have dot radius %v", scale, have)
+		}
+		if have := l.shaderData["DotSpacing"].(float32); have != float32(5*scale) {
+			t.Fatalf("scale=%v: have dot spacing %v", scale, have)
+		}
+		if l.GetDotRadius() != 2 || l.GetDotSpacing() != 5 {
+			t.Fatalf("scale=%v: the scaling changed the line settings", scale)
+		}
+	}
+
+	check(2)
+	check(0.5)
+	check(1)
+}
@@ -0,0 +1,210 @@
+package graphics
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/quasilyte/gmath"
+)
+
+func vecApproxEqual(a, b gmath.Vec) bool {
+	return a.DistanceTo(b) < 1e-9
+}
+
+func TestDrawOptionsCombine(t *testing.T) {
+	type node struct {
+		pos      gmath.Vec
+		rotation gmath.Rad
+		scale    gmath.Vec
+		pivot    gmath.Vec
+	}
+
+	tests := []struct {
+		name   string
+		parent DrawOptions
+		nodes  []node
+		local  gmath.Vec // A child position in the last node space
+		want   gmath.Vec // The expected dst position
+	}{
+		{
+			name:  "translation",
+			nodes: []node{{pos: gmath.Vec{X: 10, Y: 20}, scale: gmath.Vec{X: 1, Y: 1}}},
+			local: gmath.Vec{X: 1, Y: 2},
+			want:  gmath.Vec{X: 11, Y: 22},
+		},
+		{
+			name:   "camera offset",
+			parent: DrawOptions{Offset: gmath.Vec{X: -100, Y: -50}},
+			nodes:  []node{{pos: gmath.Vec{X: 10, Y: 20}, scale: gmath.Vec{X: 1, Y: 1}}},
+			local:  gmath.Vec{X: 1, Y: 2},
+			want:   gmath.Vec{X: -89, Y: -28},
+		},
+		{
+			name:  "rotation",
+			nodes: []node{{pos: gmath.Vec{X: 10}, rotation: math.Pi / 2, scale: gmath.Vec{X: 1, Y: 1}}},
+			local: gmath.Vec{X: 1},
+			want:  gmath.Vec{X: 10, Y: 1},
+		},
+		{
+			name: "pivot rotation",
+			nodes: []node{{
+				pos:      gmath.Vec{X: 10},
+				rotation: math.Pi / 2,
+				scale:    gmath.Vec{X: 1, Y: 1},
+				pivot:    gmath.Vec{X: 5},
+			}},
+			local: gmath.Vec{X: 6},
+			want:  gmath.Vec{X: 15, Y: 1},
+		},
+		{
+			name: "pivot stays in place",
+			nodes: []node{{
+				pos:      gmath.Vec{X: 10, Y: 10},
+				rotation: 1.3,
+				scale:    gmath.Vec{X: 2, Y: 3},
+				pivot:    gmath.Vec{X: 5, Y: -4},
+			}},
+			local: gmath.Vec{X: 5, Y: -4},
+			want:  gmath.Vec{X: 15, Y: 6},
+		},
+		{
+			name:   "parent pivot rotation",
+			parent: DrawOptions{Offset: gmath.Vec{X: 100}},
+			nodes: []node{
+				{
+					pos:      gmath.Vec{X: 10},
+					rotation: math.Pi / 2,
+					scale:    gmath.Vec{X: 1, Y: 1},
+					pivot:    gmath.Vec{X: 5},
+				},
+				{pos: gmath.Vec{X: 5}, scale: gmath.Vec{X: 1, Y: 1}},
+			},
+			local: gmath.Vec{X: 1},
+			want:  gmath.Vec{X: 115, Y: 1},
+		},
+		{
+			name:  "nested scale",
+			nodes: []node{{pos: gmath.Vec{X: 10}, scale: gmath.Vec{X: 2, Y: 2}}, {pos: gmath.Vec{X: 5}, scale: gmath.Vec{X: 3, Y: 3}}},
+			local: gmath.Vec{X: 1, Y: 1},
+			want:  gmath.Vec{X: 26, Y: 6},
+		},
+		{
+			name:   "nested scale with parent scale",
+			parent: DrawOptions{Scale: gmath.Vec{X: 0.5, Y: 0.5}},
+			nodes:  []node{{pos: gmath.Vec{X: 10}, scale: gmath.Vec{X: 2, Y: 2}}, {pos: gmath.Vec{X: 5}, scale: gmath.Vec{X: 3, Y: 3}}},
+			local:  gmath.Vec{X: 1, Y: 1},
+			want:   gmath.Vec{X: 13, Y: 3},
+		},
+		{
+			name: "nested rotation and scale",
+			nodes: []node{
+				{pos: gmath.Vec{X: 10}, rotation: math.Pi / 2, scale: gmath.Vec{X: 2, Y: 2}},
+				{pos: gmath.Vec{X: 5}, rotation: math.Pi / 2, scale: gmath.Vec{X: 1, Y: 1}},
+			},
+			local: gmath.Vec{X: 1},
+			want:  gmath.Vec{X: 8, Y: 10},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := test.parent
+			for _, n := range test.nodes {
+				opts = opts.combine(n.pos, n.rotation, n.scale, n.pivot, defaultColorScale)
+			}
+
+			have := opts.transformPos(test.local)
+			if !vecApproxEqual(have, test.want) {
+				t.Fatalf("transformPos(%v):\nhave: %v\nwant: %v", test.local, have, test.want)
+			}
+
+			var geom ebiten.GeoM
+			opts.applyTransform(&geom)
+			x, y := geom.Apply(test.local.X, test.local.Y)
+			if !vecApproxEqual(gmath.Vec{X: x, Y: y}, test.want) {
+				t.Fatalf("applyTransform(%v):\nhave: %v\nwant: %v", test.local, gmath.Vec{X: x, Y: y}, test.want)
+			}
+
+			local := opts.inverseTransformPos(have)
+			if !vecApproxEqual(local, test.local) {
+				t.Fatalf("inverseTransformPos(%v):\nhave: %v\nwant: %v", have, local, test.local)
+			}
+		})
+	}
+}
+
+func TestDrawOptionsInverseTransform(t *testing.T) {
+	optionsList := []DrawOptions{
+		{},
+		{Offset: gmath.Vec{X: 10, Y: -20}},
+		{Rotation: 0.7},
+		{Scale: gmath.Vec{X: 2, Y: 0.5}},
+		{Scale: gmath.Vec{X: -1, Y: 1}},
+		{Offset: gmath.Vec{X: 3, Y: 4}, Rotation: -2.1, Scale: gmath.Vec{X: 1.5, Y: 3}},
+	}
+	points := []gmath.Vec{
+		{},
+		{X: 1},
+		{X: -7, Y: 13},
+		{X: 0.25, Y: -1000},
+	}
+
+	for _, opts := range optionsList {
+		for _, p := range points {
+			have := opts.inverseTransformPos(opts.transformPos(p))
+			if !vecApproxEqual(have, p) {
+				t.Errorf("%+v: inverseTransformPos(transformPos(%v)) = %v", opts, p, have)
+			}
+		}
+	}
+}
+
+func TestDrawOptionsColorScale(t *testing.T) {
+	tests := []struct {
+		name   string
+		parent ColorScale
+		colors []ColorScale
+		want   ColorScale
+	}{
+		{
+			name:   "zero parent",
+			colors: []ColorScale{{R: 0.5, G: 1, B: 1, A: 0.5}},
+			want:   ColorScale{R: 0.5, G: 1, B: 1, A: 0.5},
+		},
+		{
+			name:   "default node color",
+			parent: ColorScale{R: 0.5, G: 0.5, B: 1, A: 1},
+			colors: []ColorScale{defaultColorScale},
+			want:   ColorScale{R: 0.5, G: 0.5, B: 1, A: 1},
+		},
+		{
+			name:   "nested",
+			parent: ColorScale{R: 0.5, G: 1, B: 1, A: 1},
+			colors: []ColorScale{
+				{R: 1, G: 0.5, B: 1, A: 0.5},
+				{R: 1, G: 1, B: 0.5, A: 0.5},
+			},
+			want: ColorScale{R: 0.5, G: 0.5, B: 0.5, A: 0.25},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := DrawOptions{ColorScale: test.parent}
+			for _, cs := range test.colors {
+				opts = opts.combine(gmath.Vec{}, 0, gmath.Vec{X: 1, Y: 1}, gmath.Vec{}, cs)
+			}
+			if opts.ColorScale != test.want {
+				t.Fatalf("color scale:\nhave: %v\nwant: %v", opts.ColorScale, test.want)
+			}
+
+			have := opts.multiplyColorScale(ColorScale{R: 1, G: 1, B: 1, A: 0.5})
+			want := test.want
+			want.A *= 0.5
+			if have != want {
+				t.Fatalf("multiplied color scale:\nhave: %v\nwant: %v", have, want)
+			}
+		})
+	}
+}
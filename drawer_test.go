@@ -3,4 +3,18 @@ package graphics
 var (
 	_ SceneLayerDrawer = (*Layer)(nil)
 	_ SceneLayerDrawer = (*StaticLayer)(nil)
+	_ SceneLayerDrawer = (*ParallaxLayer)(nil)
+	_ SceneLayerDrawer = (*LightingLayer)(nil)
+
+	_ Object = (*Light)(nil)
+
+	_ hitTestLayer = (*Layer)(nil)
+	_ hitTestLayer = (*StaticLayer)(nil)
+	_ hitTestLayer = (*ParallaxLayer)(nil)
+
+	_ HitTester = (*Rect)(nil)
+	_ HitTester = (*Circle)(nil)
+	_ HitTester = (*Sprite)(nil)
+	_ HitTester = (*Label)(nil)
+	_ HitTester = (*Container)(nil)
 )
@@ -1,15 +1,37 @@
 package graphics
 
 import (
+	"math"
+
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/quasilyte/gmath"
 )
 
+// DrawOptions carry the parent transformation and color.
+//
+// A local position p of the object is mapped to the dst position like this:
+//
+//	Offset + rotate(Scale * p, Rotation)
+//
+// In other words, the scaling and rotation are applied around the
+// parent origin (Offset), and then the result is translated by Offset.
+// The object's own rotation and scaling are combined with the parent ones.
+//
+// A zero value DrawOptions is an identity transformation.
 type DrawOptions struct {
 	Offset gmath.Vec
 
 	Rotation gmath.Rad
 
+	// Scale is a parent scaling factor.
+	// A zero value is identical to {1, 1}.
+	Scale gmath.Vec
+
+	// ColorScale is a parent color multiplier.
+	// It's applied on top of the object's own color scale.
+	// A zero value is identical to {1, 1, 1, 1}.
+	ColorScale ColorScale
+
 	// Blend is an optional blend mode override.
 	// You usually want to use a predefined blend from Ebitengine and
 	// assign it like DrawOptopns{Blend: &ebiten.BlendCopy}.
@@ -27,3 +49,106 @@ type Object interface {
 type PostProcessor interface {
 	PostProcess(dst, src *ebiten.Image, o DrawOptions)
 }
+
+// hasTransform reports whether opts have a rotation or scaling.
+// If this method returns false, a simple Offset translation is enough.
+func (opts *DrawOptions) hasTransform() bool {
+	return opts.Rotation != 0 || opts.hasScale()
+}
+
+func (opts *DrawOptions) hasScale() bool {
+	return !opts.Scale.IsZero() && opts.Scale != (gmath.Vec{X: 1, Y: 1})
+}
+
+func (opts *DrawOptions) getScale() gmath.Vec {
+	if opts.Scale.IsZero() {
+		return gmath.Vec{X: 1, Y: 1}
+	}
+	return opts.Scale
+}
+
+// getUniformScale returns a single scaling factor that is used
+// for the properties like line width and circle radius.
+func (opts *DrawOptions) getUniformScale() float64 {
+	if !opts.hasScale() {
+		return 1
+	}
+	return math.Sqrt(math.Abs(opts.Scale.X * opts.Scale.Y))
+}
+
+// transformPos maps a local position to the dst position.
+func (opts *DrawOptions) transformPos(pos gmath.Vec) gmath.Vec {
+	if opts.hasScale() {
+		pos = pos.Mul(opts.Scale)
+	}
+	if opts.Rotation != 0 {
+		pos = pos.Rotated(opts.Rotation)
+	}
+	return pos.Add(opts.Offset)
+}
+
+// inverseTransformPos maps a dst position back to the local position.
+// It's an inverse of transformPos.
+func (opts *DrawOptions) inverseTransformPos(pos gmath.Vec) gmath.Vec {
+	pos = pos.Sub(opts.Offset)
+	if opts.Rotation != 0 {
+		pos = pos.Rotated(-opts.Rotation)
+	}
+	if opts.hasScale() {
+		pos = gmath.Vec{X: pos.X / opts.Scale.X, Y: pos.Y / opts.Scale.Y}
+	}
+	return pos
+}
+
+// applyTransform appends the parent transformation to the geom.
+func (opts *DrawOptions) applyTransform(geom *ebiten.GeoM) {
+	if opts.hasScale() {
+		geom.Scale(opts.Scale.X, opts.Scale.Y)
+	}
+	if opts.Rotation != 0 {
+		geom.Rotate(float64(opts.Rotation))
+	}
+	geom.Translate(opts.Offset.X, opts.Offset.Y)
+}
+
+func (opts *DrawOptions) hasColorScale() bool {
+	return opts.ColorScale != (ColorScale{}) && opts.ColorScale != defaultColorScale
+}
+
+// applyColorScale multiplies the premultiplied-alpha cs by the parent color.
+func (opts *DrawOptions) applyColorScale(cs *ebiten.ColorScale) {
+	if !opts.hasColorScale() {
+		return
+	}
+	c := &opts.ColorScale
+	cs.Scale(c.R*c.A, c.G*c.A, c.B*c.A, c.A)
+}
+
+// multiplyColorScale is like applyColorScale, but for the non-premultiplied colors.
+func (opts *DrawOptions) multiplyColorScale(cs ColorScale) ColorScale {
+	if !opts.hasColorScale() {
+		return cs
+	}
+	return cs.Mul(opts.ColorScale)
+}
+
+// combine returns the options for the child objects of a node
+// located at pos (in parent space) with its own rotation, scaling and color.
+// The node rotates and scales its children around the pivot point (in node space).
+//
+// The non-uniform parent scaling combined with a rotation
+// can't be represented without a skew, so it's approximated.
+func (opts DrawOptions) combine(pos gmath.Vec, rotation gmath.Rad, scale gmath.Vec, pivot gmath.Vec, cs ColorScale) DrawOptions {
+	origin := pos.Add(pivot)
+	if !pivot.IsZero() {
+		origin = origin.Sub(pivot.Mul(scale).Rotated(rotation))
+	}
+	result := opts
+	result.Offset = opts.transformPos(origin)
+	result.Rotation = opts.Rotation + rotation
+	result.Scale = opts.getScale().Mul(scale)
+	if cs != defaultColorScale {
+		result.ColorScale = opts.multiplyColorScale(cs)
+	}
+	return result
+}
@@ -21,6 +21,14 @@ func init() {
 	// if there is a feature request for it.
 	Global.Rand.SetSeed(271828)
 
+	Global.PixelatedShaderUniforms = map[string]any{
+		"Scale": Global.PixelatedShaderScale[:],
+	}
+	Global.PixelatedRepeatShaderUniforms = map[string]any{
+		"Scale":  Global.PixelatedShaderScale[:],
+		"Repeat": float32(1),
+	}
+
 	Global.ScratchVertices = make([]ebiten.Vertex, 0, 40*4)
 	Global.ScratchIndices = make([]uint16, 0, 40*6)
 }
@@ -35,6 +43,24 @@ type cache struct {
 	CircleOutlineShader       *ebiten.Shader
 	DashedCircleOutlineShader *ebiten.Shader
 	DottedLineShader          *ebiten.Shader
+	PixelatedShader           *ebiten.Shader
+
+	BlurShader                *ebiten.Shader
+	BrightPassShader          *ebiten.Shader
+	VignetteShader            *ebiten.Shader
+	ChromaticAberrationShader *ebiten.Shader
+	ScanlinesShader           *ebiten.Shader
+	ColorLUTShader            *ebiten.Shader
+	ToneShader                *ebiten.Shader
+	PixelateShader            *ebiten.Shader
+
+	LightShader     *ebiten.Shader
+	NormalMapShader *ebiten.Shader
+
+	PixelatedShaderScale    [2]float32
+	PixelatedShaderUniforms map[string]any
+
+	PixelatedRepeatShaderUniforms map[string]any
 
 	Rand            gmath.Rand
 	WhitePixel      *ebiten.Image
@@ -0,0 +1,162 @@
+// Package visibility implements a 2D visibility polygon computation.
+//
+// It's a pure CPU geometry code that is used for the light shadows.
+package visibility
+
+import (
+	"math"
+	"sort"
+
+	"github.com/quasilyte/gmath"
+)
+
+// Segment is an occluding line segment.
+type Segment struct {
+	A gmath.Vec
+	B gmath.Vec
+}
+
+// Solver computes the visibility polygons.
+//
+// It keeps the scratch buffers between the calls,
+// so it's advised to re-use the solver.
+// A zero value solver is ready to use.
+type Solver struct {
+	segments []Segment
+	angles   []float64
+}
+
+// angleEpsilon is used to cast the rays slightly past the segment endpoints,
+// so the rays can "slip" behind the corners.
+const angleEpsilon = 0.00001
+
+// Compute returns the visibility polygon of the origin point.
+//
+// The segments block the visibility, while bounds limit it (e.g. a light radius box).
+// The origin is expected to be inside bounds, otherwise the result is empty.
+//
+// The result points are appended to dst (which can be nil).
+// The points are sorted by angle around the origin, the polygon is
+// star-shaped with respect to the origin, so it can be
+// triangulated as a fan: {origin, p[i], p[i+1]}, with the last
+// triangle using {origin, p[n-1], p[0]}.
+func (s *Solver) Compute(dst []gmath.Vec, origin gmath.Vec, bounds gmath.Rect, segments []Segment) []gmath.Vec {
+	if !bounds.Contains(origin) {
+		return dst
+	}
+
+	// Only keep the parts of the segments that are inside the bounds.
+	// This also makes the points where segments cross the bounds
+	// the polygon vertices candidates.
+	s.segments = s.segments[:0]
+	for _, seg := range segments {
+		clipped, ok := clipSegment(seg, bounds)
+		if !ok {
+			continue
+		}
+		s.segments = append(s.segments, clipped)
+	}
+	bottomLeft := gmath.Vec{X: bounds.Min.X, Y: bounds.Max.Y}
+	topRight := gmath.Vec{X: bounds.Max.X, Y: bounds.Min.Y}
+	s.segments = append(s.segments,
+		Segment{A: bounds.Min, B: topRight},
+		Segment{A: topRight, B: bounds.Max},
+		Segment{A: bounds.Max, B: bottomLeft},
+		Segment{A: bottomLeft, B: bounds.Min},
+	)
+
+	s.angles = s.angles[:0]
+	for _, seg := range s.segments {
+		s.addAngles(origin, seg.A)
+		s.addAngles(origin, seg.B)
+	}
+	sort.Float64s(s.angles)
+
+	for _, angle := range s.angles {
+		sin, cos := math.Sincos(angle)
+		dir := gmath.Vec{X: cos, Y: sin}
+		t, ok := s.castRay(origin, dir)
+		if !ok {
+			// Should never happen as the bounds edges
+			// always surround the origin.
+			continue
+		}
+		dst = append(dst, origin.Add(dir.Mulf(t)))
+	}
+
+	return dst
+}
+
+func (s *Solver) addAngles(origin, p gmath.Vec) {
+	angle := math.Atan2(p.Y-origin.Y, p.X-origin.X)
+	s.angles = append(s.angles, angle-angleEpsilon, angle, angle+angleEpsilon)
+}
+
+// castRay returns the distance to the closest segment hit.
+func (s *Solver) castRay(origin, dir gmath.Vec) (float64, bool) {
+	minDist := math.MaxFloat64
+	found := false
+	for _, seg := range s.segments {
+		t, ok := raySegmentIntersection(origin, dir, seg)
+		if ok && t < minDist {
+			minDist = t
+			found = true
+		}
+	}
+	return minDist, found
+}
+
+func raySegmentIntersection(origin, dir gmath.Vec, seg Segment) (float64, bool) {
+	segDir := seg.B.Sub(seg.A)
+	denom := cross(dir, segDir)
+	if math.Abs(denom) < 1e-12 {
+		// Parallel lines.
+		return 0, false
+	}
+	delta := seg.A.Sub(origin)
+	t := cross(delta, segDir) / denom
+	u := cross(delta, dir) / denom
+	if t < 0 || u < 0 || u > 1 {
+		return 0, false
+	}
+	return t, true
+}
+
+// clipSegment implements the Liang-Barsky line clipping.
+func clipSegment(seg Segment, bounds gmath.Rect) (Segment, bool) {
+	d := seg.B.Sub(seg.A)
+	t0 := 0.0
+	t1 := 1.0
+	edges := [4][2]float64{
+		{-d.X, seg.A.X - bounds.Min.X},
+		{d.X, bounds.Max.X - seg.A.X},
+		{-d.Y, seg.A.Y - bounds.Min.Y},
+		{d.Y, bounds.Max.Y - seg.A.Y},
+	}
+	for _, e := range edges {
+		p, q := e[0], e[1]
+		if p == 0 {
+			if q < 0 {
+				return seg, false
+			}
+			continue
+		}
+		r := q / p
+		if p < 0 {
+			t0 = max(t0, r)
+		} else {
+			t1 = min(t1, r)
+		}
+		if t0 > t1 {
+			return seg, false
+		}
+	}
+	return Segment{
+		A: seg.A.Add(d.Mulf(t0)),
+		B: seg.A.Add(d.Mulf(t1)),
+	}, true
+}
+
+func cross(a, b gmath.Vec) float64 {
+	return a.X*b.Y - a.Y*b.X
+}
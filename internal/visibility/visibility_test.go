@@ -0,0 +1,105 @@
+package visibility
+
+import (
+	"math"
+	"testing"
+
+	"github.com/quasilyte/gmath"
+)
+
+func polygonArea(points []gmath.Vec) float64 {
+	area := 0.0
+	for i := range points {
+		a := points[i]
+		b := points[(i+1)%len(points)]
+		area += a.X*b.Y - b.X*a.Y
+	}
+	return math.Abs(area) / 2
+}
+
+func TestCompute(t *testing.T) {
+	bounds := gmath.Rect{Max: gmath.Vec{X: 100, Y: 100}}
+	origin := gmath.Vec{X: 50, Y: 50}
+
+	tests := []struct {
+		name     string
+		segments []Segment
+		want     float64
+	}{
+		{
+			name: "empty",
+			want: 10000,
+		},
+		{
+			name: "outside",
+			segments: []Segment{
+				{A: gmath.Vec{X: 200, Y: 0}, B: gmath.Vec{X: 200, Y: 100}},
+				{A: gmath.Vec{X: -10, Y: -10}, B: gmath.Vec{X: 110, Y: -10}},
+			},
+			want: 10000,
+		},
+		{
+			// The shadow is a trapezoid behind the wall:
+			// its sides go through the wall endpoints at 45 degrees.
+			name: "wall",
+			segments: []Segment{
+				{A: gmath.Vec{X: 75, Y: 25}, B: gmath.Vec{X: 75, Y: 75}},
+			},
+			want: 10000 - 25*(50+100)/2,
+		},
+		{
+			// The same wall, but it's crossing the bounds.
+			// Everything to the right of the wall is in the shadow.
+			name: "long wall",
+			segments: []Segment{
+				{A: gmath.Vec{X: 75, Y: -50}, B: gmath.Vec{X: 75, Y: 150}},
+			},
+			want: 7500,
+		},
+		{
+			// A wall hidden behind another wall doesn't change anything.
+			name: "hidden wall",
+			segments: []Segment{
+				{A: gmath.Vec{X: 75, Y: -50}, B: gmath.Vec{X: 75, Y: 150}},
+				{A: gmath.Vec{X: 80, Y: 40}, B: gmath.Vec{X: 80, Y: 60}},
+			},
+			want: 7500,
+		},
+		{
+			// A closed box around the origin.
+			name: "box",
+			segments: []Segment{
+				{A: gmath.Vec{X: 40, Y: 40}, B: gmath.Vec{X: 60, Y: 40}},
+				{A: gmath.Vec{X: 60, Y: 40}, B: gmath.Vec{X: 60, Y: 60}},
+				{A: gmath.Vec{X: 60, Y: 60}, B: gmath.Vec{X: 40, Y: 60}},
+				{A: gmath.Vec{X: 40, Y: 60}, B: gmath.Vec{X: 40, Y: 40}},
+			},
+			want: 400,
+		},
+	}
+
+	var s Solver
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			points := s.Compute(nil, origin, bounds, test.segments)
+			have := polygonArea(points)
+			if math.Abs(have-test.want) > 0.1 {
+				t.Fatalf("area mismatch:\nhave: %f\nwant: %f", have, test.want)
+			}
+			for _, p := range points {
+				if p.X < -0.001 || p.X > 100.001 || p.Y < -0.001 || p.Y > 100.001 {
+					t.Fatalf("point %v is outside of the bounds", p)
+				}
+			}
+		})
+	}
+}
+
+func TestComputeOriginOutside(t *testing.T) {
+	var s Solver
+	bounds := gmath.Rect{Max: gmath.Vec{X: 100, Y: 100}}
+	points := s.Compute(nil, gmath.Vec{X: 150, Y: 50}, bounds, nil)
+	if len(points) != 0 {
+		t.Fatalf("expected an empty result, got %d points", len(points))
+	}
+}
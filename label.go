@@ -248,6 +248,13 @@ func (l *Label) BoundsRect() gmath.Rect {
 	return rect
 }
 
+// ContainsPoint reports whether the label bounds rect contains the pos.
+// The pos is in the same coordinates as the label Pos.
+// An invisible label contains no points.
+func (l *Label) ContainsPoint(pos gmath.Vec) bool {
+	return l.IsVisible() && l.BoundsRect().Contains(pos)
+}
+
 func (l *Label) Draw(dst *ebiten.Image) {
 	l.DrawWithOptions(dst, DrawOptions{})
 }
@@ -258,7 +265,6 @@ func (l *Label) DrawWithOptions(dst *ebiten.Image, opts DrawOptions) {
 	}
 
 	pos := l.Pos.Resolve()
-	offset := opts.Offset
 
 	numLines := strings.Count(l.text, "\n") + 1
 
@@ -274,26 +280,28 @@ func (l *Label) DrawWithOptions(dst *ebiten.Image, opts DrawOptions) {
 	}
 
 	if l.shadow.enabled {
-		l.drawText(dst, opts.Blend, containerRect, pos, offset.Add(gmath.Vec{Y: 1}), l.shadow.ebitenColorScale)
+		l.drawText(dst, &opts, containerRect, pos, gmath.Vec{Y: 1}, l.shadow.ebitenColorScale)
 	}
-	l.drawText(dst, opts.Blend, containerRect, pos, offset, l.ebitenColorScale)
+	l.drawText(dst, &opts, containerRect, pos, gmath.Vec{}, l.ebitenColorScale)
 }
 
-func (l *Label) drawText(dst *ebiten.Image, blend *ebiten.Blend, rect gmath.Rect, pos, offset gmath.Vec, clr ebiten.ColorScale) {
+func (l *Label) drawText(dst *ebiten.Image, opts *DrawOptions, rect gmath.Rect, pos, offset gmath.Vec, clr ebiten.ColorScale) {
 	fontInfo := cache.Global.FontInfoList[l.fontID]
 	containerRect := rect
 
 	var drawOptions text.DrawOptions
-	if blend != nil {
-		drawOptions.Blend = *blend
+	if opts.Blend != nil {
+		drawOptions.Blend = *opts.Blend
 	}
 	drawOptions.ColorScale = clr
+	opts.applyColorScale(&drawOptions.ColorScale)
 	drawOptions.Filter = ebiten.FilterLinear
 	drawOptions.LineSpacing = fontInfo.LineHeight
 
 	if l.GetAlignHorizontal() == AlignHorizontalLeft {
 		drawOptions.GeoM.Translate(math.Round(pos.X), math.Round(pos.Y))
 		drawOptions.GeoM.Translate(offset.X, offset.Y)
+		opts.applyTransform(&drawOptions.GeoM)
 		text.Draw(dst, l.text, fontInfo.Face, &drawOptions)
 		return
 	}
@@ -321,6 +329,7 @@ func (l *Label) drawText(dst *ebiten.Image, blend *ebiten.Blend, rect gmath.Rect
 		drawOptions.GeoM.Reset()
 		drawOptions.GeoM.Translate(math.Round(pos.X+offsetX), math.Round(pos.Y+offsetY))
 		drawOptions.GeoM.Translate(offset.X, offset.Y)
+		opts.applyTransform(&drawOptions.GeoM)
 		text.Draw(dst, lineText, fontInfo.Face, &drawOptions)
 		if nextLine == -1 {
 			break
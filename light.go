@@ -0,0 +1,313 @@
+package graphics
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/quasilyte/ebitengine-graphics/internal/cache"
+	"github.com/quasilyte/gmath"
+)
+
+// Light is a light source object for the [LightingLayer].
+//
+// A light without a texture is a point light: a radial
+// gradient that fades out from the center to its radius.
+// A light with a texture (a "light cookie") uses that texture
+// as its shape instead; the texture is scaled to fit the light diameter.
+//
+// Lights are always centered around their Pos.
+//
+// While lights are intended to be used with the [LightingLayer],
+// they can be drawn directly too: the default blend mode
+// is additive (ebiten.BlendLighter), so they work as simple glows.
+type Light struct {
+	Pos gmath.Pos
+
+	// Rotation is only used for the textured lights.
+	Rotation *gmath.Rad
+
+	texture *ebiten.Image
+
+	colorScale ColorScale
+
+	radius float32
+	energy float32
+
+	flickerAmount float32
+	flickerSpeed  float32
+	flickerTime   float32
+	flickerValue  float32
+
+	// drawColor is a shader Color uniform storage.
+	drawColor  [4]float32
+	shaderData map[string]any
+
+	noShadows bool
+	visible   bool
+	disposed  bool
+}
+
+// NewLight returns a point light of the specified radius.
+//
+// By default, a light has these properties:
+// * Visible=true
+// * The ColorScale is {1, 1, 1, 1}
+// * Energy is 1
+// * Falloff is 1 (linear)
+// * No flickering
+// * Shadows=true (see [LightingLayer.AddOccluder])
+//
+// You need to call [CompileShaders] before using lights.
+func NewLight(r float64) *Light {
+	requireShaders()
+
+	l := &Light{
+		visible:      true,
+		radius:       float32(r),
+		energy:       1,
+		colorScale:   defaultColorScale,
+		flickerValue: 1,
+	}
+	l.shaderData = map[string]any{
+		"Radius":  float32(math.Ceil(r)),
+		"Falloff": float32(1),
+		"Color":   l.drawColor[:],
+	}
+	return l
+}
+
+// Dispose marks this light for deletion.
+// After calling this method, IsDisposed will report true.
+func (l *Light) Dispose() {
+	l.disposed = true
+}
+
+// IsDisposed reports whether this light is marked for deletion.
+// IsDisposed returns true only after Disposed was called on this light.
+func (l *Light) IsDisposed() bool {
+	return l.disposed
+}
+
+// IsVisible reports whether this light is visible.
+// Use SetVisibility to change this flag value.
+//
+// When light is invisible (visible=false), it will not be rendered at all.
+// This is an efficient way to temporarily turn off the light.
+func (l *Light) IsVisible() bool { return l.visible }
+
+// SetVisibility changes the Visible flag value.
+// It can be used to turn the light on or off.
+// Use IsVisible to get the current flag value.
+func (l *Light) SetVisibility(visible bool) { l.visible = visible }
+
+// HasShadows reports whether this light is blocked by the occluders.
+// Use SetShadows to change this flag value.
+func (l *Light) HasShadows() bool { return !l.noShadows }
+
+// SetShadows changes the Shadows flag value.
+// Use HasShadows to get the current flag value.
+//
+// The shadows are only computed when the light is drawn
+// by the [LightingLayer] that has some occluders.
+// Disabling the shadows for the lights that don't need them
+// saves some CPU time.
+func (l *Light) SetShadows(enabled bool) { l.noShadows = !enabled }
+
+// GetRadius returns the current light radius.
+// Use SetRadius to change it.
+func (l *Light) GetRadius() float64 {
+	return float64(l.radius)
+}
+
+// SetRadius changes the light radius.
+// Use GetRadius to retrieve the current value.
+func (l *Light) SetRadius(r float64) {
+	l.radius = float32(r)
+	l.shaderData["Radius"] = float32(math.Ceil(r))
+}
+
+// GetFalloff returns the current light falloff.
+// Use SetFalloff to change it.
+func (l *Light) GetFalloff() float64 {
+	return float64(l.shaderData["Falloff"].(float32))
+}
+
+// SetFalloff changes the light attenuation curve exponent.
+// Use GetFalloff to retrieve the current value.
+//
+// The light intensity at distance d is (1-d/radius)^falloff.
+// A falloff of 1 is linear, 2 is quadratic and so on.
+// The values below 1 make the light look "harder".
+//
+// The falloff is not used for the textured lights.
+func (l *Light) SetFalloff(falloff float64) {
+	l.shaderData["Falloff"] = float32(falloff)
+}
+
+// GetEnergy returns the current light energy.
+// Use SetEnergy to change it.
+func (l *Light) GetEnergy() float64 {
+	return float64(l.energy)
+}
+
+// SetEnergy changes the light intensity multiplier.
+// Use GetEnergy to retrieve the current value.
+func (l *Light) SetEnergy(energy float64) {
+	l.energy = float32(energy)
+}
+
+// GetColorScale is used to retrieve the current light color scale.
+// Use SetColorScale to change it.
+func (l *Light) GetColorScale() ColorScale {
+	return l.colorScale
+}
+
+// SetColorScale assigns a new light ColorScale.
+// Use GetColorScale to retrieve the current color scale.
+func (l *Light) SetColorScale(cs ColorScale) {
+	l.colorScale = cs
+}
+
+// GetTexture returns the current light cookie texture.
+// Use SetTexture to change it.
+func (l *Light) GetTexture() *ebiten.Image {
+	return l.texture
+}
+
+// SetTexture assigns a light cookie texture.
+// A nil texture turns this light back into the point light.
+func (l *Light) SetTexture(img *ebiten.Image) {
+	l.texture = img
+}
+
+// GetFlicker returns the current flickering settings.
+// Use SetFlicker to change them.
+func (l *Light) GetFlicker() (amount, speed float64) {
+	return float64(l.flickerAmount), float64(l.flickerSpeed)
+}
+
+// SetFlicker configures the light flickering.
+//
+// The amount is a max energy reduction fraction: 0.3 means that the
+// light energy can go down to 70% of its value. Zero amount disables the flickering.
+// The speed controls how fast the energy changes.
+//
+// Flickering requires the light to be updated,
+// see [Light.UpdateWithDelta].
+func (l *Light) SetFlicker(amount, speed float64) {
+	if l.flickerAmount == 0 && amount != 0 {
+		// Randomize the phase, so several lights don't flicker in unison.
+		l.flickerTime = float32(cache.Global.Rand.FloatRange(0, 100))
+	}
+	l.flickerAmount = float32(amount)
+	l.flickerSpeed = float32(speed)
+	l.updateFlicker()
+}
+
+// Update is a shorthand for UpdateWithDelta(1.0/60.0).
+func (l *Light) Update() {
+	l.UpdateWithDelta(1.0 / 60.0)
+}
+
+// UpdateWithDelta advances the light animations like flickering.
+//
+// The [LightingLayer] calls it automatically for its lights.
+func (l *Light) UpdateWithDelta(delta float64) {
+	if l.flickerAmount == 0 {
+		return
+	}
+	l.flickerTime += float32(delta) * l.flickerSpeed
+	l.updateFlicker()
+}
+
+func (l *Light) updateFlicker() {
+	if l.flickerAmount == 0 {
+		l.flickerValue = 1
+		return
+	}
+	// A sum of the incommensurable sines gives a cheap
+	// non-repeating noise in [0, 1] range.
+	t := float64(l.flickerTime)
+	noise := 0.5 + 0.25*math.Sin(t) + 0.15*math.Sin(t*2.7+1.3) + 0.1*math.Sin(t*5.3+2.1)
+	l.flickerValue = 1 - l.flickerAmount*float32(noise)
+}
+
+// BoundsRect returns the properly positioned light containing rectangle.
+func (l *Light) BoundsRect() gmath.Rect {
+	pos := l.Pos.Resolve()
+	offset := gmath.Vec{X: float64(l.radius), Y: float64(l.radius)}
+	return gmath.Rect{
+		Min: pos.Sub(offset),
+		Max: pos.Add(offset),
+	}
+}
+
+// Draw renders the light onto the provided dst image.
+//
+// This method is a shorthand to DrawWithOptions(dst, {})
+// which also implements the gscene.Graphics interface.
+//
+// See DrawWithOptions for more info.
+func (l *Light) Draw(dst *ebiten.Image) {
+	l.DrawWithOptions(dst, DrawOptions{})
+}
+
+// DrawWithOptions renders the light onto the provided dst image.
+//
+// If opts.Blend is nil, ebiten.BlendLighter is used.
+func (l *Light) DrawWithOptions(dst *ebiten.Image, opts DrawOptions) {
+	if !l.visible || l.radius <= 0 {
+		return
+	}
+
+	cs := l.colorScale.premultiplyAlpha().ScaleRGB(l.energy * l.flickerValue)
+	if cs.R == 0 && cs.G == 0 && cs.B == 0 {
+		return
+	}
+
+	blend := ebiten.BlendLighter
+	if opts.Blend != nil {
+		blend = *opts.Blend
+	}
+
+	pos := l.Pos.Resolve()
+
+	if l.texture != nil {
+		l.drawTextured(dst, pos, cs, blend, &opts)
+		return
+	}
+
+	r := float64(l.shaderData["Radius"].(float32))
+	l.drawColor = [4]float32{cs.R, cs.G, cs.B, cs.A}
+	var drawOptions ebiten.DrawRectShaderOptions
+	drawOptions.Uniforms = l.shaderData
+	drawOptions.Blend = blend
+	opts.applyColorScale(&drawOptions.ColorScale)
+	drawOptions.GeoM.Translate(pos.X-r, pos.Y-r)
+	opts.applyTransform(&drawOptions.GeoM)
+	dst.DrawRectShader(int(2*r), int(2*r), cache.Global.LightShader, &drawOptions)
+}
+
+func (l *Light) drawTextured(dst *ebiten.Image, pos gmath.Vec, cs ColorScale, blend ebiten.Blend, opts *DrawOptions) {
+	size := l.texture.Bounds().Size()
+	if size.X == 0 || size.Y == 0 {
+		return
+	}
+	w := float64(size.X)
+	h := float64(size.Y)
+
+	var drawOptions ebiten.DrawImageOptions
+	drawOptions.Blend = blend
+	drawOptions.Filter = ebiten.FilterLinear
+	drawOptions.ColorScale.Scale(cs.R, cs.G, cs.B, cs.A)
+	opts.applyColorScale(&drawOptions.ColorScale)
+	drawOptions.GeoM.Translate(-w*0.5, -h*0.5)
+	d := 2 * float64(l.radius)
+	drawOptions.GeoM.Scale(d/w, d/h)
+	if l.Rotation != nil {
+		drawOptions.GeoM.Rotate(float64(*l.Rotation))
+	}
+	drawOptions.GeoM.Translate(pos.X, pos.Y)
+	opts.applyTransform(&drawOptions.GeoM)
+	dst.DrawImage(l.texture, &drawOptions)
+}
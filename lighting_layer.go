@@ -0,0 +1,239 @@
+package graphics
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/quasilyte/ebitengine-graphics/internal/cache"
+	"github.com/quasilyte/ebitengine-graphics/internal/visibility"
+	"github.com/quasilyte/gmath"
+)
+
+// blendMultiply multiplies the destination color by the source color.
+// The destination alpha is preserved.
+var blendMultiply = ebiten.Blend{
+	BlendFactorSourceRGB:        ebiten.BlendFactorZero,
+	BlendFactorSourceAlpha:      ebiten.BlendFactorZero,
+	BlendFactorDestinationRGB:   ebiten.BlendFactorSourceColor,
+	BlendFactorDestinationAlpha: ebiten.BlendFactorOne,
+	BlendOperationRGB:           ebiten.BlendOperationAdd,
+	BlendOperationAlpha:         ebiten.BlendOperationAdd,
+}
+
+// LightingLayer is a scene layer that implements a dynamic 2D lighting.
+//
+// The layer renders a light map into an offscreen buffer:
+// the buffer is filled with the ambient color and then all
+// layer objects (usually [Light] objects) are drawn on top of it additively.
+// The resulting light map is multiplied over the layers rendered before it.
+//
+// This means that the lighting layer should be placed after the
+// world layers, but before the layers that should not be affected
+// by the lighting (like HUD).
+//
+// The light objects are positioned in the world coordinates,
+// they follow the camera offset like any other [Layer] objects.
+//
+// Any [Object] can be added to this layer, it will be drawn using
+// the additive blending (that's how sprites can be used as light shapes).
+//
+// The [Occluder] shapes added via [LightingLayer.AddOccluder] block the light.
+// For every light, a visibility polygon is computed on the CPU;
+// this polygon is then used as a mask when drawing the light.
+type LightingLayer struct {
+	objects    []Object
+	occluders  []*Occluder
+	needFilter bool
+
+	ambient ColorScale
+
+	buf pooledBuf
+
+	// These are used for the shadows rendering.
+	shadowBuf pooledBuf
+	solver    visibility.Solver
+	segments  []visibility.Segment
+	polygon   []gmath.Vec
+}
+
+// NewLightingLayer creates a new lighting layer.
+//
+// By default, the ambient color is black, so only the
+// lit areas will be visible.
+func NewLightingLayer() *LightingLayer {
+	return &LightingLayer{
+		objects: make([]Object, 0, 16),
+		ambient: ColorScale{0, 0, 0, 1},
+	}
+}
+
+// GetAmbientColor returns the current ambient color.
+// Use SetAmbientColor to change it.
+func (l *LightingLayer) GetAmbientColor() ColorScale {
+	return l.ambient
+}
+
+// SetAmbientColor changes the ambient light color.
+// Use GetAmbientColor to retrieve the current value.
+//
+// The ambient color is the light level of the areas not lit by any light.
+// {1, 1, 1, 1} means "no darkness", {0, 0, 0, 1} means "pitch black".
+// The alpha component is ignored.
+func (l *LightingLayer) SetAmbientColor(cs ColorScale) {
+	l.ambient = cs
+}
+
+// AddOccluder adds a light-blocking shape to this layer.
+// A disposed occluder is removed from the layer automatically.
+//
+// Only [Light] objects are affected by the occluders,
+// see [Light.SetShadows].
+func (l *LightingLayer) AddOccluder(o *Occluder) {
+	l.occluders = append(l.occluders, o)
+	l.needFilter = true
+}
+
+func (l *LightingLayer) AddChild(g gsceneGraphics) {
+	l.objects = append(l.objects, g.(Object))
+	l.needFilter = true
+}
+
+func (l *LightingLayer) Update(delta float64) {
+	l.needFilter = true
+	for _, o := range l.objects {
+		if light, ok := o.(*Light); ok {
+			light.UpdateWithDelta(delta)
+		}
+	}
+}
+
+func (l *LightingLayer) filter() {
+	liveObjects := l.objects[:0]
+	for _, o := range l.objects {
+		if o.IsDisposed() {
+			continue
+		}
+		liveObjects = append(liveObjects, o)
+	}
+	l.objects = liveObjects
+
+	liveOccluders := l.occluders[:0]
+	for _, o := range l.occluders {
+		if o.IsDisposed() {
+			continue
+		}
+		liveOccluders = append(liveOccluders, o)
+	}
+	l.occluders = liveOccluders
+}
+
+func (l *LightingLayer) DrawWithOptions(dst *ebiten.Image, opts DrawOptions) {
+	if l.needFilter {
+		l.filter()
+	}
+	l.needFilter = false
+
+	bounds := dst.Bounds()
+	lightMap := l.buf.get(bounds.Size())
+	lightMap.Fill(color.NRGBA{
+		R: uint8(gmath.Clamp(l.ambient.R, 0, 1) * 255),
+		G: uint8(gmath.Clamp(l.ambient.G, 0, 1) * 255),
+		B: uint8(gmath.Clamp(l.ambient.B, 0, 1) * 255),
+		A: 0xff,
+	})
+
+	// The light map has its origin at (0, 0),
+	// while dst can be a sub-image.
+	// The other options (like the parent scaling) are passed as is.
+	lightOptions := opts
+	lightOptions.Offset = opts.Offset.Sub(gmath.VecFromStd(bounds.Min))
+	lightOptions.Blend = &ebiten.BlendLighter
+
+	// Collect the occluder edges once per frame.
+	l.segments = l.segments[:0]
+	for _, o := range l.occluders {
+		if o.disabled {
+			continue
+		}
+		l.segments = o.appendSegments(l.segments)
+	}
+
+	for _, o := range l.objects {
+		if light, ok := o.(*Light); ok && len(l.segments) != 0 && !light.noShadows {
+			l.drawShadowedLight(lightMap, light, lightOptions)
+			continue
+		}
+		o.DrawWithOptions(lightMap, lightOptions)
+	}
+
+	var drawOptions ebiten.DrawImageOptions
+	drawOptions.Blend = blendMultiply
+	drawOptions.GeoM.Translate(float64(bounds.Min.X), float64(bounds.Min.Y))
+	dst.DrawImage(lightMap, &drawOptions)
+}
+
+func (l *LightingLayer) drawShadowedLight(dst *ebiten.Image, light *Light, opts DrawOptions) {
+	if !light.visible || light.radius <= 0 {
+		return
+	}
+
+	pos := light.Pos.Resolve()
+	l.polygon = l.solver.Compute(l.polygon[:0], pos, light.BoundsRect(), l.segments)
+	if len(l.polygon) == 0 || len(l.polygon) >= math.MaxUint16 {
+		return
+	}
+
+	// Render the light into a separate buffer first.
+	size := int(math.Ceil(2*float64(light.radius))) + 2
+	buf := l.shadowBuf.get(image.Point{X: size, Y: size})
+	buf.Clear()
+	// bufOrigin is a world position of the buf top-left corner.
+	bufOrigin := pos.Sub(gmath.Vec{X: float64(size) / 2, Y: float64(size) / 2})
+	light.DrawWithOptions(buf, DrawOptions{
+		Offset: gmath.Vec{X: -bufOrigin.X, Y: -bufOrigin.Y},
+		Blend:  &ebiten.BlendCopy,
+	})
+
+	// Then use the visibility polygon as a mask:
+	// only the visible parts of the light are copied.
+	vertices := cache.Global.ScratchVertices[:0]
+	indices := cache.Global.ScratchIndices[:0]
+	defer func() {
+		cache.Global.ScratchVertices = vertices[:0]
+		cache.Global.ScratchIndices = indices[:0]
+	}()
+	// The light itself is rendered to buf without the parent options,
+	// they're applied while copying the visible polygon.
+	clr := opts.multiplyColorScale(defaultColorScale)
+	clr = clr.premultiplyAlpha()
+	vertices = appendLightMaskVertex(vertices, pos, bufOrigin, &opts, clr)
+	for i, p := range l.polygon {
+		vertices = appendLightMaskVertex(vertices, p, bufOrigin, &opts, clr)
+		next := uint16(i+1)%uint16(len(l.polygon)) + 1
+		indices = append(indices, 0, uint16(i+1), next)
+	}
+
+	var drawOptions ebiten.DrawTrianglesOptions
+	drawOptions.Blend = ebiten.BlendLighter
+	if opts.Blend != nil {
+		drawOptions.Blend = *opts.Blend
+	}
+	drawOptions.ColorScaleMode = ebiten.ColorScaleModePremultipliedAlpha
+	dst.DrawTriangles(vertices, indices, buf, &drawOptions)
+}
+
+func appendLightMaskVertex(dst []ebiten.Vertex, p, bufOrigin gmath.Vec, opts *DrawOptions, clr ColorScale) []ebiten.Vertex {
+	dstPos := opts.transformPos(p)
+	return append(dst, ebiten.Vertex{
+		DstX:   float32(dstPos.X),
+		DstY:   float32(dstPos.Y),
+		SrcX:   float32(p.X - bufOrigin.X),
+		SrcY:   float32(p.Y - bufOrigin.Y),
+		ColorR: clr.R,
+		ColorG: clr.G,
+		ColorB: clr.B,
+		ColorA: clr.A,
+	})
+}
@@ -133,7 +133,9 @@ func (l *Line) DrawWithOptions(dst *ebiten.Image, opts DrawOptions) {
 		return
 	}
 
-	pos1 := l.BeginPos.Resolve().Add(opts.Offset)
-	pos2 := l.EndPos.Resolve().Add(opts.Offset)
-	drawLine(dst, opts.Blend, pos1, pos2, l.width, l.ebitenColorScale)
+	pos1 := opts.transformPos(l.BeginPos.Resolve())
+	pos2 := opts.transformPos(l.EndPos.Resolve())
+	cs := l.ebitenColorScale
+	opts.applyColorScale(&cs)
+	drawLine(dst, opts.Blend, pos1, pos2, l.width*opts.getUniformScale(), cs)
 }
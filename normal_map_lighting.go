@@ -0,0 +1,214 @@
+package graphics
+
+import (
+	"image"
+	"math"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/quasilyte/ebitengine-graphics/internal/cache"
+)
+
+// MaxNormalMapLights is the max number of lights
+// a [NormalMapLighting] can have.
+const MaxNormalMapLights = 8
+
+// NormalMapLighting is a set of lights that affect the normal-mapped sprites.
+// See [Sprite.SetNormalMap].
+//
+// It computes a per-pixel diffuse and specular shading
+// using the bundled shader. The sprite normal map is bound to
+// the Texture1 slot of that shader.
+//
+// A single lighting object is usually shared between all
+// normal-mapped sprites of the scene.
+//
+// The lights are positioned in the world coordinates,
+// so they work with the camera offsets.
+// For the sprites inside a [Container], the lights are
+// positioned in the container local space instead.
+// [Light] radius, color, energy and flickering are respected;
+// the light textures, falloff and shadows are ignored.
+type NormalMapLighting struct {
+	shader *Shader
+
+	lights []*Light
+
+	lightPos    [2 * MaxNormalMapLights]float32
+	lightColor  [3 * MaxNormalMapLights]float32
+	lightRadius [MaxNormalMapLights]float32
+
+	dstToWorld       [4]float32
+	dstToWorldOffset [2]float32
+	normalMatrix     [4]float32
+}
+
+// NewNormalMapLighting creates a lighting without any lights.
+//
+// By default, lighting has these properties:
+// * The AmbientColor is {0.2, 0.2, 0.2, 1}
+// * Specular is 0.5
+// * Shininess is 16
+// * LightHeight is 32
+//
+// You need to call [CompileShaders] before using normal maps.
+func NewNormalMapLighting() *NormalMapLighting {
+	requireShaders()
+
+	l := &NormalMapLighting{
+		shader: NewShader(cache.Global.NormalMapShader),
+	}
+	l.shader.shaderData = map[string]any{
+		"LightPos":         l.lightPos[:],
+		"LightColor":       l.lightColor[:],
+		"LightRadius":      l.lightRadius[:],
+		"LightCount":       int32(0),
+		"DstToWorld":       l.dstToWorld[:],
+		"DstToWorldOffset": l.dstToWorldOffset[:],
+		"NormalMatrix":     l.normalMatrix[:],
+	}
+	l.SetAmbientColor(ColorScale{0.2, 0.2, 0.2, 1})
+	l.SetSpecular(0.5, 16)
+	l.SetLightHeight(32)
+	return l
+}
+
+// AddLight adds a light to this lighting.
+// A disposed light is removed automatically.
+//
+// It panics if there are already MaxNormalMapLights lights.
+func (l *NormalMapLighting) AddLight(light *Light) {
+	l.filter()
+	if len(l.lights) >= MaxNormalMapLights {
+		panic("too many normal map lights")
+	}
+	l.lights = append(l.lights, light)
+}
+
+// RemoveLight removes the light from this lighting.
+// It does nothing if this light was not added.
+func (l *NormalMapLighting) RemoveLight(light *Light) {
+	index := slices.Index(l.lights, light)
+	if index < 0 {
+		return
+	}
+	l.lights = slices.Delete(l.lights, index, index+1)
+}
+
+// GetAmbientColor returns the current ambient color.
+// Use SetAmbientColor to change it.
+func (l *NormalMapLighting) GetAmbientColor() ColorScale {
+	v := l.shader.GetValue("Ambient").([]float32)
+	return ColorScale{R: v[0], G: v[1], B: v[2], A: 1}
+}
+
+// SetAmbientColor changes the light level of the unlit sprite pixels.
+// The alpha component is ignored.
+func (l *NormalMapLighting) SetAmbientColor(cs ColorScale) {
+	l.shader.SetVec3Value("Ambient", []float32{cs.R, cs.G, cs.B})
+}
+
+// GetSpecular returns the current specular highlight settings.
+// Use SetSpecular to change them.
+func (l *NormalMapLighting) GetSpecular() (strength, shininess float64) {
+	strength = float64(l.shader.GetValue("Specular").(float32))
+	shininess = float64(l.shader.GetValue("Shininess").(float32))
+	return strength, shininess
+}
+
+// SetSpecular changes the specular highlight settings.
+// A zero strength disables the specular highlights.
+// The higher shininess values make the highlights smaller and sharper.
+func (l *NormalMapLighting) SetSpecular(strength, shininess float64) {
+	l.shader.SetFloatValue("Specular", float32(strength))
+	l.shader.SetFloatValue("Shininess", float32(shininess))
+}
+
+// GetLightHeight returns the current light height.
+// Use SetLightHeight to change it.
+func (l *NormalMapLighting) GetLightHeight() float64 {
+	return float64(l.shader.GetValue("LightHeight").(float32))
+}
+
+// SetLightHeight changes the distance between the lights and the sprites plane.
+// The lower values make the surface details more pronounced.
+func (l *NormalMapLighting) SetLightHeight(h float64) {
+	l.shader.SetFloatValue("LightHeight", float32(h))
+}
+
+func (l *NormalMapLighting) filter() {
+	l.lights = slices.DeleteFunc(l.lights, func(light *Light) bool {
+		return light.IsDisposed()
+	})
+}
+
+// updateLights copies the current lights state into the shader uniforms.
+func (l *NormalMapLighting) updateLights() {
+	n := 0
+	for _, light := range l.lights {
+		if light.disposed || !light.visible || light.radius <= 0 {
+			continue
+		}
+		pos := light.Pos.Resolve()
+		cs := light.colorScale.ScaleRGB(light.energy * light.flickerValue * light.colorScale.A)
+		l.lightPos[n*2+0] = float32(pos.X)
+		l.lightPos[n*2+1] = float32(pos.Y)
+		l.lightColor[n*3+0] = cs.R
+		l.lightColor[n*3+1] = cs.G
+		l.lightColor[n*3+2] = cs.B
+		l.lightRadius[n] = light.radius
+		n++
+	}
+	l.shader.shaderData["LightCount"] = int32(n)
+}
+
+// updateTransform computes the dst-to-world and the normals transformations.
+// geom maps the sprite texels to the dst pixels,
+// opts map the sprite parent space (the "world") to the dst pixels.
+// It returns false if any of these transformations is degenerate.
+func (l *NormalMapLighting) updateTransform(geom ebiten.GeoM, opts *DrawOptions, dstOrigin image.Point) bool {
+	var boundsToWorld ebiten.GeoM
+	opts.applyTransform(&boundsToWorld)
+	if !boundsToWorld.IsInvertible() {
+		return false
+	}
+	boundsToWorld.Invert()
+
+	// The shader dst positions are relative to the dst image origin,
+	// while the GeoM uses the dst image bounds coordinates.
+	var dstToWorld ebiten.GeoM
+	dstToWorld.Translate(float64(dstOrigin.X), float64(dstOrigin.Y))
+	dstToWorld.Concat(boundsToWorld)
+	l.dstToWorld = [4]float32{
+		float32(dstToWorld.Element(0, 0)), float32(dstToWorld.Element(0, 1)),
+		float32(dstToWorld.Element(1, 0)), float32(dstToWorld.Element(1, 1)),
+	}
+	l.dstToWorldOffset = [2]float32{
+		float32(dstToWorld.Element(0, 2)),
+		float32(dstToWorld.Element(1, 2)),
+	}
+
+	// This is the sprite own linear transformation:
+	// flips, skew, extra GeoM, rotation and scaling.
+	texelToWorld := geom
+	texelToWorld.Concat(boundsToWorld)
+	a := texelToWorld.Element(0, 0)
+	b := texelToWorld.Element(0, 1)
+	c := texelToWorld.Element(1, 0)
+	d := texelToWorld.Element(1, 1)
+	det := a*d - b*c
+	if det == 0 {
+		return false
+	}
+
+	// The normals are transformed by the inverse transpose matrix.
+	// It's normalized to |det|=1, so a uniform scaling doesn't
+	// change the shading, while the flips and skew still do.
+	k := math.Copysign(1/math.Sqrt(math.Abs(det)), det)
+	l.normalMatrix = [4]float32{
+		float32(d * k), float32(-c * k),
+		float32(-b * k), float32(a * k),
+	}
+	return true
+}
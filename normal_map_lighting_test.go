@@ -0,0 +1,160 @@
+package graphics
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/quasilyte/gmath"
+)
+
+func TestNormalMapLightingTransform(t *testing.T) {
+	CompileShaders()
+
+	applyRows := func(m [4]float32, offset [2]float32, p gmath.Vec) gmath.Vec {
+		return gmath.Vec{
+			X: float64(m[0])*p.X + float64(m[1])*p.Y + float64(offset[0]),
+			Y: float64(m[2])*p.X + float64(m[3])*p.Y + float64(offset[1]),
+		}
+	}
+
+	tests := []struct {
+		name       string
+		setup      func(s *Sprite)
+		opts       DrawOptions
+		dstOrigin  image.Point
+		wantNormal gmath.Vec // The transformed {1, 0} texel normal
+	}{
+		{
+			name:       "identity",
+			wantNormal: gmath.Vec{X: 1},
+		},
+		{
+			name:       "camera offset",
+			opts:       DrawOptions{Offset: gmath.Vec{X: -100, Y: 50}},
+			dstOrigin:  image.Point{X: 16, Y: 8},
+			wantNormal: gmath.Vec{X: 1},
+		},
+		{
+			name: "sprite rotation",
+			setup: func(s *Sprite) {
+				rotation := gmath.Rad(math.Pi / 2)
+				s.Rotation = &rotation
+			},
+			wantNormal: gmath.Vec{Y: 1},
+		},
+		{
+			name: "parent rotation and scale",
+			opts: DrawOptions{
+				Offset:   gmath.Vec{X: 10, Y: 20},
+				Rotation: math.Pi / 2,
+				Scale:    gmath.Vec{X: 3, Y: 3},
+			},
+			// The lights are in the parent space too,
+			// so the normals are not affected.
+			wantNormal: gmath.Vec{X: 1},
+		},
+		{
+			name: "flip",
+			setup: func(s *Sprite) {
+				s.SetHorizontalFlip(true)
+				s.SetScaleX(2)
+			},
+			wantNormal: gmath.Vec{X: -1},
+		},
+		{
+			name: "skew",
+			setup: func(s *Sprite) {
+				s.SetSkewX(math.Pi / 4)
+			},
+			// The vertical edges become diagonal,
+			// so the right-facing normal tilts up.
+			wantNormal: gmath.Vec{X: 1, Y: -1}.Normalized(),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := NewSprite()
+			s.SetImage(ebiten.NewImage(16, 16))
+			s.Pos.Offset = gmath.Vec{X: 40, Y: 30}
+			if test.setup != nil {
+				test.setup(s)
+			}
+			var geom ebiten.GeoM
+			s.buildGeoM(&geom)
+			test.opts.applyTransform(&geom)
+
+			l := NewNormalMapLighting()
+			if !l.updateTransform(geom, &test.opts, test.dstOrigin) {
+				t.Fatal("unexpected degenerate transform")
+			}
+
+			// The sprite origin should be mapped back to its world position.
+			originX, originY := geom.Apply(8, 8)
+			dstPos := gmath.Vec{
+				X: originX - float64(test.dstOrigin.X),
+				Y: originY - float64(test.dstOrigin.Y),
+			}
+			worldPos := applyRows(l.dstToWorld, l.dstToWorldOffset, dstPos)
+			if worldPos.DistanceTo(s.Pos.Resolve()) > 1e-3 {
+				t.Fatalf("world pos:\nhave: %v\nwant: %v", worldPos, s.Pos.Resolve())
+			}
+
+			normal := applyRows(l.normalMatrix, [2]float32{}, gmath.Vec{X: 1}).Normalized()
+			if normal.DistanceTo(test.wantNormal) > 1e-3 {
+				t.Fatalf("normal:\nhave: %v\nwant: %v", normal, test.wantNormal)
+			}
+
+			// Make sure the uniforms are accepted by the shader.
+			s.SetNormalMap(ebiten.NewImage(16, 16), l)
+			s.DrawWithOptions(ebiten.NewImage(64, 64), test.opts)
+		})
+	}
+
+	t.Run("degenerate", func(t *testing.T) {
+		l := NewNormalMapLighting()
+		var geom ebiten.GeoM
+		geom.Scale(0, 1)
+		if l.updateTransform(geom, &DrawOptions{}, image.Point{}) {
+			t.Fatal("degenerate transform is accepted")
+		}
+	})
+}
+
+func TestSpriteNormalMapSize(t *testing.T) {
+	l := NewNormalMapLighting()
+
+	s := NewSprite()
+	s.SetImage(ebiten.NewImage(16, 8))
+	expectPanic(t, "SetNormalMap", func() {
+		s.SetNormalMap(ebiten.NewImage(8, 16), l)
+	})
+	if s.GetNormalMap() != nil {
+		t.Fatal("a mismatched normal map is assigned")
+	}
+
+	normalMap := ebiten.NewImage(16, 8)
+	s.SetNormalMap(normalMap, l)
+	expectPanic(t, "SetImage", func() {
+		s.SetImage(ebiten.NewImage(16, 16))
+	})
+	s.SetImage(ebiten.NewImage(16, 8))
+
+	// A sub-image is matched by its size, not by its bounds.
+	atlas := ebiten.NewImage(64, 64)
+	s.SetImage(atlas.SubImage(image.Rect(32, 32, 48, 40)).(*ebiten.Image))
+
+	// The image can be changed freely after the normal map is removed.
+	s.SetNormalMap(nil, nil)
+	s.SetImage(ebiten.NewImage(4, 4))
+
+	// A normal map can be assigned before the image.
+	s = NewSprite()
+	s.SetNormalMap(normalMap, l)
+	expectPanic(t, "SetImage before the image", func() {
+		s.SetImage(ebiten.NewImage(8, 8))
+	})
+	s.SetImage(ebiten.NewImage(16, 8))
+}
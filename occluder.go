@@ -0,0 +1,102 @@
+package graphics
+
+import (
+	"github.com/quasilyte/ebitengine-graphics/internal/visibility"
+	"github.com/quasilyte/gmath"
+)
+
+// Occluder is a light-blocking shape for the [LightingLayer].
+//
+// An occluder is a closed polygon, its points are
+// relative to the occluder Pos.
+// Use [LightingLayer.AddOccluder] to make it cast shadows.
+//
+// Occluders are not rendered by themselves.
+type Occluder struct {
+	Pos gmath.Pos
+
+	points []gmath.Vec
+
+	disabled bool
+	disposed bool
+}
+
+// NewPolygonOccluder returns an occluder of the specified shape.
+// The points are relative to the occluder Pos.
+//
+// The polygon is implicitly closed: there is no need to
+// repeat the first point at the end.
+//
+// It panics if there are less than 2 points.
+func NewPolygonOccluder(points []gmath.Vec) *Occluder {
+	if len(points) < 2 {
+		panic("an occluder polygon needs at least 2 points")
+	}
+	return &Occluder{points: points}
+}
+
+// NewRectOccluder returns a rectangle-shaped occluder.
+// The rect is relative to the occluder Pos.
+//
+// A static [Rect] can be turned into an occluder
+// by passing its BoundsRect() result here.
+func NewRectOccluder(rect gmath.Rect) *Occluder {
+	return NewPolygonOccluder([]gmath.Vec{
+		rect.Min,
+		{X: rect.Max.X, Y: rect.Min.Y},
+		rect.Max,
+		{X: rect.Min.X, Y: rect.Max.Y},
+	})
+}
+
+// Dispose marks this occluder for deletion.
+// After calling this method, IsDisposed will report true.
+func (o *Occluder) Dispose() {
+	o.disposed = true
+}
+
+// IsDisposed reports whether this occluder is marked for deletion.
+// IsDisposed returns true only after Disposed was called on this occluder.
+func (o *Occluder) IsDisposed() bool {
+	return o.disposed
+}
+
+// IsEnabled reports whether this occluder blocks the light.
+// Use SetEnabled to change this flag value.
+func (o *Occluder) IsEnabled() bool { return !o.disabled }
+
+// SetEnabled changes the Enabled flag value.
+// A disabled occluder doesn't cast any shadows.
+// Use IsEnabled to get the current flag value.
+func (o *Occluder) SetEnabled(enabled bool) { o.disabled = !enabled }
+
+// GetPoints returns the occluder polygon points.
+// The returned slice should not be modified.
+func (o *Occluder) GetPoints() []gmath.Vec {
+	return o.points
+}
+
+// SetPoints changes the occluder polygon shape.
+// See [NewPolygonOccluder] for more info.
+func (o *Occluder) SetPoints(points []gmath.Vec) {
+	if len(points) < 2 {
+		panic("an occluder polygon needs at least 2 points")
+	}
+	o.points = points
+}
+
+// appendSegments adds the occluder edges (in world coordinates) to dst.
+func (o *Occluder) appendSegments(dst []visibility.Segment) []visibility.Segment {
+	pos := o.Pos.Resolve()
+	prev := pos.Add(o.points[len(o.points)-1])
+	if len(o.points) == 2 {
+		// A single segment, there is no need to close it.
+		return append(dst, visibility.Segment{A: pos.Add(o.points[0]), B: prev})
+	}
+	for _, p := range o.points {
+		current := pos.Add(p)
+		dst = append(dst, visibility.Segment{A: prev, B: current})
+		prev = current
+	}
+	return dst
+}
@@ -0,0 +1,184 @@
+package graphics
+
+import (
+	"image"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/quasilyte/gmath"
+)
+
+// ParallaxLayer is a [SceneLayerDrawer] wrapper that scrolls
+// at a different rate than the camera.
+//
+// The camera offset is multiplied by the scroll factor before
+// being passed to the wrapped layer.
+// A factor of {1, 1} makes the layer behave like the wrapped layer itself,
+// {0.5, 0.5} makes it scroll twice as slow (looks farther away),
+// {0, 0} makes it ignore the camera completely.
+// The scaled offset is floored to keep the rendering pixel-perfect
+// (the axes with a factor of 1 are not affected).
+//
+// Since the offset is computed per camera during the rendering,
+// several parallax layers work correctly with multiple cameras
+// and their viewport rects.
+//
+// A parallax layer can also have an infinitely repeated background sprite,
+// see [ParallaxLayer.SetRepeatedSprite].
+type ParallaxLayer struct {
+	layer SceneLayerDrawer
+
+	scrollFactor gmath.Vec
+
+	sprite  *Sprite
+	repeatX bool
+	repeatY bool
+}
+
+// NewParallaxLayer wraps the provided layer into a parallax layer.
+// See [ParallaxLayer] doc comment to learn more about the scroll factor.
+func NewParallaxLayer(layer SceneLayerDrawer, scrollFactor gmath.Vec) *ParallaxLayer {
+	return &ParallaxLayer{
+		layer:        layer,
+		scrollFactor: scrollFactor,
+	}
+}
+
+// GetScrollFactor returns the current layer scroll factor.
+// Use SetScrollFactor to change it.
+func (l *ParallaxLayer) GetScrollFactor() gmath.Vec {
+	return l.scrollFactor
+}
+
+// SetScrollFactor changes the layer scroll factor.
+// Use GetScrollFactor to retrieve the current value.
+func (l *ParallaxLayer) SetScrollFactor(factor gmath.Vec) {
+	l.scrollFactor = factor
+}
+
+// SetRepeatedSprite assigns a background sprite that is drawn
+// before the wrapped layer objects.
+// A nil sprite removes the background.
+//
+// The sprite's current frame is repeated infinitely along
+// the selected axes, the sprite Pos is used as a pattern anchor.
+// The background covers the entire camera viewport along the repeated axis.
+//
+// The layer overrides the sprite repeat size and UV offset while
+// drawing it, the sprite's own values are restored afterwards.
+// Rotation is not supported for the background sprites.
+func (l *ParallaxLayer) SetRepeatedSprite(s *Sprite, repeatX, repeatY bool) {
+	l.sprite = s
+	l.repeatX = repeatX
+	l.repeatY = repeatY
+}
+
+func (l *ParallaxLayer) AddChild(o gsceneGraphics) {
+	l.layer.AddChild(o)
+}
+
+func (l *ParallaxLayer) Update(delta float64) {
+	l.layer.Update(delta)
+}
+
+func (l *ParallaxLayer) DrawWithOptions(dst *ebiten.Image, opts DrawOptions) {
+	opts = l.adjustOptions(opts)
+
+	if l.sprite != nil && !l.sprite.IsDisposed() {
+		l.drawRepeatedSprite(dst, opts)
+	}
+
+	l.layer.DrawWithOptions(dst, opts)
+}
+
+// adjustOptions applies the scroll factor to the camera offset.
+func (l *ParallaxLayer) adjustOptions(opts DrawOptions) DrawOptions {
+	opts.Offset = gmath.Vec{
+		X: scrollOffset(opts.Offset.X, l.scrollFactor.X),
+		Y: scrollOffset(opts.Offset.Y, l.scrollFactor.Y),
+	}
+	return opts
+}
+
+// scrollOffset returns the camera offset scaled by the factor.
+//
+// The scaled offset is floored to keep the rendering pixel-perfect.
+// A factor of 1 keeps the offset as is, so such layer
+// is drawn exactly like the unwrapped layer.
+func scrollOffset(offset, factor float64) float64 {
+	if factor == 1 {
+		return offset
+	}
+	return math.Floor(offset * factor)
+}
+
+// repeatedSpriteLayout describes how the repeated sprite is drawn.
+type repeatedSpriteLayout struct {
+	repeatWidth  int
+	repeatHeight int
+	uvOffset     gmath.Vec
+	drawOffset   gmath.Vec
+}
+
+func (l *ParallaxLayer) drawRepeatedSprite(dst *ebiten.Image, opts DrawOptions) {
+	s := l.sprite
+	layout, ok := l.layoutRepeatedSprite(dst.Bounds(), opts.Offset)
+	if !ok {
+		return
+	}
+
+	// The sprite repeat settings are overwritten only for this draw call.
+	prevWidth, prevHeight := s.GetRepeatSize()
+	prevUV := s.GetUVOffset()
+	s.SetRepeatSize(layout.repeatWidth, layout.repeatHeight)
+	s.SetUVOffset(layout.uvOffset)
+	s.DrawWithOptions(dst, DrawOptions{
+		Offset: layout.drawOffset,
+		Blend:  opts.Blend,
+	})
+	s.SetRepeatSize(prevWidth, prevHeight)
+	s.SetUVOffset(prevUV)
+}
+
+func (l *ParallaxLayer) layoutRepeatedSprite(bounds image.Rectangle, offset gmath.Vec) (repeatedSpriteLayout, bool) {
+	s := l.sprite
+	frameWidth := s.GetFrameWidth()
+	frameHeight := s.GetFrameHeight()
+	if frameWidth == 0 || frameHeight == 0 {
+		return repeatedSpriteLayout{}, false
+	}
+
+	// anchor is a pattern origin in the dst coordinates.
+	pos := s.calculatePos()
+	anchor := pos.Add(offset)
+	if s.IsCentered() {
+		anchor = anchor.Sub(gmath.Vec{X: float64(frameWidth / 2), Y: float64(frameHeight / 2)})
+	}
+
+	topLeft := anchor
+	layout := repeatedSpriteLayout{
+		repeatWidth:  frameWidth,
+		repeatHeight: frameHeight,
+	}
+	if l.repeatX {
+		layout.repeatWidth = bounds.Dx()
+		topLeft.X = float64(bounds.Min.X)
+		layout.uvOffset.X = topLeft.X - anchor.X
+	}
+	if l.repeatY {
+		layout.repeatHeight = bounds.Dy()
+		topLeft.Y = float64(bounds.Min.Y)
+		layout.uvOffset.Y = topLeft.Y - anchor.Y
+	}
+
+	// Now compute the draw offset that would put the
+	// sprite's top-left corner into the desired position.
+	layout.drawOffset = topLeft.Sub(pos)
+	if s.IsCentered() {
+		layout.drawOffset = layout.drawOffset.Add(gmath.Vec{
+			X: float64(layout.repeatWidth / 2),
+			Y: float64(layout.repeatHeight / 2),
+		})
+	}
+	return layout, true
+}
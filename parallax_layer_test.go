@@ -0,0 +1,140 @@
+package graphics
+
+import (
+	"image"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/quasilyte/gmath"
+)
+
+type recordingLayer struct {
+	offsets []gmath.Vec
+}
+
+func (l *recordingLayer) Update(delta float64)      {}
+func (l *recordingLayer) AddChild(o gsceneGraphics) {}
+
+func (l *recordingLayer) DrawWithOptions(dst *ebiten.Image, opts DrawOptions) {
+	l.offsets = append(l.offsets, opts.Offset)
+}
+
+func TestParallaxLayerOffset(t *testing.T) {
+	tests := []struct {
+		factor gmath.Vec
+		offset gmath.Vec
+		want   gmath.Vec
+	}{
+		{gmath.Vec{X: 1, Y: 1}, gmath.Vec{X: -10, Y: 20}, gmath.Vec{X: -10, Y: 20}},
+		{gmath.Vec{X: 1, Y: 1}, gmath.Vec{X: -10.25, Y: 20.75}, gmath.Vec{X: -10.25, Y: 20.75}},
+		{gmath.Vec{X: 0.5, Y: 0.5}, gmath.Vec{X: -10, Y: 20}, gmath.Vec{X: -5, Y: 10}},
+		{gmath.Vec{X: 0.5, Y: 0.5}, gmath.Vec{X: -11, Y: 21}, gmath.Vec{X: -6, Y: 10}},
+		{gmath.Vec{X: 0.5, Y: 1}, gmath.Vec{X: -11, Y: 21.5}, gmath.Vec{X: -6, Y: 21.5}},
+		{gmath.Vec{X: 0, Y: 0}, gmath.Vec{X: -11, Y: 21}, gmath.Vec{}},
+		{gmath.Vec{X: 2, Y: 0.25}, gmath.Vec{X: -3.25, Y: 7}, gmath.Vec{X: -7, Y: 1}},
+	}
+
+	dst := ebiten.NewImage(8, 8)
+	for i, test := range tests {
+		wrapped := &recordingLayer{}
+		l := NewParallaxLayer(wrapped, test.factor)
+		l.DrawWithOptions(dst, DrawOptions{Offset: test.offset})
+		if len(wrapped.offsets) != 1 {
+			t.Fatalf("test%d: the wrapped layer is drawn %d times", i, len(wrapped.offsets))
+		}
+		if have := wrapped.offsets[0]; have != test.want {
+			t.Errorf("test%d: factor=%v offset=%v:\nhave: %v\nwant: %v",
+				i, test.factor, test.offset, have, test.want)
+		}
+	}
+}
+
+func TestParallaxLayerRepeatedSpriteLayout(t *testing.T) {
+	tests := []struct {
+		name     string
+		pos      gmath.Vec
+		centered bool
+		repeatX  bool
+		repeatY  bool
+		bounds   image.Rectangle
+		offset   gmath.Vec
+		want     repeatedSpriteLayout
+	}{
+		{
+			name:    "repeat x",
+			pos:     gmath.Vec{X: 10, Y: 5},
+			repeatX: true,
+			bounds:  image.Rect(0, 0, 320, 240),
+			offset:  gmath.Vec{X: -100, Y: -40},
+			want: repeatedSpriteLayout{
+				repeatWidth:  320,
+				repeatHeight: 16,
+				uvOffset:     gmath.Vec{X: 90},
+				drawOffset:   gmath.Vec{X: -10, Y: -40},
+			},
+		},
+		{
+			name:     "repeat both centered",
+			pos:      gmath.Vec{X: 100, Y: 100},
+			centered: true,
+			repeatX:  true,
+			repeatY:  true,
+			bounds:   image.Rect(0, 0, 320, 240),
+			offset:   gmath.Vec{X: -20},
+			want: repeatedSpriteLayout{
+				repeatWidth:  320,
+				repeatHeight: 240,
+				uvOffset:     gmath.Vec{X: -64, Y: -92},
+				drawOffset:   gmath.Vec{X: 60, Y: 20},
+			},
+		},
+		{
+			name:    "repeat y viewport",
+			repeatY: true,
+			bounds:  image.Rect(50, 20, 150, 100),
+			offset:  gmath.Vec{X: 7, Y: 3},
+			want: repeatedSpriteLayout{
+				repeatWidth:  32,
+				repeatHeight: 80,
+				uvOffset:     gmath.Vec{Y: 17},
+				drawOffset:   gmath.Vec{X: 7, Y: 20},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s := NewSprite()
+		s.SetImage(ebiten.NewImage(32, 16))
+		s.SetCentered(test.centered)
+		s.Pos.Offset = test.pos
+		l := NewParallaxLayer(NewLayer(), gmath.Vec{X: 1, Y: 1})
+		l.SetRepeatedSprite(s, test.repeatX, test.repeatY)
+
+		have, ok := l.layoutRepeatedSprite(test.bounds, test.offset)
+		if !ok {
+			t.Fatalf("%s: the sprite is not drawn", test.name)
+		}
+		if have != test.want {
+			t.Errorf("%s:\nhave: %+v\nwant: %+v", test.name, have, test.want)
+		}
+	}
+}
+
+func TestParallaxLayerRepeatedSpriteState(t *testing.T) {
+	s := NewSprite()
+	s.SetImage(ebiten.NewImage(32, 16))
+	s.SetRepeatSize(64, 48)
+	s.SetUVOffset(gmath.Vec{X: 3, Y: 4})
+
+	l := NewParallaxLayer(NewLayer(), gmath.Vec{X: 0.5, Y: 0.5})
+	l.SetRepeatedSprite(s, true, false)
+	l.DrawWithOptions(ebiten.NewImage(100, 100), DrawOptions{Offset: gmath.Vec{X: -30}})
+
+	// The user-provided values are preserved.
+	if w, h := s.GetRepeatSize(); w != 64 || h != 48 {
+		t.Fatalf("repeat size is changed to %dx%d", w, h)
+	}
+	if uv := s.GetUVOffset(); uv != (gmath.Vec{X: 3, Y: 4}) {
+		t.Fatalf("uv offset is changed to %v", uv)
+	}
+}
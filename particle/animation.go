@@ -0,0 +1,230 @@
+package particle
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/quasilyte/gmath"
+)
+
+// AnimationMode describes how the [SpriteAnimation] frames are selected.
+type AnimationMode uint8
+
+const (
+	// AnimateByProgress spreads the frames over the particle lifetime:
+	// every particle shows all of its frames, regardless of its lifetime.
+	AnimateByProgress AnimationMode = iota
+
+	// AnimateByFPS changes the frames at a fixed rate.
+	AnimateByFPS
+)
+
+var animationModeNames = [...]string{
+	AnimateByProgress: "progress",
+	AnimateByFPS:      "fps",
+}
+
+func (m AnimationMode) String() string {
+	if int(m) < len(animationModeNames) {
+		return animationModeNames[m]
+	}
+	return fmt.Sprintf("AnimationMode(%d)", m)
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (m AnimationMode) MarshalText() ([]byte, error) {
+	if int(m) >= len(animationModeNames) {
+		return nil, fmt.Errorf("invalid animation mode value %d", m)
+	}
+	return []byte(animationModeNames[m]), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (m *AnimationMode) UnmarshalText(data []byte) error {
+	for i, name := range animationModeNames {
+		if name == string(data) {
+			*m = AnimationMode(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown animation mode %q", data)
+}
+
+// SpriteAnimation describes the particle sprite sheet animation.
+//
+// The template image is treated as a sprite sheet: a grid of the
+// equally sized frames. The frames are numbered left-to-right, top-to-bottom.
+type SpriteAnimation struct {
+	FrameWidth  int `json:"frame_width"`
+	FrameHeight int `json:"frame_height"`
+
+	// NumFrames is a number of the used frames.
+	// A zero value means "all frames of the sheet".
+	NumFrames int `json:"num_frames,omitempty"`
+
+	Mode AnimationMode `json:"mode"`
+
+	// FPS is a number of frames per second.
+	// It's only used by the [AnimateByFPS] mode.
+	FPS float64 `json:"fps,omitempty"`
+
+	// Loop makes the animation start over after its last frame.
+	// Without a loop, the last frame is shown until the particle expires.
+	Loop bool `json:"loop,omitempty"`
+
+	// RandomStartFrame makes every particle start its animation
+	// from a random frame, so the particles look less alike.
+	RandomStartFrame bool `json:"random_start_frame,omitempty"`
+}
+
+func (a *SpriteAnimation) validate(imgWidth, imgHeight int) error {
+	if a.FrameWidth <= 0 || a.FrameHeight <= 0 {
+		return fmt.Errorf("frame size should be positive")
+	}
+	if a.FrameWidth > imgWidth || a.FrameHeight > imgHeight {
+		return fmt.Errorf("frame size %dx%d is bigger than the image size %dx%d",
+			a.FrameWidth, a.FrameHeight, imgWidth, imgHeight)
+	}
+	numFrames := (imgWidth / a.FrameWidth) * (imgHeight / a.FrameHeight)
+	if a.NumFrames < 0 || a.NumFrames > numFrames {
+		return fmt.Errorf("num frames should be in [0, %d] range", numFrames)
+	}
+	switch a.Mode {
+	case AnimateByProgress:
+	case AnimateByFPS:
+		if a.FPS <= 0 {
+			return fmt.Errorf("fps should be positive")
+		}
+	default:
+		return fmt.Errorf("invalid animation mode value %d", a.Mode)
+	}
+	return nil
+}
+
+// SetAnimation makes the particles cycle through the sprite sheet frames.
+// The template image is used as a sprite sheet,
+// so every particle has a frame size instead of the image size.
+// A zero value animation removes the animation.
+//
+// The sprite sheet should be assigned (see [Template.SetImage])
+// before the animation is set; changing the image later
+// keeps the animation, so the new image should have a compatible layout.
+//
+// It panics if the animation is invalid for the current template image.
+func (tmpl *Template) SetAnimation(a SpriteAnimation) {
+	if a != (SpriteAnimation{}) {
+		bounds := tmpl.img.Bounds()
+		if err := a.validate(bounds.Dx(), bounds.Dy()); err != nil {
+			panic(err.Error())
+		}
+	}
+	tmpl.animation = a
+	tmpl.updateAnimation()
+}
+
+// GetAnimation returns the current sprite sheet animation settings.
+// Use SetAnimation to change them.
+func (tmpl *Template) GetAnimation() SpriteAnimation {
+	return tmpl.animation
+}
+
+func (tmpl *Template) updateAnimation() {
+	a := &tmpl.animation
+	tmpl.numFrames = 0
+	tmpl.needsRandBits &^= frameRandBit
+	if a.FrameWidth == 0 {
+		return
+	}
+
+	bounds := tmpl.img.Bounds()
+	tmpl.frameCols = max(bounds.Dx()/a.FrameWidth, 1)
+	tmpl.numFrames = a.NumFrames
+	if tmpl.numFrames == 0 {
+		tmpl.numFrames = tmpl.frameCols * max(bounds.Dy()/a.FrameHeight, 1)
+	}
+	if a.RandomStartFrame {
+		tmpl.needsRandBits |= frameRandBit
+	}
+}
+
+// frameSize returns the particle image size.
+// For the animated particles, it's a frame size.
+func (tmpl *Template) frameSize() (width, height int) {
+	if tmpl.numFrames != 0 {
+		return tmpl.animation.FrameWidth, tmpl.animation.FrameHeight
+	}
+	bounds := tmpl.img.Bounds()
+	return bounds.Dx(), bounds.Dy()
+}
+
+// particleFrame returns the current particle frame index.
+// The t is the particle lifetime progress, the seconds is its age.
+func (tmpl *Template) particleFrame(p *particle, t, seconds float32) int {
+	n := tmpl.numFrames
+	start := (int(p.imageSeed) * n) >> 8
+
+	var offset int
+	if tmpl.animation.Mode == AnimateByFPS {
+		offset = int(seconds * float32(tmpl.animation.FPS))
+	} else {
+		offset = int(t * float32(n))
+	}
+
+	frame := start + offset
+	if tmpl.animation.Loop {
+		return frame % n
+	}
+	return min(frame, n-1)
+}
+
+// SetParticleSpin is a shorthand for SetParticleSpinRange(spin, spin).
+func (tmpl *Template) SetParticleSpin(spin gmath.Rad) {
+	tmpl.SetParticleSpinRange(spin, spin)
+}
+
+// SetParticleSpinRange assigns the particle image angular velocity (radians per second).
+// Unlike the turn rate, it only rotates the particle image,
+// the particle movement is not affected.
+//
+// Every particle gets a random spin from the [min, max] range.
+// Use a range like [-x, x] to make the particles spin in both directions.
+func (tmpl *Template) SetParticleSpinRange(minSpin, maxSpin gmath.Rad) {
+	if maxSpin < minSpin {
+		panic("maxSpin can't be less than minSpin")
+	}
+
+	if minSpin != maxSpin {
+		tmpl.needsRandBits |= spinRandBit
+	} else {
+		tmpl.needsRandBits &^= spinRandBit
+	}
+
+	tmpl.particleMinSpin = float32(minSpin)
+	tmpl.particleMaxSpin = float32(maxSpin)
+	tmpl.particleSpinStep = float32(maxSpin-minSpin) / math.MaxUint8
+	tmpl.hasSpin = minSpin != 0 || maxSpin != 0
+}
+
+// GetParticleSpinRange returns the current particle spin range.
+// Use SetParticleSpinRange to change it.
+func (tmpl *Template) GetParticleSpinRange() (minSpin, maxSpin gmath.Rad) {
+	minSpin = gmath.Rad(tmpl.particleMinSpin)
+	maxSpin = gmath.Rad(tmpl.particleMaxSpin)
+	return minSpin, maxSpin
+}
+
+func (tmpl *Template) particleSpin(p *particle) float32 {
+	return tmpl.particleMinSpin + tmpl.particleSpinStep*float32(imageSpinSeed(p.imageSeed))
+}
+
+// imageSpinSeed derives the spin seed from the particle image seed.
+//
+// The particle struct has no room for a separate spin seed
+// (it would grow from 20 to 24 bytes), so the start frame and
+// the spin share a single random byte.
+// The odd multiplier makes this mapping a permutation of [0, 255],
+// so the spin is still uniformly distributed, but it's not
+// linearly tied to the start frame.
+func imageSpinSeed(seed uint8) uint8 {
+	return seed*167 + 89
+}
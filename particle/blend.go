@@ -0,0 +1,117 @@
+package particle
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	graphics "github.com/quasilyte/ebitengine-graphics"
+)
+
+// BlendMode describes how the particles are combined with the destination image.
+type BlendMode uint8
+
+const (
+	// BlendNormal is a regular alpha blending (source-over).
+	BlendNormal BlendMode = iota
+
+	// BlendAdditive adds the particle colors to the destination.
+	// It's good for the fire, sparks and glow effects.
+	BlendAdditive
+
+	// BlendMultiply multiplies the destination by the particle colors.
+	// It's good for the shadows and smoke that darkens the scene.
+	BlendMultiply
+)
+
+var blendModeNames = [...]string{
+	BlendNormal:   "normal",
+	BlendAdditive: "additive",
+	BlendMultiply: "multiply",
+}
+
+func (m BlendMode) String() string {
+	if int(m) < len(blendModeNames) {
+		return blendModeNames[m]
+	}
+	return fmt.Sprintf("BlendMode(%d)", m)
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (m BlendMode) MarshalText() ([]byte, error) {
+	if int(m) >= len(blendModeNames) {
+		return nil, fmt.Errorf("invalid blend mode value %d", m)
+	}
+	return []byte(blendModeNames[m]), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (m *BlendMode) UnmarshalText(data []byte) error {
+	for i, name := range blendModeNames {
+		if name == string(data) {
+			*m = BlendMode(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown blend mode %q", data)
+}
+
+// blendMultiply is a multiply blending for the premultiplied-alpha colors.
+// The transparent particle pixels leave the destination unchanged.
+var blendMultiply = ebiten.Blend{
+	BlendFactorSourceRGB:        ebiten.BlendFactorDestinationColor,
+	BlendFactorSourceAlpha:      ebiten.BlendFactorDestinationAlpha,
+	BlendFactorDestinationRGB:   ebiten.BlendFactorOneMinusSourceAlpha,
+	BlendFactorDestinationAlpha: ebiten.BlendFactorOneMinusSourceAlpha,
+	BlendOperationRGB:           ebiten.BlendOperationAdd,
+	BlendOperationAlpha:         ebiten.BlendOperationAdd,
+}
+
+func (m BlendMode) blend() ebiten.Blend {
+	switch m {
+	case BlendAdditive:
+		return ebiten.BlendLighter
+	case BlendMultiply:
+		return blendMultiply
+	default:
+		return ebiten.BlendSourceOver
+	}
+}
+
+// SetBlendMode assigns the particles blend mode.
+// The default blend mode is [BlendNormal].
+//
+// [graphics.DrawOptions.Blend] passed to the [Renderer] overrides this blend mode.
+//
+// The emitters are grouped by their image, blend mode and shader
+// during the rendering, so the blend mode should be assigned
+// before the emitter is added to the [Renderer].
+func (tmpl *Template) SetBlendMode(m BlendMode) {
+	if m > BlendMultiply {
+		panic("invalid blend mode")
+	}
+	tmpl.blendMode = m
+}
+
+// GetBlendMode returns the current particles blend mode.
+// Use SetBlendMode to change it.
+func (tmpl *Template) GetBlendMode() BlendMode {
+	return tmpl.blendMode
+}
+
+// SetShader assigns a shader that is used to render the particles.
+// The shader gets the particle image as Images[0];
+// the particle color is passed as a vertex color.
+// A nil shader removes the shader.
+//
+// Like with the blend mode, the shader should be assigned
+// before the emitter is added to the [Renderer].
+// A disabled shader (see [graphics.Shader.Enabled]) is ignored.
+func (tmpl *Template) SetShader(s *graphics.Shader) {
+	tmpl.shader = s
+}
+
+// GetShader returns the current particles shader.
+// Use SetShader to change it.
+func (tmpl *Template) GetShader() *graphics.Shader {
+	return tmpl.shader
+}
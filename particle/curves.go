@@ -0,0 +1,321 @@
+package particle
+
+import (
+	"fmt"
+	"slices"
+
+	graphics "github.com/quasilyte/ebitengine-graphics"
+)
+
+// curveLUTSize is a number of the precomputed curve samples.
+// The curves are baked into the lookup tables, so sampling
+// them during the rendering is just an array access.
+const curveLUTSize = 256
+
+// Easing is an interpolation function used between two [Keyframe] values.
+type Easing uint8
+
+const (
+	// EaseLinear is a constant speed interpolation.
+	EaseLinear Easing = iota
+
+	// EaseIn starts slow and then accelerates.
+	EaseIn
+
+	// EaseOut starts fast and then decelerates.
+	EaseOut
+
+	// EaseInOut is slow at both ends.
+	EaseInOut
+
+	// EaseStep holds the keyframe value until the next keyframe.
+	EaseStep
+)
+
+var easingNames = [...]string{
+	EaseLinear: "linear",
+	EaseIn:     "in",
+	EaseOut:    "out",
+	EaseInOut:  "inout",
+	EaseStep:   "step",
+}
+
+func (e Easing) String() string {
+	if int(e) < len(easingNames) {
+		return easingNames[e]
+	}
+	return fmt.Sprintf("Easing(%d)", e)
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (e Easing) MarshalText() ([]byte, error) {
+	if int(e) >= len(easingNames) {
+		return nil, fmt.Errorf("invalid easing value %d", e)
+	}
+	return []byte(easingNames[e]), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (e *Easing) UnmarshalText(data []byte) error {
+	for i, name := range easingNames {
+		if name == string(data) {
+			*e = Easing(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown easing %q", data)
+}
+
+func (e Easing) apply(t float32) float32 {
+	switch e {
+	case EaseIn:
+		return t * t
+	case EaseOut:
+		return t * (2 - t)
+	case EaseInOut:
+		if t < 0.5 {
+			return 2 * t * t
+		}
+		return -1 + (4-2*t)*t
+	case EaseStep:
+		// The next keyframe value is used at its exact time.
+		if t < 1 {
+			return 0
+		}
+		return 1
+	default:
+		return t
+	}
+}
+
+// Keyframe is a [Curve] control point.
+type Keyframe struct {
+	// Time is a particle lifetime progress in [0, 1] range.
+	Time float32 `json:"time"`
+
+	Value float32 `json:"value"`
+
+	// Easing is used to interpolate between this
+	// keyframe and the next one.
+	Easing Easing `json:"easing,omitempty"`
+}
+
+// Curve is a scalar function of the particle lifetime progress.
+//
+// The keyframes should be sorted by their Time.
+// Before the first keyframe, the curve has the first keyframe value;
+// after the last keyframe, it has the last keyframe value.
+// An empty curve is not used.
+type Curve struct {
+	Keys []Keyframe `json:"keys"`
+}
+
+// Sample returns the curve value at the specified progress.
+func (c *Curve) Sample(t float32) float32 {
+	keys := c.Keys
+	if len(keys) == 0 {
+		return 1
+	}
+	if t <= keys[0].Time {
+		return keys[0].Value
+	}
+	for i := 1; i < len(keys); i++ {
+		next := keys[i]
+		if t > next.Time {
+			continue
+		}
+		prev := keys[i-1]
+		k := prev.Easing.apply(segmentProgress(prev.Time, next.Time, t))
+		return prev.Value + (next.Value-prev.Value)*k
+	}
+	return keys[len(keys)-1].Value
+}
+
+func (c *Curve) validate() error {
+	for i, k := range c.Keys {
+		if k.Time < 0 || k.Time > 1 {
+			return fmt.Errorf("keyframe %d: time is not in [0, 1] range", i)
+		}
+		if i != 0 && k.Time < c.Keys[i-1].Time {
+			return fmt.Errorf("keyframe %d: keyframes are not sorted by time", i)
+		}
+		if int(k.Easing) >= len(easingNames) {
+			return fmt.Errorf("keyframe %d: invalid easing value %d", i, k.Easing)
+		}
+	}
+	return nil
+}
+
+func (c *Curve) bake() *[curveLUTSize]float32 {
+	var lut [curveLUTSize]float32
+	for i := range lut {
+		lut[i] = c.Sample(float32(i) / (curveLUTSize - 1))
+	}
+	return &lut
+}
+
+// ColorStop is a [ColorGradient] control point.
+type ColorStop struct {
+	// Time is a particle lifetime progress in [0, 1] range.
+	Time float32 `json:"time"`
+
+	Color graphics.ColorScale `json:"color"`
+}
+
+// ColorGradient is a color function of the particle lifetime progress.
+// The colors are interpolated linearly between the stops.
+//
+// The stops should be sorted by their Time.
+// An empty gradient is not used.
+type ColorGradient struct {
+	Stops []ColorStop `json:"stops"`
+}
+
+// Sample returns the gradient color at the specified progress.
+func (g *ColorGradient) Sample(t float32) graphics.ColorScale {
+	stops := g.Stops
+	if len(stops) == 0 {
+		return graphics.ColorScale{R: 1, G: 1, B: 1, A: 1}
+	}
+	if t <= stops[0].Time {
+		return stops[0].Color
+	}
+	for i := 1; i < len(stops); i++ {
+		next := stops[i]
+		if t > next.Time {
+			continue
+		}
+		prev := stops[i-1]
+		k := segmentProgress(prev.Time, next.Time, t)
+		return graphics.ColorScale{
+			R: prev.Color.R + (next.Color.R-prev.Color.R)*k,
+			G: prev.Color.G + (next.Color.G-prev.Color.G)*k,
+			B: prev.Color.B + (next.Color.B-prev.Color.B)*k,
+			A: prev.Color.A + (next.Color.A-prev.Color.A)*k,
+		}
+	}
+	return stops[len(stops)-1].Color
+}
+
+func (g *ColorGradient) validate() error {
+	for i, s := range g.Stops {
+		if s.Time < 0 || s.Time > 1 {
+			return fmt.Errorf("color stop %d: time is not in [0, 1] range", i)
+		}
+		if i != 0 && s.Time < g.Stops[i-1].Time {
+			return fmt.Errorf("color stop %d: stops are not sorted by time", i)
+		}
+	}
+	return nil
+}
+
+func (g *ColorGradient) bake() *[curveLUTSize]graphics.ColorScale {
+	var lut [curveLUTSize]graphics.ColorScale
+	for i := range lut {
+		lut[i] = g.Sample(float32(i) / (curveLUTSize - 1))
+	}
+	return &lut
+}
+
+func segmentProgress(from, to, t float32) float32 {
+	if to <= from {
+		return 1
+	}
+	return (t - from) / (to - from)
+}
+
+// lutIndex maps the lifetime progress to the lookup table index.
+func lutIndex(progress float32) int {
+	if !(progress > 0) { // Also catches NaN
+		return 0
+	}
+	if progress >= 1 {
+		return curveLUTSize - 1
+	}
+	return int(progress * (curveLUTSize - 1))
+}
+
+// alphaFade computes the alpha multiplier for the fade-in/out.
+func (tmpl *Template) alphaFade(progress float32) float32 {
+	alpha := float32(1)
+	if tmpl.alphaFadeIn > 0 && progress < tmpl.alphaFadeIn {
+		alpha = progress / tmpl.alphaFadeIn
+	}
+	if tmpl.alphaFadeOut > 0 && progress > 1-tmpl.alphaFadeOut {
+		alpha = min(alpha, (1-progress)/tmpl.alphaFadeOut)
+	}
+	return max(alpha, 0)
+}
+
+// SetColorGradient assigns a color that changes over the particle lifetime.
+// The gradient color is multiplied with the particle palette color.
+// An empty gradient removes the color gradient.
+//
+// The gradient is baked into a lookup table,
+// so it's cheap to use during the rendering.
+//
+// It panics if the gradient stops are invalid.
+func (tmpl *Template) SetColorGradient(g ColorGradient) {
+	if err := g.validate(); err != nil {
+		panic(err.Error())
+	}
+	// The caller may reuse the stops slice, so a copy is stored.
+	g.Stops = slices.Clone(g.Stops)
+	tmpl.colorGradient = g
+	tmpl.colorLUT = nil
+	if len(g.Stops) != 0 {
+		tmpl.colorLUT = g.bake()
+	}
+}
+
+// GetColorGradient returns the current color gradient.
+// Use SetColorGradient to change it.
+// The returned stops slice should not be modified.
+func (tmpl *Template) GetColorGradient() ColorGradient {
+	return tmpl.colorGradient
+}
+
+// SetScaleCurve assigns a scaling multiplier that changes over the particle lifetime.
+// The curve value is multiplied with the particle scaling.
+// An empty curve removes the scale curve.
+//
+// The curve is baked into a lookup table,
+// so it's cheap to use during the rendering.
+//
+// It panics if the curve keyframes are invalid.
+func (tmpl *Template) SetScaleCurve(c Curve) {
+	if err := c.validate(); err != nil {
+		panic(err.Error())
+	}
+	c.Keys = slices.Clone(c.Keys)
+	tmpl.scaleCurve = c
+	tmpl.scaleLUT = nil
+	if len(c.Keys) != 0 {
+		tmpl.scaleLUT = c.bake()
+	}
+}
+
+// GetScaleCurve returns the current scale curve.
+// Use SetScaleCurve to change it.
+// The returned keys slice should not be modified.
+func (tmpl *Template) GetScaleCurve() Curve {
+	return tmpl.scaleCurve
+}
+
+// SetAlphaFade makes the particles fade in and fade out.
+// Both values are the lifetime fractions in [0, 1] range:
+// fadeIn=0.1 means that the particle becomes fully opaque after
+// the 10% of its lifetime. Zero values disable the fading.
+func (tmpl *Template) SetAlphaFade(fadeIn, fadeOut float64) {
+	if fadeIn < 0 || fadeIn > 1 || fadeOut < 0 || fadeOut > 1 {
+		panic("alpha fade values should be in [0, 1] range")
+	}
+	tmpl.alphaFadeIn = float32(fadeIn)
+	tmpl.alphaFadeOut = float32(fadeOut)
+}
+
+// GetAlphaFade returns the current alpha fade settings.
+// Use SetAlphaFade to change them.
+func (tmpl *Template) GetAlphaFade() (fadeIn, fadeOut float64) {
+	return float64(tmpl.alphaFadeIn), float64(tmpl.alphaFadeOut)
+}
@@ -0,0 +1,190 @@
+package particle
+
+import (
+	"math"
+	"testing"
+
+	graphics "github.com/quasilyte/ebitengine-graphics"
+)
+
+func TestTemplateCurvesAreCopied(t *testing.T) {
+	tmpl := NewTemplate()
+
+	keys := []Keyframe{{Time: 0, Value: 1}, {Time: 1, Value: 2}}
+	tmpl.SetScaleCurve(Curve{Keys: keys})
+	keys[1].Value = 100
+	if v := tmpl.GetScaleCurve().Keys[1].Value; v != 2 {
+		t.Fatalf("scale curve is affected by the caller's slice: %v", v)
+	}
+
+	stops := []ColorStop{
+		{Time: 0, Color: graphics.ColorScale{R: 1, G: 1, B: 1, A: 1}},
+		{Time: 1, Color: graphics.ColorScale{R: 1, G: 0, B: 0, A: 1}},
+	}
+	tmpl.SetColorGradient(ColorGradient{Stops: stops})
+	stops[1].Time = 0.5
+	if v := tmpl.GetColorGradient().Stops[1].Time; v != 1 {
+		t.Fatalf("color gradient is affected by the caller's slice: %v", v)
+	}
+}
+
+func TestCurveSample(t *testing.T) {
+	segment := func(e Easing) Curve {
+		return Curve{Keys: []Keyframe{
+			{Time: 0, Value: 10, Easing: e},
+			{Time: 1, Value: 20},
+		}}
+	}
+
+	tests := []struct {
+		name  string
+		curve Curve
+		t     float32
+		want  float32
+	}{
+		{"empty", Curve{}, 0.5, 1},
+
+		{"linear start", segment(EaseLinear), 0, 10},
+		{"linear 0.25", segment(EaseLinear), 0.25, 12.5},
+		{"linear 0.5", segment(EaseLinear), 0.5, 15},
+		{"linear end", segment(EaseLinear), 1, 20},
+
+		{"in 0.25", segment(EaseIn), 0.25, 10.625},
+		{"in 0.5", segment(EaseIn), 0.5, 12.5},
+		{"in 0.75", segment(EaseIn), 0.75, 15.625},
+		{"in end", segment(EaseIn), 1, 20},
+
+		{"out 0.25", segment(EaseOut), 0.25, 14.375},
+		{"out 0.5", segment(EaseOut), 0.5, 17.5},
+		{"out 0.75", segment(EaseOut), 0.75, 19.375},
+		{"out end", segment(EaseOut), 1, 20},
+
+		{"inout 0.25", segment(EaseInOut), 0.25, 11.25},
+		{"inout 0.5", segment(EaseInOut), 0.5, 15},
+		{"inout 0.75", segment(EaseInOut), 0.75, 18.75},
+		{"inout end", segment(EaseInOut), 1, 20},
+
+		{"step start", segment(EaseStep), 0, 10},
+		{"step 0.5", segment(EaseStep), 0.5, 10},
+		{"step 0.99", segment(EaseStep), 0.99, 10},
+		{"step end", segment(EaseStep), 1, 20},
+	}
+
+	// The keys don't cover the entire [0, 1] range.
+	inner := Curve{Keys: []Keyframe{
+		{Time: 0.25, Value: 4},
+		{Time: 0.75, Value: 8},
+	}}
+	tests = append(tests, []struct {
+		name  string
+		curve Curve
+		t     float32
+		want  float32
+	}{
+		{"before first", inner, 0, 4},
+		{"before first negative", inner, -1, 4},
+		{"first", inner, 0.25, 4},
+		{"middle", inner, 0.5, 6},
+		{"last", inner, 0.75, 8},
+		{"after last", inner, 0.9, 8},
+		{"after last out of range", inner, 2, 8},
+		{"single key", Curve{Keys: inner.Keys[:1]}, 0.9, 4},
+	}...)
+
+	// Equal time keys make a discontinuity: the curve approaches
+	// the first key from the left and continues from the second one.
+	jump := Curve{Keys: []Keyframe{
+		{Time: 0, Value: 0},
+		{Time: 0.5, Value: 1},
+		{Time: 0.5, Value: 5},
+		{Time: 1, Value: 7},
+	}}
+	tests = append(tests, []struct {
+		name  string
+		curve Curve
+		t     float32
+		want  float32
+	}{
+		{"jump before", jump, 0.25, 0.5},
+		{"jump at", jump, 0.5, 1},
+		{"jump after", jump, 0.75, 6},
+		{"jump end", jump, 1, 7},
+	}...)
+
+	for _, test := range tests {
+		have := test.curve.Sample(test.t)
+		if math.Abs(float64(have-test.want)) > 1e-5 {
+			t.Errorf("%s: Sample(%v): have %v, want %v", test.name, test.t, have, test.want)
+		}
+	}
+}
+
+func TestColorGradientSample(t *testing.T) {
+	red := graphics.ColorScale{R: 1, G: 0, B: 0, A: 1}
+	green := graphics.ColorScale{R: 0, G: 1, B: 0, A: 1}
+	blue := graphics.ColorScale{R: 0, G: 0, B: 1, A: 0}
+	white := graphics.ColorScale{R: 1, G: 1, B: 1, A: 1}
+
+	g := ColorGradient{Stops: []ColorStop{
+		{Time: 0.2, Color: red},
+		{Time: 0.6, Color: blue},
+		{Time: 0.6, Color: green},
+		{Time: 0.8, Color: white},
+	}}
+
+	tests := []struct {
+		name     string
+		gradient ColorGradient
+		t        float32
+		want     graphics.ColorScale
+	}{
+		{"empty", ColorGradient{}, 0.5, white},
+		{"single stop", ColorGradient{Stops: g.Stops[:1]}, 0.9, red},
+
+		{"before first", g, 0, red},
+		{"before first negative", g, -1, red},
+		{"first", g, 0.2, red},
+		{"middle", g, 0.4, graphics.ColorScale{R: 0.5, G: 0, B: 0.5, A: 0.5}},
+
+		// Equal time stops.
+		{"jump at", g, 0.6, blue},
+		{"jump after", g, 0.7, graphics.ColorScale{R: 0.5, G: 1, B: 0.5, A: 1}},
+
+		{"last", g, 0.8, white},
+		{"after last", g, 0.9, white},
+		{"after last out of range", g, 2, white},
+	}
+
+	for _, test := range tests {
+		have := test.gradient.Sample(test.t)
+		diff := math.Abs(float64(have.R-test.want.R)) +
+			math.Abs(float64(have.G-test.want.G)) +
+			math.Abs(float64(have.B-test.want.B)) +
+			math.Abs(float64(have.A-test.want.A))
+		if diff > 1e-5 {
+			t.Errorf("%s: Sample(%v):\nhave: %+v\nwant: %+v", test.name, test.t, have, test.want)
+		}
+	}
+}
+
+func TestLUTIndex(t *testing.T) {
+	tests := []struct {
+		progress float32
+		want     int
+	}{
+		{0, 0},
+		{-0.5, 0},
+		{float32(math.Inf(-1)), 0},
+		{float32(math.NaN()), 0},
+		{0.5, (curveLUTSize - 1) / 2},
+		{1, curveLUTSize - 1},
+		{1.5, curveLUTSize - 1},
+		{float32(math.Inf(1)), curveLUTSize - 1},
+	}
+
+	for _, test := range tests {
+		if have := lutIndex(test.progress); have != test.want {
+			t.Errorf("lutIndex(%v): have %d, want %d", test.progress, have, test.want)
+		}
+	}
+}
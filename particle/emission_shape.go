@@ -0,0 +1,385 @@
+package particle
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/quasilyte/gmath"
+)
+
+// EmissionShapeKind selects the [EmissionShape] geometry.
+type EmissionShapeKind uint8
+
+const (
+	// ShapePoint spawns all particles at the emitter position.
+	// It's a default emission shape.
+	ShapePoint EmissionShapeKind = iota
+
+	// ShapeCircle spawns particles inside a circle (or on its edge).
+	// A non-zero inner radius turns it into a ring.
+	ShapeCircle
+
+	// ShapeArc is like ShapeCircle, but only the [ArcFrom, ArcTo] sector is used.
+	ShapeArc
+
+	// ShapeRect spawns particles inside a rectangle (or on its edge).
+	// The rectangle is centered around the emitter position.
+	ShapeRect
+
+	// ShapeLine spawns particles along the line segment.
+	ShapeLine
+
+	// ShapePolygon spawns particles inside a polygon (or on its edge).
+	ShapePolygon
+)
+
+var emissionShapeKindNames = [...]string{
+	ShapePoint:   "point",
+	ShapeCircle:  "circle",
+	ShapeArc:     "arc",
+	ShapeRect:    "rect",
+	ShapeLine:    "line",
+	ShapePolygon: "polygon",
+}
+
+func (k EmissionShapeKind) String() string {
+	if int(k) < len(emissionShapeKindNames) {
+		return emissionShapeKindNames[k]
+	}
+	return fmt.Sprintf("EmissionShapeKind(%d)", k)
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (k EmissionShapeKind) MarshalText() ([]byte, error) {
+	if int(k) >= len(emissionShapeKindNames) {
+		return nil, fmt.Errorf("invalid emission shape kind %d", k)
+	}
+	return []byte(emissionShapeKindNames[k]), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (k *EmissionShapeKind) UnmarshalText(data []byte) error {
+	for i, name := range emissionShapeKindNames {
+		if name == string(data) {
+			*k = EmissionShapeKind(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown emission shape kind %q", data)
+}
+
+// EmissionShape describes the area where the particles are spawned.
+// The shape is positioned relative to the emitter position
+// and it's rotated by the emitter Rotation.
+//
+// Use the constructor functions like [CircleShape] to create the shapes.
+// See [Template.SetEmissionShape].
+type EmissionShape struct {
+	Kind EmissionShapeKind `json:"kind"`
+
+	// Radius and InnerRadius are used by the circle and arc shapes.
+	Radius      float64 `json:"radius,omitempty"`
+	InnerRadius float64 `json:"inner_radius,omitempty"`
+
+	// ArcFrom and ArcTo are used by the arc shape.
+	ArcFrom gmath.Rad `json:"arc_from,omitempty"`
+	ArcTo   gmath.Rad `json:"arc_to,omitempty"`
+
+	// Size is used by the rect shape.
+	Size gmath.Vec `json:"size,omitempty"`
+
+	// Points are used by the line (2 points) and polygon (3+ points) shapes.
+	Points []gmath.Vec `json:"points,omitempty"`
+
+	// Edge makes the particles spawn only on the shape outline.
+	// The line shape is always an edge.
+	Edge bool `json:"edge,omitempty"`
+}
+
+// PointShape returns a shape that spawns particles at the emitter position.
+func PointShape() EmissionShape {
+	return EmissionShape{Kind: ShapePoint}
+}
+
+// CircleShape returns a circle emission shape.
+// If edge is true, particles are spawned only on the circle outline.
+func CircleShape(radius float64, edge bool) EmissionShape {
+	return EmissionShape{Kind: ShapeCircle, Radius: radius, Edge: edge}
+}
+
+// RingShape returns a ring emission shape.
+// The particles are spawned between the inner and outer circles.
+func RingShape(innerRadius, outerRadius float64) EmissionShape {
+	return EmissionShape{Kind: ShapeCircle, Radius: outerRadius, InnerRadius: innerRadius}
+}
+
+// ArcShape returns a circle sector emission shape.
+// If edge is true, particles are spawned only on the arc outline.
+func ArcShape(radius float64, from, to gmath.Rad, edge bool) EmissionShape {
+	return EmissionShape{Kind: ShapeArc, Radius: radius, ArcFrom: from, ArcTo: to, Edge: edge}
+}
+
+// RectShape returns a rectangle emission shape.
+// The rectangle is centered around the emitter position.
+// If edge is true, particles are spawned only on the rectangle outline.
+func RectShape(size gmath.Vec, edge bool) EmissionShape {
+	return EmissionShape{Kind: ShapeRect, Size: size, Edge: edge}
+}
+
+// LineShape returns a line segment emission shape.
+func LineShape(a, b gmath.Vec) EmissionShape {
+	return EmissionShape{Kind: ShapeLine, Points: []gmath.Vec{a, b}, Edge: true}
+}
+
+// PolygonShape returns a polygon emission shape.
+// If edge is true, particles are spawned only on the polygon outline.
+//
+// The polygon is implicitly closed and it can be concave.
+func PolygonShape(points []gmath.Vec, edge bool) EmissionShape {
+	return EmissionShape{Kind: ShapePolygon, Points: points, Edge: edge}
+}
+
+func (s *EmissionShape) validate() error {
+	switch s.Kind {
+	case ShapePoint:
+		return nil
+	case ShapeCircle, ShapeArc:
+		if s.Radius < 0 || s.InnerRadius < 0 {
+			return errors.New("emission shape radius can't be negative")
+		}
+		if s.InnerRadius > s.Radius {
+			return errors.New("emission shape inner radius can't be greater than the radius")
+		}
+		if s.Kind == ShapeArc && s.ArcTo < s.ArcFrom {
+			return errors.New("emission shape arc_to can't be less than arc_from")
+		}
+		return nil
+	case ShapeRect:
+		if s.Size.X < 0 || s.Size.Y < 0 {
+			return errors.New("emission shape size can't be negative")
+		}
+		return nil
+	case ShapeLine:
+		if len(s.Points) != 2 {
+			return errors.New("line emission shape needs exactly 2 points")
+		}
+		return nil
+	case ShapePolygon:
+		if len(s.Points) < 3 {
+			return errors.New("polygon emission shape needs at least 3 points")
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid emission shape kind %d", s.Kind)
+	}
+}
+
+// shapeSampler holds the precomputed emission shape data.
+type shapeSampler struct {
+	shape EmissionShape
+
+	// edgeLengths is a cumulative outline length for
+	// the polygon-like shapes (line, rect and polygon).
+	edgeLengths []float64
+	points      []gmath.Vec
+
+	// bounds is used for the polygon volume sampling.
+	bounds gmath.Rect
+
+	// orientation is 1 for the clockwise polygons (in screen coordinates)
+	// and -1 for the counter-clockwise ones.
+	orientation float64
+}
+
+func newShapeSampler(shape EmissionShape) *shapeSampler {
+	s := &shapeSampler{shape: shape, orientation: 1}
+
+	switch shape.Kind {
+	case ShapeRect:
+		half := shape.Size.Mulf(0.5)
+		s.points = []gmath.Vec{
+			{X: -half.X, Y: -half.Y},
+			{X: half.X, Y: -half.Y},
+			{X: half.X, Y: half.Y},
+			{X: -half.X, Y: half.Y},
+		}
+	case ShapeLine:
+		s.points = shape.Points
+	case ShapePolygon:
+		s.points = shape.Points
+		area := 0.0
+		s.bounds = gmath.Rect{Min: s.points[0], Max: s.points[0]}
+		for i, p := range s.points {
+			next := s.points[(i+1)%len(s.points)]
+			area += p.X*next.Y - next.X*p.Y
+			s.bounds.Min.X = min(s.bounds.Min.X, p.X)
+			s.bounds.Min.Y = min(s.bounds.Min.Y, p.Y)
+			s.bounds.Max.X = max(s.bounds.Max.X, p.X)
+			s.bounds.Max.Y = max(s.bounds.Max.Y, p.Y)
+		}
+		if area < 0 {
+			s.orientation = -1
+		}
+	}
+
+	if len(s.points) != 0 {
+		numEdges := len(s.points)
+		if shape.Kind == ShapeLine {
+			numEdges = 1
+		}
+		total := 0.0
+		s.edgeLengths = make([]float64, numEdges)
+		for i := range s.edgeLengths {
+			a := s.points[i]
+			b := s.points[(i+1)%len(s.points)]
+			total += a.DistanceTo(b)
+			s.edgeLengths[i] = total
+		}
+	}
+
+	return s
+}
+
+// sample returns a random point of the shape and the shape normal
+// angle at that point. The rand function should return values in [0, 1) range.
+func (s *shapeSampler) sample(rand func() float64) (gmath.Vec, gmath.Rad) {
+	shape := &s.shape
+
+	switch shape.Kind {
+	case ShapeCircle, ShapeArc:
+		angle := gmath.Rad(rand() * 2 * math.Pi)
+		if shape.Kind == ShapeArc {
+			angle = shape.ArcFrom + gmath.Rad(rand())*(shape.ArcTo-shape.ArcFrom)
+		}
+		r := shape.Radius
+		if !shape.Edge {
+			// The square root gives a uniform distribution over the area.
+			inner := shape.InnerRadius * shape.InnerRadius
+			outer := shape.Radius * shape.Radius
+			r = math.Sqrt(inner + rand()*(outer-inner))
+		}
+		return gmath.RadToVec(angle).Mulf(r), angle
+
+	case ShapeRect:
+		if shape.Edge {
+			return s.sampleEdge(rand())
+		}
+		pos := gmath.Vec{
+			X: (rand() - 0.5) * shape.Size.X,
+			Y: (rand() - 0.5) * shape.Size.Y,
+		}
+		return pos, pos.Angle()
+
+	case ShapeLine:
+		return s.sampleEdge(rand())
+
+	case ShapePolygon:
+		if shape.Edge {
+			return s.sampleEdge(rand())
+		}
+		// A rejection sampling: it works for the concave polygons too.
+		// The number of attempts is limited to avoid the
+		// unpredictable per-particle costs for the weird polygons.
+		size := s.bounds.Size()
+		for i := 0; i < 16; i++ {
+			pos := s.bounds.Min.Add(gmath.Vec{X: rand() * size.X, Y: rand() * size.Y})
+			if s.polygonContains(pos) {
+				return pos, pos.Angle()
+			}
+		}
+		return s.sampleEdge(rand())
+	}
+
+	return gmath.Vec{}, 0
+}
+
+// sampleEdge picks a point on the shape outline.
+// The edges are selected proportionally to their lengths.
+func (s *shapeSampler) sampleEdge(roll float64) (gmath.Vec, gmath.Rad) {
+	total := s.edgeLengths[len(s.edgeLengths)-1]
+	dist := roll * total
+	for i, length := range s.edgeLengths {
+		if dist > length && i != len(s.edgeLengths)-1 {
+			continue
+		}
+		a := s.points[i]
+		b := s.points[(i+1)%len(s.points)]
+		prev := 0.0
+		if i != 0 {
+			prev = s.edgeLengths[i-1]
+		}
+		t := 0.0
+		if length > prev {
+			t = (dist - prev) / (length - prev)
+		}
+		// For the clockwise polygons, the outward normal
+		// is the edge direction rotated by -90 degrees.
+		normal := b.Sub(a).Angle() - gmath.Rad(s.orientation*math.Pi/2)
+		return a.LinearInterpolate(b, t), normal
+	}
+	return gmath.Vec{}, 0
+}
+
+func (s *shapeSampler) polygonContains(pos gmath.Vec) bool {
+	inside := false
+	points := s.points
+	j := len(points) - 1
+	for i := range points {
+		a := points[i]
+		b := points[j]
+		if (a.Y > pos.Y) != (b.Y > pos.Y) {
+			x := a.X + (pos.Y-a.Y)*(b.X-a.X)/(b.Y-a.Y)
+			if pos.X < x {
+				inside = !inside
+			}
+		}
+		j = i
+	}
+	return inside
+}
+
+// SetEmissionShape assigns the area where the particles are spawned.
+// The default shape is a point (see [PointShape]).
+//
+// The shape offsets are combined with the spawn offset func results.
+//
+// It panics if the shape is invalid.
+func (tmpl *Template) SetEmissionShape(shape EmissionShape) {
+	if err := shape.validate(); err != nil {
+		panic(err.Error())
+	}
+	tmpl.emissionShape = shape
+	tmpl.shapeSampler = nil
+	tmpl.needsRandBits &^= shapeRandBit
+	if shape.Kind != ShapePoint {
+		tmpl.shapeSampler = newShapeSampler(shape)
+		tmpl.needsRandBits |= shapeRandBit
+	}
+}
+
+// GetEmissionShape returns the current emission shape.
+// Use SetEmissionShape to change it.
+func (tmpl *Template) GetEmissionShape() EmissionShape {
+	return tmpl.emissionShape
+}
+
+// SetEmitAlongNormal changes the EmitAlongNormal flag value.
+// Use IsEmitAlongNormal to get the current flag value.
+//
+// When this flag is set, the particle direction is relative to
+// the emission shape normal at the spawn point.
+// So a direction of 0 means "move along the normal"
+// (away from the circle center, outward the rect edge, and so on).
+//
+// The line shape normal points to the left of the a->b direction,
+// which is "up" for a left-to-right segment.
+func (tmpl *Template) SetEmitAlongNormal(enabled bool) {
+	tmpl.emitAlongNormal = enabled
+}
+
+// IsEmitAlongNormal reports whether the particle direction follows the shape normal.
+// Use SetEmitAlongNormal to change this flag value.
+func (tmpl *Template) IsEmitAlongNormal() bool {
+	return tmpl.emitAlongNormal
+}
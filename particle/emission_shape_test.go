@@ -0,0 +1,255 @@
+package particle
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/quasilyte/gmath"
+)
+
+// lShape is a concave polygon listed clockwise (in screen coordinates):
+//
+//	+----+
+//	|    |
+//	|    +----+
+//	|         |
+//	+---------+
+var lShape = []gmath.Vec{
+	{X: 0, Y: 0},
+	{X: 10, Y: 0},
+	{X: 10, Y: 10},
+	{X: 20, Y: 10},
+	{X: 20, Y: 20},
+	{X: 0, Y: 20},
+}
+
+func reversedPoints(points []gmath.Vec) []gmath.Vec {
+	result := slices.Clone(points)
+	slices.Reverse(result)
+	return result
+}
+
+// edgeMidpointRolls returns the sampleEdge rolls that select the edge midpoints.
+func edgeMidpointRolls(s *shapeSampler) []float64 {
+	total := s.edgeLengths[len(s.edgeLengths)-1]
+	rolls := make([]float64, len(s.edgeLengths))
+	prev := 0.0
+	for i, length := range s.edgeLengths {
+		rolls[i] = (prev + (length-prev)*0.5) / total
+		prev = length
+	}
+	return rolls
+}
+
+func TestShapeSamplerOrientation(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []gmath.Vec
+		want   float64
+	}{
+		{"clockwise triangle", []gmath.Vec{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 0, Y: 10}}, 1},
+		{"counter-clockwise triangle", []gmath.Vec{{X: 0, Y: 0}, {X: 0, Y: 10}, {X: 10, Y: 0}}, -1},
+		{"clockwise concave", lShape, 1},
+		{"counter-clockwise concave", reversedPoints(lShape), -1},
+	}
+
+	for _, test := range tests {
+		s := newShapeSampler(PolygonShape(test.points, true))
+		if s.orientation != test.want {
+			t.Errorf("%s: have %v orientation, want %v", test.name, s.orientation, test.want)
+		}
+	}
+}
+
+func TestShapeSamplerEdgeNormals(t *testing.T) {
+	tests := []struct {
+		name  string
+		shape EmissionShape
+	}{
+		{"clockwise polygon", PolygonShape(lShape, true)},
+		{"counter-clockwise polygon", PolygonShape(reversedPoints(lShape), true)},
+		{"rect", RectShape(gmath.Vec{X: 20, Y: 10}, true)},
+	}
+
+	const eps = 0.1
+	for _, test := range tests {
+		s := newShapeSampler(test.shape)
+		for i, roll := range edgeMidpointRolls(s) {
+			pos, normal := s.sampleEdge(roll)
+			dir := gmath.RadToVec(normal)
+			if s.polygonContains(pos.Add(dir.Mulf(eps))) {
+				t.Errorf("%s: edge %d at %v: the normal %v points inwards", test.name, i, pos, dir)
+			}
+			if !s.polygonContains(pos.Sub(dir.Mulf(eps))) {
+				t.Errorf("%s: edge %d at %v: the normal %v is not outward", test.name, i, pos, dir)
+			}
+		}
+	}
+}
+
+func TestShapeSamplerLineNormal(t *testing.T) {
+	a := gmath.Vec{X: 5, Y: 5}
+	b := gmath.Vec{X: 25, Y: 15}
+	s := newShapeSampler(LineShape(a, b))
+	if len(s.edgeLengths) != 1 {
+		t.Fatalf("a line has %d edges, want 1", len(s.edgeLengths))
+	}
+
+	lineDir := a.DirectionTo(b)
+	for _, roll := range []float64{0, 0.25, 0.5, 0.99} {
+		pos, normal := s.sampleEdge(roll)
+		want := a.LinearInterpolate(b, roll)
+		if pos.DistanceTo(want) > 1e-9 {
+			t.Fatalf("roll=%v: have %v pos, want %v", roll, pos, want)
+		}
+		// The normal is perpendicular to the line, it's the
+		// edge direction rotated by -90 degrees (like for the clockwise polygons).
+		dir := gmath.RadToVec(normal)
+		if dot := dir.X*lineDir.X + dir.Y*lineDir.Y; math.Abs(dot) > 1e-9 {
+			t.Fatalf("roll=%v: the normal %v is not perpendicular to the line", roll, dir)
+		}
+		wantNormal := lineDir.Rotated(-math.Pi / 2)
+		if dir.DistanceTo(wantNormal) > 1e-9 {
+			t.Fatalf("roll=%v: have %v normal, want %v", roll, dir, wantNormal)
+		}
+	}
+}
+
+func TestShapeSamplerPolygonContains(t *testing.T) {
+	tests := []struct {
+		pos  gmath.Vec
+		want bool
+	}{
+		{gmath.Vec{X: 5, Y: 5}, true},
+		{gmath.Vec{X: 5, Y: 15}, true},
+		{gmath.Vec{X: 15, Y: 15}, true},
+		{gmath.Vec{X: 19.5, Y: 19.5}, true},
+
+		// The notch of the concave polygon.
+		{gmath.Vec{X: 15, Y: 5}, false},
+		{gmath.Vec{X: 19.5, Y: 0.5}, false},
+
+		{gmath.Vec{X: -1, Y: 5}, false},
+		{gmath.Vec{X: 5, Y: -1}, false},
+		{gmath.Vec{X: 21, Y: 15}, false},
+		{gmath.Vec{X: 15, Y: 21}, false},
+	}
+
+	for _, points := range [][]gmath.Vec{lShape, reversedPoints(lShape)} {
+		s := newShapeSampler(PolygonShape(points, false))
+		for _, test := range tests {
+			if have := s.polygonContains(test.pos); have != test.want {
+				t.Errorf("contains(%v): have %v, want %v", test.pos, have, test.want)
+			}
+		}
+	}
+}
+
+func TestShapeSamplerSample(t *testing.T) {
+	var seq uint64
+	rand := func() float64 {
+		seq++
+		return fastrandFloat(0xc0ffee, seq)
+	}
+
+	tests := []struct {
+		name  string
+		shape EmissionShape
+		check func(pos gmath.Vec, normal gmath.Rad) bool
+	}{
+		{
+			name:  "circle",
+			shape: CircleShape(10, false),
+			check: func(pos gmath.Vec, normal gmath.Rad) bool {
+				return pos.Len() <= 10
+			},
+		},
+		{
+			name:  "circle edge",
+			shape: CircleShape(10, true),
+			check: func(pos gmath.Vec, normal gmath.Rad) bool {
+				return math.Abs(pos.Len()-10) < 1e-9 && gmath.RadToVec(normal).Mulf(10).DistanceTo(pos) < 1e-9
+			},
+		},
+		{
+			name:  "ring",
+			shape: RingShape(5, 10),
+			check: func(pos gmath.Vec, normal gmath.Rad) bool {
+				return pos.Len() >= 5-1e-9 && pos.Len() <= 10
+			},
+		},
+		{
+			name:  "arc",
+			shape: ArcShape(10, 0, math.Pi/2, true),
+			check: func(pos gmath.Vec, normal gmath.Rad) bool {
+				return normal >= 0 && normal <= math.Pi/2 && pos.X >= -1e-9 && pos.Y >= -1e-9
+			},
+		},
+		{
+			name:  "rect",
+			shape: RectShape(gmath.Vec{X: 20, Y: 10}, false),
+			check: func(pos gmath.Vec, normal gmath.Rad) bool {
+				return math.Abs(pos.X) <= 10 && math.Abs(pos.Y) <= 5
+			},
+		},
+		{
+			name:  "concave polygon",
+			shape: PolygonShape(lShape, false),
+			check: func(pos gmath.Vec, normal gmath.Rad) bool {
+				// The fallback edge points are on the outline, so the
+				// notch interior check is enough.
+				inNotch := pos.X > 10 && pos.Y < 10
+				return !inNotch && pos.X >= 0 && pos.X <= 20 && pos.Y >= 0 && pos.Y <= 20
+			},
+		},
+	}
+
+	for _, test := range tests {
+		s := newShapeSampler(test.shape)
+		for i := 0; i < 200; i++ {
+			pos, normal := s.sample(rand)
+			if !test.check(pos, normal) {
+				t.Fatalf("%s: invalid sample pos=%v normal=%v", test.name, pos, normal)
+			}
+		}
+	}
+}
+
+func TestEmissionShapeValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		shape EmissionShape
+		valid bool
+	}{
+		{"point", PointShape(), true},
+		{"circle", CircleShape(10, false), true},
+		{"zero circle", CircleShape(0, true), true},
+		{"ring", RingShape(5, 10), true},
+		{"arc", ArcShape(10, -1, 1, false), true},
+		{"rect", RectShape(gmath.Vec{X: 10, Y: 0}, true), true},
+		{"line", LineShape(gmath.Vec{}, gmath.Vec{X: 10}), true},
+		{"triangle", PolygonShape(lShape[:3], false), true},
+
+		{"negative radius", CircleShape(-1, false), false},
+		{"negative inner radius", EmissionShape{Kind: ShapeCircle, Radius: 10, InnerRadius: -1}, false},
+		{"inner radius too big", RingShape(10, 5), false},
+		{"inverted arc", ArcShape(10, 1, -1, false), false},
+		{"arc inner radius too big", EmissionShape{Kind: ShapeArc, Radius: 5, InnerRadius: 10}, false},
+		{"negative rect size", RectShape(gmath.Vec{X: 10, Y: -1}, false), false},
+		{"line with 1 point", EmissionShape{Kind: ShapeLine, Points: lShape[:1]}, false},
+		{"line with 3 points", EmissionShape{Kind: ShapeLine, Points: lShape[:3]}, false},
+		{"polygon with 2 points", PolygonShape(lShape[:2], false), false},
+		{"invalid kind", EmissionShape{Kind: ShapePolygon + 1}, false},
+	}
+
+	for _, test := range tests {
+		err := test.shape.validate()
+		if test.valid && err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+}
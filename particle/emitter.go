@@ -12,6 +12,15 @@ type Emitter struct {
 
 	particles []particle
 
+	// states is only used for the integrated physics mode.
+	// It has the same length as particles.
+	states []particleState
+
+	// subEmitters are created for the template sub-emitters.
+	subEmitters []subEmitterInstance
+
+	hasContinuousSubEmitters bool
+
 	Pos gmath.Pos
 
 	PivotOffset gmath.Vec
@@ -26,12 +35,24 @@ type Emitter struct {
 
 	lifetimeMultiplier float32
 
+	// maxParticles is a max number of alive particles.
+	// Zero means "unlimited".
+	maxParticles int
+
 	// emitDelay is a time (in seconds) until the next emission step.
 	emitDelay float32
 
+	lifecycle emitterLifecycle
+
 	idSeq      uint32
 	generation uint16
 
+	// seed is an initial randState value.
+	// The emitters without a seed use a global random source.
+	seed      uint64
+	randState uint64
+	seeded    bool
+
 	emitting bool
 	visible  bool
 	disposed bool
@@ -47,7 +68,11 @@ type particle struct {
 	origAngle    uint8
 	paletteIndex uint8
 	userData     uint8
-	_            [2]uint8 // unused (reserved for future use)
+	turnRateSeed uint8
+
+	// imageSeed is used for both the animation start frame and the spin,
+	// see imageSpinSeed for the details.
+	imageSeed uint8
 
 	// Would use {uint16, uint16} here to save 4 bytes,
 	// but it can be desirable to support negative coords
@@ -65,6 +90,7 @@ func NewEmitter(tmpl *Template) *Emitter {
 		lifetimeMultiplier: 1,
 		visible:            true,
 	}
+	e.initSubEmitters()
 	return e
 }
 
@@ -74,9 +100,27 @@ func (e *Emitter) IsDisposed() bool {
 
 func (e *Emitter) Dispose() {
 	e.disposed = true
+	for _, sub := range e.subEmitters {
+		sub.emitter.Dispose()
+	}
 }
 
-func (e *Emitter) SetVisibility(visible bool) { e.visible = visible }
+func (e *Emitter) SetVisibility(visible bool) {
+	e.visible = visible
+	for _, sub := range e.subEmitters {
+		sub.emitter.SetVisibility(visible)
+	}
+}
+
+// SetMaxParticles limits the number of the alive emitter particles.
+// When this limit is reached, the new particles are not emitted.
+// A zero value means "unlimited" (this is a default).
+func (e *Emitter) SetMaxParticles(n int) {
+	if n < 0 {
+		panic("max particles can't be negative")
+	}
+	e.maxParticles = n
+}
 
 func (e *Emitter) SetLifetimeMultiplier(multiplier float64) {
 	e.lifetimeMultiplier = float32(multiplier)
@@ -89,6 +133,74 @@ func (e *Emitter) SetEmitting(emitting bool) {
 
 	e.emitDelay = 0
 	e.emitting = emitting
+	if emitting {
+		e.start()
+	}
+}
+
+// SetSeed makes the emitter use its own random source initialized with the seed.
+// Two emitters with the same seed, template and a sequence of updates
+// produce identical particles, so the effects can be reproduced
+// (this is important for the replays and lockstep multiplayer games).
+//
+// The sub-emitters get their own seeds derived from this seed.
+//
+// Without a seed, the emitter uses a global random source
+// shared between all emitters; its results depend on
+// the order of all emitter updates.
+//
+// The seed is applied to the next emitted particles.
+// Use [Emitter.Reset] to start over with the same seed.
+func (e *Emitter) SetSeed(seed uint64) {
+	e.seed = seed
+	e.randState = seed
+	e.seeded = true
+	for i, sub := range e.subEmitters {
+		childSeed := seed + uint64(i) + 1
+		sub.emitter.SetSeed(splitmix64(&childSeed))
+	}
+}
+
+// Reset removes all particles and returns the emitter into its initial state.
+// The emission is stopped, use [Emitter.Restart] to reset and start emitting.
+//
+// For the seeded emitters, the random source is reset too,
+// so the emitter produces the same particles again.
+//
+// The emitter settings (like Pos or the max particles limit) are not affected.
+func (e *Emitter) Reset() {
+	e.particles = e.particles[:0]
+	e.states = e.states[:0]
+	e.dtError = 0
+	e.emitDelay = 0
+	e.idSeq = 0
+	e.generation = 0
+	e.randState = e.seed
+	e.emitting = false
+	e.lifecycle.elapsed = 0
+	e.lifecycle.delayLeft = 0
+	e.lifecycle.finished = false
+	for _, sub := range e.subEmitters {
+		sub.emitter.Reset()
+	}
+}
+
+// Restart is like [Emitter.Reset], but it also enables the emission.
+func (e *Emitter) Restart() {
+	e.Reset()
+	e.SetEmitting(true)
+}
+
+func (e *Emitter) nextRandBits() uint64 {
+	if e.seeded {
+		return splitmix64(&e.randState)
+	}
+	return cache.Global.Rand.Uint64()
+}
+
+// contextSeed is used by the spawn context random functions.
+func (e *Emitter) contextSeed() uint64 {
+	return randseed1 ^ e.seed
 }
 
 func (e *Emitter) NumParticles() int {
@@ -100,20 +212,27 @@ func (e *Emitter) Update() {
 }
 
 func (e *Emitter) UpdateWithDelta(delta float64) {
-	if e.emitting {
-		// With a very low emit interval, it's possible to have
-		// delta > emitInterval.
-		// If delta is 0.1 and emitInterval is 0.04, then we need
-		// to emit twice and have the delay set to 0.02.
-		// We should also create the second particles emitted
-		// with t=0.04 instead of t=0.00.
-		e.emitDelay -= float32(delta)
-		t := float32(0.0)
-		for e.emitDelay < 0 {
-			e.emit(t)
-			t += e.tmpl.emitInterval
-			e.emitDelay += e.tmpl.emitInterval
+	// The sub-emitters are updated first, so the particles
+	// spawned during this update are not advanced twice.
+	for _, sub := range e.subEmitters {
+		sub.emitter.UpdateWithDelta(delta)
+	}
+
+	// The template physics mode could be changed after
+	// some particles were already emitted.
+	if e.tmpl.physics == physicsIntegrated {
+		if len(e.states) != len(e.particles) {
+			e.states = e.states[:0]
+			for i := range e.particles {
+				e.states = append(e.states, e.tmpl.initialState(&e.particles[i]))
+			}
 		}
+	} else {
+		e.states = nil
+	}
+
+	if e.emitting {
+		e.updateEmission(float32(delta))
 	}
 
 	deltaMS := delta * 1000
@@ -128,28 +247,70 @@ func (e *Emitter) UpdateWithDelta(delta float64) {
 	_, fract := math.Modf(deltaMS)
 	e.dtError += fract
 
+	if e.states != nil {
+		e.integrate(float32(delta))
+	}
+
 	live := e.particles[:0]
 	dt := uint16(deltaMS)
-	for _, p := range e.particles {
+	hasSubEmitters := len(e.subEmitters) != 0
+	for i, p := range e.particles {
+		prevCounter := p.counter
 		p.counter += dt
 		if p.counter > p.lifetime {
+			if hasSubEmitters {
+				t := float32(p.lifetime) * 0.001
+				overshoot := float32(p.counter-p.lifetime) * 0.001
+				e.triggerSubEmitters(SubEmitOnDeath, &p, i, t, overshoot)
+			}
 			continue
 		}
+		if e.hasContinuousSubEmitters {
+			e.updateContinuousSubEmitters(&p, i, prevCounter)
+		}
+		if e.states != nil {
+			e.states[len(live)] = e.states[i]
+		}
 		live = append(live, p)
 	}
 	e.particles = live
-}
+	if e.states != nil {
+		e.states = e.states[:len(live)]
+	}
 
-func (e *Emitter) emit(t float32) {
-	tmpl := e.tmpl
-	e.generation++
+	if e.lifecycle.autoDispose && e.IsFinished() {
+		e.Dispose()
+	}
+}
 
-	w, h := float64(e.tmpl.img.Bounds().Dx()), float64(e.tmpl.img.Bounds().Dy())
-	pos := e.Pos.Resolve().Sub(gmath.Vec{X: w * 0.5, Y: h * 0.5})
+// spawnOrigin returns the emitter position adjusted by its pivot.
+// Since particle positions are their image top-left corners,
+// the result is shifted by the half of the image size.
+func (e *Emitter) spawnOrigin() gmath.Vec {
+	pos := e.centeredOrigin(e.Pos.Resolve())
 	if !e.PivotOffset.IsZero() {
 		offset := rotatedVec(e.PivotOffset, e.Rotation)
 		pos = pos.Add(offset)
 	}
+	return pos
+}
+
+// centeredOrigin converts the particle center position
+// into the particle image top-left corner.
+func (e *Emitter) centeredOrigin(center gmath.Vec) gmath.Vec {
+	w, h := e.tmpl.frameSize()
+	return center.Sub(gmath.Vec{X: float64(w) * 0.5, Y: float64(h) * 0.5})
+}
+
+func (e *Emitter) emit(t float32) {
+	e.emitFrom(e.simulationOrigin(), e.simulationRotation(), t)
+}
+
+// emitFrom emits a particles burst at pos.
+// The rotation affects the particle directions and spawn offsets.
+func (e *Emitter) emitFrom(pos gmath.Vec, rotation *gmath.Rad, t float32) {
+	tmpl := e.tmpl
+	e.generation++
 
 	// Compute up to 64 random bits only once.
 	// Then use the fastrand to generate more.
@@ -157,7 +318,7 @@ func (e *Emitter) emit(t float32) {
 	randBits := uint64(0)
 	randSeq := uint64(0)
 	if e.tmpl.needsRandBits != 0 {
-		randBits = cache.Global.Rand.Uint64()
+		randBits = e.nextRandBits()
 	}
 
 	numParticles := 1
@@ -168,7 +329,22 @@ func (e *Emitter) emit(t float32) {
 		numParticles = int(e.tmpl.minEmitBurst + uint8(x*e.tmpl.emitBurstRangeSize/256))
 	}
 
+	// The shape sampling may need an arbitrary amount of random values.
+	shapeRand := func() float64 {
+		v := fastrandFloat(randBits, randSeq)
+		randSeq++
+		return v
+	}
+
+	var emitterAngle gmath.Rad
+	if rotation != nil {
+		emitterAngle = *rotation
+	}
+
 	ctx := SpawnContext{emitter: e}
+	if e.maxParticles != 0 {
+		numParticles = min(numParticles, e.maxParticles-len(e.particles))
+	}
 	for i := 0; i < numParticles; i++ {
 		ctx.id = e.idSeq
 		e.idSeq++
@@ -178,9 +354,17 @@ func (e *Emitter) emit(t float32) {
 			ctx.userData = tmpl.spawnUserDataFunc(ctx)
 		}
 		if tmpl.spawnOffsetFunc != nil {
-			offset := rotatedVec(tmpl.spawnOffsetFunc(ctx), e.Rotation)
+			offset := rotatedVec(tmpl.spawnOffsetFunc(ctx), rotation)
 			particlePos = particlePos.Add(offset)
 		}
+		angle := emitterAngle
+		if tmpl.shapeSampler != nil {
+			offset, normal := tmpl.shapeSampler.sample(shapeRand)
+			particlePos = particlePos.Add(rotatedVec(offset, rotation))
+			if tmpl.emitAlongNormal {
+				angle += normal
+			}
+		}
 
 		paletteIndex := uint8(0)
 		if tmpl.spawnColorFunc != nil {
@@ -208,8 +392,8 @@ func (e *Emitter) emit(t float32) {
 		}
 
 		origAngle := uint8(0)
-		if e.Rotation != nil {
-			origAngle = uint8((e.Rotation.Normalized() / (2 * math.Pi)) * 256)
+		if angle != 0 {
+			origAngle = uint8((angle.Normalized() / (2 * math.Pi)) * 256)
 		}
 
 		p := particle{
@@ -234,6 +418,26 @@ func (e *Emitter) emit(t float32) {
 			p.angleSeed = x
 		}
 
+		if e.tmpl.needsRandBits&turnRateRandBit != 0 {
+			x := uint8(fastrand(randBits, randSeq))
+			randSeq++
+			p.turnRateSeed = x
+		}
+
+		if e.tmpl.needsRandBits&(frameRandBit|spinRandBit) != 0 {
+			x := uint8(fastrand(randBits, randSeq))
+			randSeq++
+			p.imageSeed = x
+		}
+
 		e.particles = append(e.particles, p)
+
+		if tmpl.physics == physicsIntegrated {
+			e.states = append(e.states, tmpl.initialState(&p))
+		}
+
+		if len(e.subEmitters) != 0 {
+			e.triggerSubEmitters(SubEmitOnBirth, &p, len(e.particles)-1, t, t)
+		}
 	}
 }
@@ -0,0 +1,180 @@
+package particle
+
+// prewarmStep is a fixed delta used to simulate the prewarm time.
+const prewarmStep = 1.0 / 60.0
+
+// emitterLifecycle holds the emission timing settings and state.
+type emitterLifecycle struct {
+	// duration is an emission time limit (in seconds).
+	// Zero means "emit until stopped".
+	duration float32
+	elapsed  float32
+
+	startDelay float32
+	delayLeft  float32
+
+	prewarm float32
+
+	oneShot     bool
+	autoDispose bool
+
+	// finished is set when the emission is stopped
+	// due to the duration limit or a one-shot burst.
+	finished bool
+}
+
+// SetDuration limits the emission time (in seconds).
+// When it's over, the emitter stops emitting on its own.
+// The start delay is not included in the duration.
+// A zero duration means "emit until stopped" (this is a default).
+func (e *Emitter) SetDuration(seconds float64) {
+	if seconds < 0 {
+		panic("duration can't be negative")
+	}
+	e.lifecycle.duration = float32(seconds)
+}
+
+// SetOneShot makes the emitter emit a single burst and stop.
+// It's useful for the explosion-like effects.
+// The burst size is controlled by the [Template.SetEmitBurst].
+func (e *Emitter) SetOneShot(oneShot bool) {
+	e.lifecycle.oneShot = oneShot
+}
+
+// SetStartDelay assigns a time (in seconds) between the emission
+// start and the first emitted particles.
+func (e *Emitter) SetStartDelay(seconds float64) {
+	if seconds < 0 {
+		panic("start delay can't be negative")
+	}
+	e.lifecycle.startDelay = float32(seconds)
+}
+
+// SetPrewarm makes the emitter simulate the specified amount
+// of time (in seconds) when it starts emitting.
+// This way a smoke column can be fully visible right away.
+//
+// The prewarm is simulated as if the start delay has already passed.
+// It's simulated using the fixed 1/60 steps, so a long prewarm
+// for a dense emitter can be quite expensive.
+//
+// The prewarm time is not counted towards the duration (see [Emitter.SetDuration]),
+// so the emitter can't be finished or auto-disposed right after the start.
+// The one-shot emitters are not prewarmed.
+func (e *Emitter) SetPrewarm(seconds float64) {
+	if seconds < 0 {
+		panic("prewarm can't be negative")
+	}
+	e.lifecycle.prewarm = float32(seconds)
+}
+
+// SetAutoDispose makes the emitter dispose itself when its emission
+// is finished (see [Emitter.IsFinished]).
+// A disposed emitter is removed from the [Renderer] automatically,
+// so the fire-and-forget effects don't need any extra bookkeeping.
+func (e *Emitter) SetAutoDispose(autoDispose bool) {
+	e.lifecycle.autoDispose = autoDispose
+}
+
+// IsEmitting reports whether the emitter is currently emitting.
+// It's true during the start delay too.
+func (e *Emitter) IsEmitting() bool {
+	return e.emitting
+}
+
+// IsFinished reports whether the emission finished on its own
+// (due to the duration limit or a one-shot burst) and all
+// particles (including the sub-emitter particles) are expired.
+//
+// An emitter stopped with SetEmitting(false) is never finished.
+func (e *Emitter) IsFinished() bool {
+	return e.lifecycle.finished && !e.hasParticles()
+}
+
+func (e *Emitter) hasParticles() bool {
+	if len(e.particles) != 0 {
+		return true
+	}
+	for _, sub := range e.subEmitters {
+		if sub.emitter.hasParticles() {
+			return true
+		}
+	}
+	return false
+}
+
+// start is called when the emitter starts emitting.
+func (e *Emitter) start() {
+	l := &e.lifecycle
+	l.elapsed = 0
+	l.delayLeft = 0
+	l.finished = false
+
+	if l.prewarm > 0 && !l.oneShot {
+		// The prewarm simulates the emission that happened before the start,
+		// so it doesn't count towards the duration and it can't
+		// finish (and auto-dispose) the emitter.
+		duration, autoDispose := l.duration, l.autoDispose
+		l.duration = 0
+		l.autoDispose = false
+		for t := float32(0); t < l.prewarm; t += prewarmStep {
+			e.UpdateWithDelta(prewarmStep)
+		}
+		l.duration = duration
+		l.autoDispose = autoDispose
+		l.elapsed = 0
+	}
+
+	if e.emitting {
+		l.delayLeft = l.startDelay
+	}
+}
+
+func (e *Emitter) stopEmission() {
+	e.emitting = false
+	e.lifecycle.finished = true
+}
+
+// updateEmission emits the particles for the elapsed delta time.
+func (e *Emitter) updateEmission(delta float32) {
+	l := &e.lifecycle
+
+	if l.delayLeft > 0 {
+		l.delayLeft -= delta
+		if l.delayLeft > 0 {
+			return
+		}
+		// Use the remaining part of the delta.
+		delta = -l.delayLeft
+		l.delayLeft = 0
+	}
+
+	if l.oneShot {
+		e.emit(0)
+		e.stopEmission()
+		return
+	}
+
+	if l.duration > 0 {
+		delta = min(delta, l.duration-l.elapsed)
+	}
+	l.elapsed += delta
+
+	// With a very low emit interval, it's possible to have
+	// delta > emitInterval.
+	// If delta is 0.1 and emitInterval is 0.04, then we need
+	// to emit twice and have the delay set to 0.02.
+	// We should also create the second particles emitted
+	// with t=0.04 instead of t=0.00.
+	e.emitDelay -= delta
+	t := float32(0.0)
+	for e.emitDelay < 0 {
+		e.emit(t)
+		t += e.tmpl.emitInterval
+		e.emitDelay += e.tmpl.emitInterval
+	}
+
+	if l.duration > 0 && l.elapsed >= l.duration {
+		e.stopEmission()
+	}
+}
@@ -0,0 +1,316 @@
+package particle
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"unsafe"
+
+	"github.com/quasilyte/gmath"
+)
+
+func TestParticleSize(t *testing.T) {
+	if unsafe.Sizeof(int(0)) != 8 {
+		t.Skip("this test is only executed on 64-bit platforms")
+	}
+
+	// An emitter can have thousands of live particles,
+	// they're copied around during the simulation.
+	// Every new per-particle field should fit into the existing padding.
+	wantSize := uintptr(20)
+	haveSize := unsafe.Sizeof(particle{})
+	if wantSize != haveSize {
+		t.Fatalf("sizeof(particle):\nhave: %d\nwant: %d", haveSize, wantSize)
+	}
+}
+
+func TestImageSpinSeed(t *testing.T) {
+	var seen [256]bool
+	for i := 0; i < 256; i++ {
+		x := imageSpinSeed(uint8(i))
+		if seen[x] {
+			t.Fatalf("seed %d maps to an already used spin seed %d", i, x)
+		}
+		seen[x] = true
+	}
+}
+
+func newRandomizedTemplate() *Template {
+	tmpl := NewTemplate()
+	tmpl.SetParticleLifetimeRange(0.5, 1.5)
+	tmpl.SetParticleSpeedRange(10, 80)
+	tmpl.SetParticleScalingRange(gmath.Vec{X: 0.5, Y: 0.5}, gmath.Vec{X: 2, Y: 2})
+	tmpl.SetParticleDirection(0, 2*math.Pi)
+	tmpl.SetEmitBurst(1, 6)
+	tmpl.SetEmitInterval(0.05)
+	tmpl.SetParticleTurnRateRange(-1, 1)
+	tmpl.SetEmissionShape(CircleShape(16, false))
+	return tmpl
+}
+
+func runEmitter(e *Emitter, deltas []float64) []particle {
+	e.SetEmitting(true)
+	for _, delta := range deltas {
+		e.UpdateWithDelta(delta)
+	}
+	return slices.Clone(e.particles)
+}
+
+func testDeltas() []float64 {
+	deltas := make([]float64, 0, 180)
+	for i := 0; i < cap(deltas); i++ {
+		// A non-uniform delta sequence, like in a real game.
+		deltas = append(deltas, 1.0/60.0+float64(i%7)*0.001)
+	}
+	return deltas
+}
+
+func TestEmitterDeterminism(t *testing.T) {
+	tests := []struct {
+		name string
+		tmpl func() *Template
+	}{
+		{
+			name: "default",
+			tmpl: NewTemplate,
+		},
+		{
+			name: "randomized",
+			tmpl: newRandomizedTemplate,
+		},
+		{
+			name: "integrated",
+			tmpl: func() *Template {
+				tmpl := newRandomizedTemplate()
+				tmpl.SetRadialAcceleration(20)
+				tmpl.SetTangentialAcceleration(-15)
+				return tmpl
+			},
+		},
+		{
+			name: "sub-emitters",
+			tmpl: func() *Template {
+				tmpl := newRandomizedTemplate()
+				tmpl.AddSubEmitter(SubEmitter{
+					Template: newRandomizedTemplate(),
+					Trigger:  SubEmitOnDeath,
+				})
+				tmpl.AddSubEmitter(SubEmitter{
+					Template: newRandomizedTemplate(),
+					Trigger:  SubEmitWhileAlive,
+					Interval: 0.1,
+				})
+				return tmpl
+			},
+		},
+	}
+
+	deltas := testDeltas()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tmpl := test.tmpl()
+
+			e1 := NewEmitter(tmpl)
+			e1.SetSeed(1234)
+			e2 := NewEmitter(tmpl)
+			e2.SetSeed(1234)
+
+			// An unseeded emitter consumes the global random
+			// source values; it should not affect the seeded emitters.
+			noise := NewEmitter(tmpl)
+			noise.SetEmitting(true)
+			noise.Update()
+
+			have1 := runEmitter(e1, deltas)
+			noise.Update()
+			have2 := runEmitter(e2, deltas)
+
+			if len(have1) == 0 {
+				t.Fatal("no particles emitted")
+			}
+			if !slices.Equal(have1, have2) {
+				t.Fatal("emitters with the same seed produced different particles")
+			}
+			for i := range e1.subEmitters {
+				sub1 := e1.subEmitters[i].emitter.particles
+				sub2 := e2.subEmitters[i].emitter.particles
+				if !slices.Equal(sub1, sub2) {
+					t.Fatalf("sub-emitter[%d] particles mismatch", i)
+				}
+			}
+			if !slices.Equal(e1.states, e2.states) {
+				t.Fatal("emitters with the same seed produced different states")
+			}
+		})
+	}
+}
+
+func TestEmitterDifferentSeeds(t *testing.T) {
+	tmpl := newRandomizedTemplate()
+	deltas := testDeltas()
+
+	e1 := NewEmitter(tmpl)
+	e1.SetSeed(1)
+	e2 := NewEmitter(tmpl)
+	e2.SetSeed(2)
+
+	if slices.Equal(runEmitter(e1, deltas), runEmitter(e2, deltas)) {
+		t.Fatal("emitters with different seeds produced identical particles")
+	}
+}
+
+func TestEmitterRestart(t *testing.T) {
+	tmpl := newRandomizedTemplate()
+	deltas := testDeltas()
+
+	e := NewEmitter(tmpl)
+	e.SetSeed(99)
+	have1 := runEmitter(e, deltas)
+
+	e.Reset()
+	if e.NumParticles() != 0 {
+		t.Fatalf("reset emitter has %d particles", e.NumParticles())
+	}
+	if e.emitting {
+		t.Fatal("reset emitter is still emitting")
+	}
+
+	e.Restart()
+	have2 := runEmitter(e, deltas)
+	if !slices.Equal(have1, have2) {
+		t.Fatal("restarted emitter produced different particles")
+	}
+}
+
+func TestSpawnContextRandSeeded(t *testing.T) {
+	tmpl := newRandomizedTemplate()
+	var rolls []float64
+	tmpl.SetSpawnOffsetFunc(func(ctx SpawnContext) gmath.Vec {
+		rolls = append(rolls, ctx.Rand())
+		return gmath.Vec{}
+	})
+
+	e1 := NewEmitter(tmpl)
+	e1.SetSeed(7)
+	runEmitter(e1, testDeltas())
+	rolls1 := rolls
+
+	rolls = nil
+	e2 := NewEmitter(tmpl)
+	e2.SetSeed(8)
+	runEmitter(e2, testDeltas())
+	rolls2 := rolls
+
+	if slices.Equal(rolls1, rolls2) {
+		t.Fatal("spawn context random values don't depend on the seed")
+	}
+}
+
+func TestEmitterLifecycle(t *testing.T) {
+	t.Run("one-shot", func(t *testing.T) {
+		tmpl := NewTemplate()
+		tmpl.SetEmitBurst(5, 5)
+		tmpl.SetParticleLifetime(0.5)
+
+		e := NewEmitter(tmpl)
+		e.SetOneShot(true)
+		e.SetAutoDispose(true)
+		e.SetEmitting(true)
+		e.Update()
+		if e.NumParticles() != 5 || e.IsEmitting() {
+			t.Fatalf("one-shot: have %d particles, emitting=%v", e.NumParticles(), e.IsEmitting())
+		}
+		for i := 0; i < 60 && !e.IsDisposed(); i++ {
+			e.Update()
+		}
+		if !e.IsDisposed() {
+			t.Fatal("finished emitter is not disposed")
+		}
+	})
+
+	t.Run("duration", func(t *testing.T) {
+		tmpl := NewTemplate()
+		tmpl.SetEmitInterval(0.1)
+
+		e := NewEmitter(tmpl)
+		e.SetStartDelay(0.5)
+		e.SetDuration(1)
+		e.SetEmitting(true)
+		for i := 0; i < 29; i++ {
+			e.Update()
+		}
+		if e.NumParticles() != 0 {
+			t.Fatalf("particles emitted during the start delay")
+		}
+		for i := 0; i < 120; i++ {
+			e.Update()
+		}
+		if e.IsEmitting() {
+			t.Fatal("emitter is still emitting after its duration")
+		}
+		if e.IsFinished() {
+			t.Fatal("emitter is finished while having alive particles")
+		}
+	})
+
+	t.Run("prewarm", func(t *testing.T) {
+		e := NewEmitter(NewTemplate())
+		e.SetPrewarm(2)
+		e.SetEmitting(true)
+		if e.NumParticles() < 3 {
+			t.Fatalf("prewarmed emitter has only %d particles", e.NumParticles())
+		}
+	})
+
+	t.Run("prewarm duration", func(t *testing.T) {
+		tmpl := NewTemplate()
+		tmpl.SetEmitInterval(0.1)
+
+		e := NewEmitter(tmpl)
+		e.SetDuration(1)
+		e.SetPrewarm(2)
+		e.SetAutoDispose(true)
+		e.SetEmitting(true)
+		if !e.IsEmitting() || e.IsFinished() || e.IsDisposed() {
+			t.Fatalf("prewarm finished the emitter: emitting=%v finished=%v disposed=%v",
+				e.IsEmitting(), e.IsFinished(), e.IsDisposed())
+		}
+		if e.NumParticles() == 0 {
+			t.Fatal("the emitter is not prewarmed")
+		}
+
+		// The duration is counted from the start.
+		for i := 0; i < 55; i++ {
+			e.Update()
+		}
+		if !e.IsEmitting() {
+			t.Fatal("the emitter is stopped before its duration")
+		}
+		for i := 0; i < 10; i++ {
+			e.Update()
+		}
+		if e.IsEmitting() {
+			t.Fatal("the emitter is still emitting after its duration")
+		}
+	})
+
+	t.Run("prewarm one-shot", func(t *testing.T) {
+		tmpl := NewTemplate()
+		tmpl.SetEmitBurst(5, 5)
+		tmpl.SetParticleLifetime(0.5)
+
+		e := NewEmitter(tmpl)
+		e.SetOneShot(true)
+		e.SetPrewarm(2)
+		e.SetAutoDispose(true)
+		e.SetEmitting(true)
+		if e.NumParticles() != 0 || !e.IsEmitting() || e.IsDisposed() {
+			t.Fatalf("one-shot emitter is prewarmed: have %d particles, emitting=%v disposed=%v",
+				e.NumParticles(), e.IsEmitting(), e.IsDisposed())
+		}
+		e.Update()
+		if e.NumParticles() != 5 {
+			t.Fatalf("have %d particles after the burst, want 5", e.NumParticles())
+		}
+	})
+}
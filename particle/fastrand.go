@@ -17,6 +17,17 @@ func fastrand(seed, i uint64) uint64 {
 	return x * 2685821657736338717
 }
 
+// splitmix64 advances the state and returns the next pseudo-random value.
+// It's used as a small per-emitter random source that
+// is good enough to seed the fastrand.
+func splitmix64(state *uint64) uint64 {
+	*state += 0x9e3779b97f4a7c15
+	z := *state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
 func fastrandFloat(seed, i uint64) float64 {
 	const (
 		mantissaBits = 52
@@ -0,0 +1,281 @@
+package particle
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/quasilyte/gmath"
+)
+
+// physicsMode describes how the particle positions are computed.
+type physicsMode uint8
+
+const (
+	// physicsNone is a straight line movement at a constant speed.
+	physicsNone physicsMode = iota
+
+	// physicsClosedForm is used when all forces can be evaluated
+	// analytically from the particle lifetime counter.
+	physicsClosedForm
+
+	// physicsIntegrated is used when some forces depend on the
+	// current particle position (like the radial acceleration).
+	// These particles need an extra per-particle state that is
+	// updated every tick.
+	physicsIntegrated
+)
+
+// particleState is a simulation state for the integrated particles.
+// It's stored in a separate slice, so the particle struct stays compact.
+type particleState struct {
+	pos gmath.Vec32
+	vel gmath.Vec32
+}
+
+// SetGravity assigns a constant world-space acceleration
+// that affects all particles (like a gravity or a wind).
+// The value is in pixels per second squared.
+//
+// The gravity is not affected by the emitter rotation.
+func (tmpl *Template) SetGravity(g gmath.Vec) {
+	tmpl.gravity = g.AsVec32()
+	tmpl.updatePhysicsMode()
+}
+
+// SetDamping assigns a linear velocity damping factor.
+// The particle velocity is reduced by exp(-damping*t),
+// so a value of 1 makes the particle lose ~63% of its speed every second.
+//
+// Zero damping means "no drag".
+func (tmpl *Template) SetDamping(damping float64) {
+	if damping < 0 {
+		panic("damping can't be negative")
+	}
+	tmpl.damping = float32(damping)
+	tmpl.updatePhysicsMode()
+}
+
+// SetParticleAcceleration assigns an acceleration along the particle heading.
+// A negative value slows the particle down (and then makes it move backwards).
+// The value is in pixels per second squared.
+func (tmpl *Template) SetParticleAcceleration(a float64) {
+	tmpl.acceleration = float32(a)
+	tmpl.updatePhysicsMode()
+}
+
+// SetRadialAcceleration assigns an acceleration that pushes the
+// particles away from the emitter (or pulls them closer for negative values).
+// The value is in pixels per second squared.
+//
+// The radial and tangential accelerations depend on the current
+// particle position, so they can't be evaluated analytically.
+// The particles of such templates are simulated step by step and
+// use some extra memory.
+func (tmpl *Template) SetRadialAcceleration(a float64) {
+	tmpl.radialAcceleration = float32(a)
+	tmpl.updatePhysicsMode()
+}
+
+// SetTangentialAcceleration assigns an acceleration that is
+// perpendicular to the emitter->particle direction.
+// Positive values make the particles swirl clockwise.
+// The value is in pixels per second squared.
+//
+// See [Template.SetRadialAcceleration] for the performance notes.
+func (tmpl *Template) SetTangentialAcceleration(a float64) {
+	tmpl.tangentialAcceleration = float32(a)
+	tmpl.updatePhysicsMode()
+}
+
+// SetParticleTurnRate is a shorthand for SetParticleTurnRateRange(rate, rate).
+func (tmpl *Template) SetParticleTurnRate(rate gmath.Rad) {
+	tmpl.SetParticleTurnRateRange(rate, rate)
+}
+
+// SetParticleTurnRateRange assigns the particle angular velocity (radians per second).
+// The particle heading is rotated over time, so it moves along a curve
+// instead of a straight line. The particle image rotates along with its heading.
+//
+// Every particle gets a random turn rate from the [min, max] range.
+func (tmpl *Template) SetParticleTurnRateRange(minRate, maxRate gmath.Rad) {
+	if maxRate < minRate {
+		panic("maxRate can't be less than minRate")
+	}
+
+	if minRate != maxRate {
+		tmpl.needsRandBits |= turnRateRandBit
+	} else {
+		tmpl.needsRandBits &^= turnRateRandBit
+	}
+
+	tmpl.particleMinTurnRate = float32(minRate)
+	tmpl.particleMaxTurnRate = float32(maxRate)
+	tmpl.particleTurnRateStep = float32(maxRate-minRate) / 255
+	tmpl.hasTurnRate = minRate != 0 || maxRate != 0
+	tmpl.updatePhysicsMode()
+}
+
+func (tmpl *Template) updatePhysicsMode() {
+	switch {
+	case tmpl.radialAcceleration != 0 || tmpl.tangentialAcceleration != 0:
+		tmpl.physics = physicsIntegrated
+	case !tmpl.gravity.IsZero() || tmpl.damping != 0 || tmpl.acceleration != 0 || tmpl.hasTurnRate:
+		tmpl.physics = physicsClosedForm
+	default:
+		tmpl.physics = physicsNone
+	}
+}
+
+func (tmpl *Template) particleTurnRate(p *particle) float32 {
+	return tmpl.particleMinTurnRate + tmpl.particleTurnRateStep*float32(p.turnRateSeed)
+}
+
+// particleHeading returns the initial particle movement direction angle.
+func (tmpl *Template) particleHeading(p *particle) float64 {
+	angle := tmpl.particleMinAngle + tmpl.particleAngleStep*float64(p.angleSeed)
+	angle += float64(p.origAngle) * ((2 * math.Pi) / 255)
+	return angle
+}
+
+func (tmpl *Template) particleSpeed(p *particle) float32 {
+	return tmpl.particleMinSpeed + tmpl.particleSpeedStep*float32(p.speedSeed)
+}
+
+func (tmpl *Template) initialState(p *particle) particleState {
+	speed := tmpl.particleSpeed(p)
+	dir := gmath.Vec32{X: 1}.Rotated(gmath.Rad(tmpl.particleHeading(p)))
+	return particleState{
+		pos: p.origPos,
+		vel: dir.Mulf(speed),
+	}
+}
+
+// displacement computes the particle offset from its origin after t seconds.
+//
+// The movement is a superposition of two parts:
+//   - a heading part: the particle moves along its (turning) heading
+//     with the initial speed and the acceleration, both affected by damping
+//   - a gravity part: a constant world-space acceleration affected by damping
+//
+// Both parts have an analytical solution; complex numbers
+// are used to represent the rotating heading.
+func (tmpl *Template) displacement(heading, speed, turnRate, t float64) gmath.Vec32 {
+	k := float64(tmpl.damping)
+	a := float64(tmpl.acceleration)
+	tc := complex(t, 0)
+
+	var d complex128
+	if turnRate == 0 {
+		var dist float64
+		if k == 0 {
+			dist = speed*t + 0.5*a*t*t
+		} else {
+			terminal := a / k
+			dist = terminal*t + (speed-terminal)*(1-math.Exp(-k*t))/k
+		}
+		d = complex(dist, 0)
+	} else {
+		iw := complex(0, turnRate)
+		e := cmplx.Exp(iw * tc)
+		if k == 0 {
+			d = complex(speed, 0)*(e-1)/iw +
+				complex(a, 0)*(tc*e/iw+(e-1)/complex(turnRate*turnRate, 0))
+		} else {
+			terminal := a / k
+			m := complex(-k, turnRate)
+			d = complex(terminal, 0)*(e-1)/iw +
+				complex(speed-terminal, 0)*(cmplx.Exp(m*tc)-1)/m
+		}
+	}
+	d *= cmplx.Rect(1, heading)
+
+	var g float64
+	if k == 0 {
+		g = 0.5 * t * t
+	} else {
+		g = (t - (1-math.Exp(-k*t))/k) / k
+	}
+
+	return gmath.Vec32{
+		X: float32(real(d) + float64(tmpl.gravity.X)*g),
+		Y: float32(imag(d) + float64(tmpl.gravity.Y)*g),
+	}
+}
+
+// particlePos computes the particle position after t seconds.
+// Like origPos, it's a particle image top-left corner.
+//
+// The i is a particle index that is used to access its integrated state.
+func (e *Emitter) particlePos(p *particle, i int, heading float64, t float32) gmath.Vec32 {
+	tmpl := e.tmpl
+	switch {
+	case e.states != nil:
+		return e.states[i].pos
+	case tmpl.physics == physicsClosedForm:
+		speed := float64(tmpl.particleSpeed(p))
+		turnRate := float64(tmpl.particleTurnRate(p))
+		return p.origPos.Add(tmpl.displacement(heading, speed, turnRate, float64(t)))
+	default:
+		dir := gmath.Vec32{X: 1}.Rotated(gmath.Rad(heading))
+		return p.origPos.Add(dir.Mulf(tmpl.particleSpeed(p) * t))
+	}
+}
+
+// particleMotion returns the particle center position and
+// its movement direction after t seconds.
+func (e *Emitter) particleMotion(p *particle, i int, t float32) (gmath.Vec, gmath.Rad) {
+	tmpl := e.tmpl
+	heading := tmpl.particleHeading(p)
+	pos := e.particlePos(p, i, heading, t)
+
+	dir := gmath.Rad(heading)
+	switch {
+	case e.states != nil:
+		if vel := e.states[i].vel; !vel.IsZero() {
+			dir = vel.Angle()
+		}
+	case tmpl.physics == physicsClosedForm:
+		// The velocity direction is approximated using
+		// a position after a tiny time step.
+		const epsilon = 0.001
+		if vel := e.particlePos(p, i, heading, t+epsilon).Sub(pos); !vel.IsZero() {
+			dir = vel.Angle()
+		}
+	}
+
+	w, h := tmpl.frameSize()
+	center := pos.AsVec64().Add(gmath.Vec{X: float64(w) * 0.5, Y: float64(h) * 0.5})
+	return center, dir
+}
+
+// integrate advances the integrated particles simulation by dt seconds.
+// It uses a semi-implicit Euler method.
+func (e *Emitter) integrate(dt float32) {
+	tmpl := e.tmpl
+	center := e.simulationOrigin().AsVec32()
+	damping := float32(1)
+	if tmpl.damping != 0 {
+		damping = float32(math.Exp(-float64(tmpl.damping * dt)))
+	}
+
+	for i := range e.particles {
+		p := &e.particles[i]
+		s := &e.states[i]
+
+		acc := tmpl.gravity
+		if tmpl.acceleration != 0 && !s.vel.IsZero() {
+			acc = acc.Add(s.vel.Normalized().Mulf(tmpl.acceleration))
+		}
+		if r := s.pos.Sub(center); !r.IsZero() {
+			r = r.Normalized()
+			acc = acc.Add(r.Mulf(tmpl.radialAcceleration))
+			acc = acc.Add(gmath.Vec32{X: -r.Y, Y: r.X}.Mulf(tmpl.tangentialAcceleration))
+		}
+
+		s.vel = s.vel.Add(acc.Mulf(dt)).Mulf(damping)
+		if tmpl.hasTurnRate {
+			s.vel = s.vel.Rotated(gmath.Rad(tmpl.particleTurnRate(p) * dt))
+		}
+		s.pos = s.pos.Add(s.vel.Mulf(dt))
+	}
+}
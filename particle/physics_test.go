@@ -0,0 +1,119 @@
+package particle
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/quasilyte/gmath"
+)
+
+// integrateDisplacement is a reference implementation of the
+// closed-form physics: it solves the motion equations numerically.
+//
+//	speed' = acceleration - damping*speed
+//	heading' = turnRate
+//	gravityVel' = gravity - damping*gravityVel
+//	pos' = speed*dir(heading) + gravityVel
+func integrateDisplacement(tmpl *Template, heading, speed, turnRate, t float64) gmath.Vec {
+	type state struct {
+		pos        gmath.Vec
+		speed      float64
+		heading    float64
+		gravityVel gmath.Vec
+	}
+	k := float64(tmpl.damping)
+	a := float64(tmpl.acceleration)
+	g := gmath.Vec{X: float64(tmpl.gravity.X), Y: float64(tmpl.gravity.Y)}
+
+	derivative := func(s state) state {
+		return state{
+			pos:        gmath.RadToVec(gmath.Rad(s.heading)).Mulf(s.speed).Add(s.gravityVel),
+			speed:      a - k*s.speed,
+			heading:    turnRate,
+			gravityVel: g.Sub(s.gravityVel.Mulf(k)),
+		}
+	}
+	step := func(s, d state, dt float64) state {
+		return state{
+			pos:        s.pos.Add(d.pos.Mulf(dt)),
+			speed:      s.speed + d.speed*dt,
+			heading:    s.heading + d.heading*dt,
+			gravityVel: s.gravityVel.Add(d.gravityVel.Mulf(dt)),
+		}
+	}
+
+	// The classic RK4 with a fine step.
+	const numSteps = 20000
+	dt := t / numSteps
+	s := state{speed: speed, heading: heading}
+	for i := 0; i < numSteps; i++ {
+		k1 := derivative(s)
+		k2 := derivative(step(s, k1, dt/2))
+		k3 := derivative(step(s, k2, dt/2))
+		k4 := derivative(step(s, k3, dt))
+		s.pos = s.pos.Add(k1.pos.Add(k2.pos.Mulf(2)).Add(k3.pos.Mulf(2)).Add(k4.pos).Mulf(dt / 6))
+		s.speed += (k1.speed + 2*k2.speed + 2*k3.speed + k4.speed) * dt / 6
+		s.heading += (k1.heading + 2*k2.heading + 2*k3.heading + k4.heading) * dt / 6
+		s.gravityVel = s.gravityVel.Add(k1.gravityVel.Add(k2.gravityVel.Mulf(2)).Add(k3.gravityVel.Mulf(2)).Add(k4.gravityVel).Mulf(dt / 6))
+	}
+	return s.pos
+}
+
+func TestDisplacement(t *testing.T) {
+	tests := []struct {
+		damping      float64
+		acceleration float64
+		turnRate     float64
+		gravity      gmath.Vec
+	}{
+		{},
+		{damping: 1.5},
+		{turnRate: 2},
+		{turnRate: -0.5},
+		{damping: 1.5, turnRate: 2},
+		{damping: 0.2, turnRate: -3},
+		{acceleration: 40},
+		{acceleration: -60},
+		{acceleration: 40, damping: 0.8},
+		{acceleration: -60, damping: 2},
+		{acceleration: 40, turnRate: 1.5},
+		{acceleration: 40, damping: 0.8, turnRate: 1.5},
+		{acceleration: -20, damping: 3, turnRate: -4},
+		{gravity: gmath.Vec{X: 5, Y: 98}},
+		{gravity: gmath.Vec{Y: 98}, damping: 1},
+		{gravity: gmath.Vec{X: -10, Y: 50}, damping: 0.5, acceleration: 30, turnRate: 1},
+	}
+
+	for _, test := range tests {
+		name := fmt.Sprintf("k=%v/a=%v/w=%v/g=%v", test.damping, test.acceleration, test.turnRate, test.gravity)
+		t.Run(name, func(t *testing.T) {
+			tmpl := NewTemplate()
+			tmpl.SetDamping(test.damping)
+			tmpl.SetParticleAcceleration(test.acceleration)
+			tmpl.SetGravity(test.gravity)
+
+			const heading = 0.7
+			const speed = 50
+			for _, lifetime := range []float64{0.1, 0.5, 1, 2.5} {
+				have := tmpl.displacement(heading, speed, test.turnRate, lifetime).AsVec64()
+				want := integrateDisplacement(tmpl, heading, speed, test.turnRate, lifetime)
+				// The displacement is computed in float32.
+				tolerance := 1e-5*want.Len() + 1e-3
+				if have.DistanceTo(want) > tolerance {
+					t.Fatalf("t=%v:\nhave: %v\nwant: %v", lifetime, have, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDisplacementStraightLine(t *testing.T) {
+	// Without any forces, the particle moves along its heading.
+	tmpl := NewTemplate()
+	have := tmpl.displacement(math.Pi/2, 10, 0, 3).AsVec64()
+	want := gmath.Vec{Y: 30}
+	if have.DistanceTo(want) > 1e-4 {
+		t.Fatalf("have: %v\nwant: %v", have, want)
+	}
+}
@@ -22,23 +22,30 @@ import (
 // but stable: this order is consistent between the frames.
 // If different layers are needed, several renderers should be used.
 type Renderer struct {
-	bucketIDByImage map[*ebiten.Image]int
-	bucketList      []*rendererBucket
-	freeList        []*rendererBucket
-	disposed        bool
+	bucketIDByKey map[bucketKey]int
+	bucketList    []*rendererBucket
+	freeList      []*rendererBucket
+	disposed      bool
+}
+
+// bucketKey describes the emitters that can be rendered in one batch.
+type bucketKey struct {
+	img    *ebiten.Image
+	shader *graphics.Shader
+	blend  BlendMode
 }
 
 type rendererBucket struct {
-	img      *ebiten.Image
+	key      bucketKey
 	id       int
 	emitters []*Emitter
 }
 
 func NewRenderer() *Renderer {
 	return &Renderer{
-		bucketIDByImage: make(map[*ebiten.Image]int, 8),
-		bucketList:      make([]*rendererBucket, 0, 8),
-		freeList:        make([]*rendererBucket, 0, 2),
+		bucketIDByKey: make(map[bucketKey]int, 8),
+		bucketList:    make([]*rendererBucket, 0, 8),
+		freeList:      make([]*rendererBucket, 0, 2),
 	}
 }
 
@@ -54,7 +61,12 @@ func (r *Renderer) Dispose() {
 }
 
 func (r *Renderer) AddEmitter(e *Emitter) {
-	bucketID, ok := r.bucketIDByImage[e.tmpl.img]
+	key := bucketKey{
+		img:    e.tmpl.img,
+		shader: e.tmpl.shader,
+		blend:  e.tmpl.blendMode,
+	}
+	bucketID, ok := r.bucketIDByKey[key]
 	var bucket *rendererBucket
 
 	if ok {
@@ -67,21 +79,26 @@ func (r *Renderer) AddEmitter(e *Emitter) {
 			bucket = r.freeList[len(r.freeList)-1]
 			r.freeList = r.freeList[:len(r.freeList)-1]
 			id = bucket.id
-			bucket.img = e.tmpl.img
+			bucket.key = key
 		} else {
 			// Allocate a new bucket.
 			id = len(r.bucketList)
 			bucket = &rendererBucket{
 				id:       id,
-				img:      e.tmpl.img,
+				key:      key,
 				emitters: make([]*Emitter, 0, 8),
 			}
 			r.bucketList = append(r.bucketList, bucket)
 		}
-		r.bucketIDByImage[e.tmpl.img] = id
+		r.bucketIDByKey[key] = id
 	}
 
 	bucket.emitters = append(bucket.emitters, e)
+
+	// The sub-emitters are rendered by the same renderer.
+	for _, sub := range e.subEmitters {
+		r.AddEmitter(sub.emitter)
+	}
 }
 
 func (r *Renderer) Draw(dst *ebiten.Image) {
@@ -90,14 +107,14 @@ func (r *Renderer) Draw(dst *ebiten.Image) {
 
 func (r *Renderer) DrawWithOptions(dst *ebiten.Image, opts graphics.DrawOptions) {
 	for _, bucket := range r.bucketList {
-		if bucket.img == nil {
+		if bucket.key.img == nil {
 			continue // This bucket was retired, it's in the free list
 		}
-		activeEmitters := r.drawBucket(dst, bucket.img, opts, bucket.emitters)
+		activeEmitters := r.drawBucket(dst, bucket.key, opts, bucket.emitters)
 		if len(activeEmitters) == 0 {
-			delete(r.bucketIDByImage, bucket.img)
+			delete(r.bucketIDByKey, bucket.key)
 			bucket.emitters = bucket.emitters[:0]
-			bucket.img = nil
+			bucket.key = bucketKey{}
 			r.freeList = append(r.freeList, bucket)
 			continue
 		}
@@ -105,7 +122,7 @@ func (r *Renderer) DrawWithOptions(dst *ebiten.Image, opts graphics.DrawOptions)
 	}
 }
 
-func (r *Renderer) drawBucket(dst *ebiten.Image, img *ebiten.Image, opts graphics.DrawOptions, emitters []*Emitter) []*Emitter {
+func (r *Renderer) drawBucket(dst *ebiten.Image, key bucketKey, opts graphics.DrawOptions, emitters []*Emitter) []*Emitter {
 	const batchThreshold = math.MaxUint16 / 24 // Doesn't have to be bigger
 	batchParticles := 0
 
@@ -131,7 +148,7 @@ func (r *Renderer) drawBucket(dst *ebiten.Image, img *ebiten.Image, opts graphic
 		}
 
 		if batchParticles+n > batchThreshold {
-			r.drawBatch(dst, img, opts, batch)
+			r.drawBatch(dst, key, opts, batch)
 			batch = batch[:0]
 			batchParticles = 0
 		}
@@ -144,13 +161,13 @@ func (r *Renderer) drawBucket(dst *ebiten.Image, img *ebiten.Image, opts graphic
 	}
 
 	if len(batch) != 0 {
-		r.drawBatch(dst, img, opts, batch)
+		r.drawBatch(dst, key, opts, batch)
 	}
 
 	return activeEmitters
 }
 
-func (r *Renderer) drawBatch(dst, img *ebiten.Image, opts graphics.DrawOptions, emitters []*Emitter) {
+func (r *Renderer) drawBatch(dst *ebiten.Image, key bucketKey, opts graphics.DrawOptions, emitters []*Emitter) {
 	// Use pre-allocated slices.
 	vertices := cache.Global.ScratchVertices[:0]
 	indices := cache.Global.ScratchIndices[:0]
@@ -162,10 +179,26 @@ func (r *Renderer) drawBatch(dst, img *ebiten.Image, opts graphics.DrawOptions,
 	idx := uint16(0)
 	offset32 := opts.Offset.AsVec32()
 
+	// The parent transformation is applied on top of
+	// the particle's own scaling and rotation.
+	parentScale := opts.Scale.AsVec32()
+	if parentScale.IsZero() {
+		parentScale = gmath.Vec32{X: 1, Y: 1}
+	}
+	needParentScaling := parentScale.X != 1 || parentScale.Y != 1
+	parentRotation := float64(opts.Rotation)
+	parentColorScale := opts.ColorScale
+	needParentColor := parentColorScale != (graphics.ColorScale{}) &&
+		parentColorScale != (graphics.ColorScale{R: 1, G: 1, B: 1, A: 1})
+
 	for _, e := range emitters {
 		tmpl := e.tmpl
 
-		w, h := float32(tmpl.img.Bounds().Dx()), float32(tmpl.img.Bounds().Dy())
+		frameWidth, frameHeight := tmpl.frameSize()
+		w, h := float32(frameWidth), float32(frameHeight)
+		srcMin := tmpl.img.Bounds().Min
+		numFrames := tmpl.numFrames
+		frameCols := tmpl.frameCols
 		halfWidth := w * 0.5
 		halfHeight := h * 0.5
 		palette := tmpl.palette
@@ -175,17 +208,34 @@ func (r *Renderer) drawBatch(dst, img *ebiten.Image, opts graphics.DrawOptions,
 			tmpl.particleMinScaling.Y != 1
 		needAngle := tmpl.particleMinAngle != 0 ||
 			tmpl.particleMaxAngle != 0 ||
-			e.Rotation != nil
-
+			e.Rotation != nil ||
+			tmpl.emitAlongNormal ||
+			tmpl.physics != physicsNone ||
+			tmpl.hasSpin
+
+		colorLUT := tmpl.colorLUT
+		scaleLUT := tmpl.scaleLUT
+		needFade := tmpl.alphaFadeIn != 0 || tmpl.alphaFadeOut != 0
 		updateColorScaleFunc := tmpl.updateColorScaleFunc
 		updateScalingFunc := tmpl.updateScalingFunc
 
+		// The local space particles are attached to the emitter.
+		isLocal := tmpl.space == SpaceLocal
+		var emitterPos gmath.Vec32
+		var emitterRotation float64
+		if isLocal {
+			emitterPos = e.Pos.Resolve().AsVec32()
+			if e.Rotation != nil {
+				emitterRotation = float64(*e.Rotation)
+			}
+		}
+
 		ctx := UpdateContext{emitter: e}
 		minSpeed := tmpl.particleMinSpeed
 		speedStep := tmpl.particleSpeedStep
 		minScaling := tmpl.particleMinScaling
 		scalingStep := tmpl.particleScalingStep
-		for _, p := range e.particles {
+		for i, p := range e.particles {
 			var pos xmath.Geom32
 			var angle float64
 			{
@@ -197,18 +247,37 @@ func (r *Renderer) drawBatch(dst, img *ebiten.Image, opts graphics.DrawOptions,
 
 				dir := gmath.Vec32{X: 1, Y: 0}
 				if needAngle {
-					angle = tmpl.particleMinAngle + tmpl.particleAngleStep*float64(p.angleSeed)
-					angle += float64(p.origAngle) * ((2 * math.Pi) / 255)
+					angle = tmpl.particleHeading(&p)
 					dir = dir.Rotated(gmath.Rad(angle))
 				}
 
 				speed := minSpeed + (speedStep * float32(p.speedSeed))
-				currentPos := origPos.Add(dir.Mulf(speed).Mulf(fcounter * 0.001))
+				var currentPos gmath.Vec32
+				switch {
+				case e.states != nil:
+					currentPos = e.states[i].pos
+				case tmpl.physics == physicsClosedForm:
+					t := float64(fcounter * 0.001)
+					turnRate := float64(tmpl.particleTurnRate(&p))
+					currentPos = origPos.Add(tmpl.displacement(angle, float64(speed), turnRate, t))
+				default:
+					currentPos = origPos.Add(dir.Mulf(speed).Mulf(fcounter * 0.001))
+				}
+				if tmpl.hasTurnRate {
+					// The particle image follows its heading.
+					angle += float64(tmpl.particleTurnRate(&p) * fcounter * 0.001)
+				}
+				if tmpl.hasSpin {
+					angle += float64(tmpl.particleSpin(&p) * fcounter * 0.001)
+				}
 
 				scaling := gmath.Vec32{X: 1, Y: 1}
 				if needScaling {
 					scaling = minScaling.Add(scalingStep.Mulf(float32(p.scalingSeed)))
 				}
+				if scaleLUT != nil {
+					scaling = scaling.Mulf(scaleLUT[lutIndex(progress)])
+				}
 				if updateScalingFunc != nil {
 					scaling = scaling.Mul(updateScalingFunc(ctx))
 				}
@@ -220,22 +289,53 @@ func (r *Renderer) drawBatch(dst, img *ebiten.Image, opts graphics.DrawOptions,
 				if angle != 0 {
 					pos.Rotate(angle)
 				}
-				pos.Translate(halfWidth, halfHeight)
-				pos.Translate(offset32.X+currentPos.X, offset32.Y+currentPos.Y)
+				pos.Translate(halfWidth+currentPos.X, halfHeight+currentPos.Y)
+				if isLocal {
+					if emitterRotation != 0 {
+						pos.Rotate(emitterRotation)
+					}
+					pos.Translate(emitterPos.X, emitterPos.Y)
+				}
+				if needParentScaling {
+					pos.Scale(parentScale.X, parentScale.Y)
+				}
+				if parentRotation != 0 {
+					pos.Rotate(parentRotation)
+				}
+				pos.Translate(offset32.X, offset32.Y)
 			}
 
 			clr := palette[p.paletteIndex]
+			if colorLUT != nil {
+				clr = clr.Mul(colorLUT[lutIndex(ctx.t)])
+			}
+			if needFade {
+				clr.A *= tmpl.alphaFade(ctx.t)
+			}
 			if updateColorScaleFunc != nil {
 				clr = clr.Mul(updateColorScaleFunc(ctx))
 			}
+			if needParentColor {
+				clr = clr.Mul(parentColorScale)
+			}
+
+			// The source rect is a current animation frame
+			// or the entire image for the non-animated particles.
+			srcX := float32(srcMin.X)
+			srcY := float32(srcMin.Y)
+			if numFrames != 0 {
+				frame := tmpl.particleFrame(&p, ctx.t, float32(p.counter)*0.001)
+				srcX += float32(frame%frameCols) * w
+				srcY += float32(frame/frameCols) * h
+			}
 
 			x := pos.Tx
 			y := pos.Ty
 			vertices = append(vertices,
-				ebiten.Vertex{DstX: x, DstY: y, SrcX: 0, SrcY: 0, ColorR: clr.R, ColorG: clr.G, ColorB: clr.B, ColorA: clr.A},
-				ebiten.Vertex{DstX: (pos.A1+1)*w + x, DstY: pos.C*w + y, SrcX: w, SrcY: 0, ColorR: clr.R, ColorG: clr.G, ColorB: clr.B, ColorA: clr.A},
-				ebiten.Vertex{DstX: pos.B*h + x, DstY: (pos.D1+1)*h + y, SrcX: 0, SrcY: h, ColorR: clr.R, ColorG: clr.G, ColorB: clr.B, ColorA: clr.A},
-				ebiten.Vertex{DstX: pos.ApplyX(w, h), DstY: pos.ApplyY(w, h), SrcX: w, SrcY: h, ColorR: clr.R, ColorG: clr.G, ColorB: clr.B, ColorA: clr.A},
+				ebiten.Vertex{DstX: x, DstY: y, SrcX: srcX, SrcY: srcY, ColorR: clr.R, ColorG: clr.G, ColorB: clr.B, ColorA: clr.A},
+				ebiten.Vertex{DstX: (pos.A1+1)*w + x, DstY: pos.C*w + y, SrcX: srcX + w, SrcY: srcY, ColorR: clr.R, ColorG: clr.G, ColorB: clr.B, ColorA: clr.A},
+				ebiten.Vertex{DstX: pos.B*h + x, DstY: (pos.D1+1)*h + y, SrcX: srcX, SrcY: srcY + h, ColorR: clr.R, ColorG: clr.G, ColorB: clr.B, ColorA: clr.A},
+				ebiten.Vertex{DstX: pos.ApplyX(w, h), DstY: pos.ApplyY(w, h), SrcX: srcX + w, SrcY: srcY + h, ColorR: clr.R, ColorG: clr.G, ColorB: clr.B, ColorA: clr.A},
 			)
 
 			indices = append(indices, idx, idx+1, idx+2, idx+1, idx+2, idx+3)
@@ -243,6 +343,17 @@ func (r *Renderer) drawBatch(dst, img *ebiten.Image, opts graphics.DrawOptions,
 		}
 	}
 
+	blend := key.blend.blend()
+	if opts.Blend != nil {
+		blend = *opts.Blend
+	}
+
+	if key.shader != nil && key.shader.Enabled {
+		key.shader.DrawTriangles(dst, vertices, indices, key.img, blend)
+		return
+	}
+
 	var drawOptions ebiten.DrawTrianglesOptions
-	dst.DrawTriangles(vertices, indices, img, &drawOptions)
+	drawOptions.Blend = blend
+	dst.DrawTriangles(vertices, indices, key.img, &drawOptions)
 }
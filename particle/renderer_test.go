@@ -0,0 +1,110 @@
+package particle
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	graphics "github.com/quasilyte/ebitengine-graphics"
+)
+
+func findBucket(r *Renderer, e *Emitter) *rendererBucket {
+	for _, b := range r.bucketList {
+		for _, x := range b.emitters {
+			if x == e {
+				return b
+			}
+		}
+	}
+	return nil
+}
+
+func TestRendererBuckets(t *testing.T) {
+	img := ebiten.NewImage(4, 4)
+	shader1 := graphics.NewShader(nil)
+	shader2 := graphics.NewShader(nil)
+
+	newTemplate := func(blend BlendMode, shader *graphics.Shader) *Template {
+		tmpl := NewTemplate()
+		tmpl.SetImage(img)
+		tmpl.SetBlendMode(blend)
+		tmpl.SetShader(shader)
+		return tmpl
+	}
+
+	normal1 := NewEmitter(newTemplate(BlendNormal, nil))
+	normal2 := NewEmitter(newTemplate(BlendNormal, nil))
+	additive := NewEmitter(newTemplate(BlendAdditive, nil))
+	multiply := NewEmitter(newTemplate(BlendMultiply, nil))
+	shaded1 := NewEmitter(newTemplate(BlendNormal, shader1))
+	shaded2 := NewEmitter(newTemplate(BlendNormal, shader2))
+	shaded1Additive := NewEmitter(newTemplate(BlendAdditive, shader1))
+
+	r := NewRenderer()
+	emitters := []*Emitter{normal1, normal2, additive, multiply, shaded1, shaded2, shaded1Additive}
+	for _, e := range emitters {
+		r.AddEmitter(e)
+	}
+
+	// Same image, blend and shader share the bucket,
+	// even if the templates are different.
+	if findBucket(r, normal1) != findBucket(r, normal2) {
+		t.Fatal("the emitters with identical keys are not batched together")
+	}
+	if len(r.bucketList) != 6 {
+		t.Fatalf("have %d buckets, want 6", len(r.bucketList))
+	}
+	seen := map[*rendererBucket]*Emitter{}
+	for _, e := range emitters[1:] {
+		b := findBucket(r, e)
+		if b == nil {
+			t.Fatal("emitter is not added to any bucket")
+		}
+		if other, ok := seen[b]; ok {
+			t.Fatalf("emitters with keys %+v and %+v share a bucket",
+				bucketKey{img: e.tmpl.img, shader: e.tmpl.shader, blend: e.tmpl.blendMode},
+				bucketKey{img: other.tmpl.img, shader: other.tmpl.shader, blend: other.tmpl.blendMode})
+		}
+		seen[b] = e
+	}
+
+	// A bucket without live emitters is retired during the Draw.
+	dst := ebiten.NewImage(8, 8)
+	additiveBucket := findBucket(r, additive)
+	additiveID := additiveBucket.id
+	additive.Dispose()
+	r.Draw(dst)
+	if additiveBucket.key != (bucketKey{}) || len(additiveBucket.emitters) != 0 {
+		t.Fatal("the bucket of a disposed emitter is not retired")
+	}
+	if len(r.freeList) != 1 || r.freeList[0] != additiveBucket {
+		t.Fatal("the retired bucket is not in the free list")
+	}
+	if len(r.bucketIDByKey) != 5 {
+		t.Fatalf("have %d active bucket keys, want 5", len(r.bucketIDByKey))
+	}
+
+	// The other buckets are kept.
+	normal2.Dispose()
+	r.Draw(dst)
+	if b := findBucket(r, normal1); b == nil || len(b.emitters) != 1 {
+		t.Fatal("the bucket with a live emitter is retired")
+	}
+
+	// A new key re-uses the retired bucket.
+	otherImage := NewTemplate()
+	otherImage.SetImage(ebiten.NewImage(2, 2))
+	reused := NewEmitter(otherImage)
+	r.AddEmitter(reused)
+	if b := findBucket(r, reused); b != additiveBucket || b.id != additiveID {
+		t.Fatal("the retired bucket is not re-used")
+	}
+	if len(r.bucketList) != 6 || len(r.freeList) != 0 {
+		t.Fatalf("have %d buckets and %d free buckets, want 6 and 0", len(r.bucketList), len(r.freeList))
+	}
+
+	// The key that was retired creates a new bucket.
+	r.AddEmitter(NewEmitter(newTemplate(BlendAdditive, nil)))
+	if len(r.bucketList) != 7 {
+		t.Fatalf("have %d buckets, want 7", len(r.bucketList))
+	}
+}
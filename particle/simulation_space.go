@@ -0,0 +1,108 @@
+package particle
+
+import (
+	"fmt"
+
+	"github.com/quasilyte/gmath"
+)
+
+// SimulationSpace describes the coordinate system the particles live in.
+type SimulationSpace uint8
+
+const (
+	// SpaceWorld particles are not affected by the emitter movement
+	// after they're emitted. A moving emitter leaves a trail of particles.
+	SpaceWorld SimulationSpace = iota
+
+	// SpaceLocal particles are positioned relative to the current
+	// emitter position and rotation, so they move along with the emitter.
+	// It's useful for the effects like shields and auras.
+	SpaceLocal
+)
+
+var simulationSpaceNames = [...]string{
+	SpaceWorld: "world",
+	SpaceLocal: "local",
+}
+
+func (s SimulationSpace) String() string {
+	if int(s) < len(simulationSpaceNames) {
+		return simulationSpaceNames[s]
+	}
+	return fmt.Sprintf("SimulationSpace(%d)", s)
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (s SimulationSpace) MarshalText() ([]byte, error) {
+	if int(s) >= len(simulationSpaceNames) {
+		return nil, fmt.Errorf("invalid simulation space value %d", s)
+	}
+	return []byte(simulationSpaceNames[s]), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (s *SimulationSpace) UnmarshalText(data []byte) error {
+	for i, name := range simulationSpaceNames {
+		if name == string(data) {
+			*s = SimulationSpace(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown simulation space %q", data)
+}
+
+// SetSimulationSpace assigns the particles coordinate system.
+// The default space is [SpaceWorld].
+//
+// In the local space, all particle movement (including the gravity)
+// happens in the emitter coordinate system: the particles are
+// translated by the current emitter Pos and rotated by its Rotation
+// during the rendering. The emitter PivotOffset is a local
+// spawn position, so the particles rotate around the emitter Pos.
+//
+// Changing the simulation space doesn't affect the
+// already emitted particles positions, so it's better to
+// assign it before the template is used.
+func (tmpl *Template) SetSimulationSpace(space SimulationSpace) {
+	if space > SpaceLocal {
+		panic("invalid simulation space")
+	}
+	tmpl.space = space
+}
+
+// GetSimulationSpace returns the current particles coordinate system.
+// Use SetSimulationSpace to change it.
+func (tmpl *Template) GetSimulationSpace() SimulationSpace {
+	return tmpl.space
+}
+
+// simulationOrigin is like spawnOrigin, but the result
+// is in the template simulation space.
+func (e *Emitter) simulationOrigin() gmath.Vec {
+	if e.tmpl.space == SpaceLocal {
+		return e.centeredOrigin(e.PivotOffset)
+	}
+	return e.spawnOrigin()
+}
+
+// simulationRotation returns the emitter rotation in the simulation space.
+// The local space particles are rotated during the rendering instead.
+func (e *Emitter) simulationRotation() *gmath.Rad {
+	if e.tmpl.space == SpaceLocal {
+		return nil
+	}
+	return e.Rotation
+}
+
+// toWorld converts the simulation space position
+// and direction into the world space.
+func (e *Emitter) toWorld(pos gmath.Vec, dir gmath.Rad) (gmath.Vec, gmath.Rad) {
+	if e.tmpl.space != SpaceLocal {
+		return pos, dir
+	}
+	if e.Rotation != nil {
+		pos = pos.Rotated(*e.Rotation)
+		dir += *e.Rotation
+	}
+	return pos.Add(e.Pos.Resolve()), dir
+}
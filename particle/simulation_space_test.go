@@ -0,0 +1,125 @@
+package particle
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/quasilyte/gmath"
+)
+
+func newSpaceTestEmitter(space SimulationSpace, rotation float64) *Emitter {
+	tmpl := NewTemplate()
+	tmpl.SetImage(ebiten.NewImage(4, 2))
+	tmpl.SetParticleSpeedRange(0, 0)
+	tmpl.SetSimulationSpace(space)
+
+	e := NewEmitter(tmpl)
+	e.Pos.Offset = gmath.Vec{X: 100, Y: 50}
+	e.PivotOffset = gmath.Vec{X: 10, Y: 0}
+	if rotation != 0 {
+		r := gmath.Rad(rotation)
+		e.Rotation = &r
+	}
+	return e
+}
+
+func TestSimulationOrigin(t *testing.T) {
+	tests := []struct {
+		space    SimulationSpace
+		rotation float64
+		want     gmath.Vec
+	}{
+		// The world space origin is the rotated pivot around the emitter Pos.
+		{SpaceWorld, 0, gmath.Vec{X: 108, Y: 49}},
+		{SpaceWorld, math.Pi / 2, gmath.Vec{X: 98, Y: 59}},
+		{SpaceWorld, math.Pi, gmath.Vec{X: 88, Y: 49}},
+
+		// The local space origin is the pivot itself,
+		// the emitter transform is applied during the rendering.
+		{SpaceLocal, 0, gmath.Vec{X: 8, Y: -1}},
+		{SpaceLocal, math.Pi / 2, gmath.Vec{X: 8, Y: -1}},
+		{SpaceLocal, math.Pi, gmath.Vec{X: 8, Y: -1}},
+	}
+
+	for _, test := range tests {
+		e := newSpaceTestEmitter(test.space, test.rotation)
+		have := e.simulationOrigin()
+		if have.DistanceTo(test.want) > 1e-9 {
+			t.Errorf("space=%v rotation=%v:\nhave: %v\nwant: %v", test.space, test.rotation, have, test.want)
+		}
+
+		rotation := e.simulationRotation()
+		switch test.space {
+		case SpaceWorld:
+			if rotation != e.Rotation {
+				t.Errorf("space=%v rotation=%v: the emitter rotation is not used", test.space, test.rotation)
+			}
+		case SpaceLocal:
+			if rotation != nil {
+				t.Errorf("space=%v rotation=%v: the rotation is applied twice", test.space, test.rotation)
+			}
+		}
+	}
+}
+
+func TestSimulationToWorld(t *testing.T) {
+	tests := []struct {
+		space    SimulationSpace
+		rotation float64
+		pos      gmath.Vec
+		dir      gmath.Rad
+		wantPos  gmath.Vec
+		wantDir  gmath.Rad
+	}{
+		{SpaceWorld, 0, gmath.Vec{X: 10, Y: 5}, 1, gmath.Vec{X: 10, Y: 5}, 1},
+		{SpaceWorld, math.Pi / 2, gmath.Vec{X: 10, Y: 5}, 1, gmath.Vec{X: 10, Y: 5}, 1},
+
+		{SpaceLocal, 0, gmath.Vec{X: 10, Y: 5}, 1, gmath.Vec{X: 110, Y: 55}, 1},
+		{SpaceLocal, math.Pi / 2, gmath.Vec{X: 10, Y: 5}, 1, gmath.Vec{X: 95, Y: 60}, 1 + math.Pi/2},
+		{SpaceLocal, math.Pi, gmath.Vec{X: 10, Y: 5}, 0, gmath.Vec{X: 90, Y: 45}, math.Pi},
+	}
+
+	for _, test := range tests {
+		e := newSpaceTestEmitter(test.space, test.rotation)
+		pos, dir := e.toWorld(test.pos, test.dir)
+		if pos.DistanceTo(test.wantPos) > 1e-9 || math.Abs(float64(dir-test.wantDir)) > 1e-9 {
+			t.Errorf("space=%v rotation=%v: have (%v, %v), want (%v, %v)",
+				test.space, test.rotation, pos, dir, test.wantPos, test.wantDir)
+		}
+	}
+}
+
+func TestSimulationSpaceSpawnPos(t *testing.T) {
+	// A particle with zero speed stays at its spawn point.
+	// Its world position is the rotated pivot around the emitter Pos
+	// in both spaces.
+	for _, space := range []SimulationSpace{SpaceWorld, SpaceLocal} {
+		for _, rotation := range []float64{0, math.Pi / 2, -math.Pi / 4} {
+			e := newSpaceTestEmitter(space, rotation)
+			e.emit(0)
+			if e.NumParticles() != 1 {
+				t.Fatalf("have %d particles, want 1", e.NumParticles())
+			}
+
+			want := e.Pos.Resolve().Add(e.PivotOffset.Rotated(gmath.Rad(rotation)))
+			have, _ := e.toWorld(e.particleMotion(&e.particles[0], 0, 0))
+			if have.DistanceTo(want) > 1e-4 {
+				t.Errorf("space=%v rotation=%v:\nhave: %v\nwant: %v", space, rotation, have, want)
+			}
+
+			// The local space particles follow the emitter.
+			e.Pos.Offset = e.Pos.Offset.Add(gmath.Vec{X: 20, Y: 30})
+			moved, _ := e.toWorld(e.particleMotion(&e.particles[0], 0, 0))
+			shift := moved.Sub(have)
+			wantShift := gmath.Vec{}
+			if space == SpaceLocal {
+				wantShift = gmath.Vec{X: 20, Y: 30}
+			}
+			if shift.DistanceTo(wantShift) > 1e-4 {
+				t.Errorf("space=%v rotation=%v: the emitter movement shifted the particle by %v, want %v",
+					space, rotation, shift, wantShift)
+			}
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package particle
+
+import (
+	"math"
+
+	"github.com/quasilyte/gmath"
+)
+
+// DefaultSubEmitterMaxParticles is a [SubEmitter] MaxParticles default value.
+const DefaultSubEmitterMaxParticles = 512
+
+// SubEmitTrigger describes when a [SubEmitter] spawns its particles.
+type SubEmitTrigger uint8
+
+const (
+	// SubEmitOnBirth spawns the particles when a parent particle is emitted.
+	SubEmitOnBirth SubEmitTrigger = iota
+
+	// SubEmitOnDeath spawns the particles when a parent particle expires.
+	// This is how the fireworks explosions are usually implemented.
+	SubEmitOnDeath
+
+	// SubEmitWhileAlive spawns the particles every Interval seconds
+	// while a parent particle is alive.
+	// This is how the trails are usually implemented.
+	SubEmitWhileAlive
+)
+
+// SubEmitter describes a template that is emitted by the particles of another template.
+//
+// Every spawn emits a burst of the sub-emitter template particles
+// (see [Template.SetEmitBurst]) at the parent particle current position.
+// The sub-emitter particle directions are relative to the parent
+// particle movement direction, so the sub-emitter template
+// with zero direction and spread continues the parent particle movement.
+//
+// The spawned particles are not affected by the parent particle after that.
+type SubEmitter struct {
+	Template *Template
+
+	Trigger SubEmitTrigger
+
+	// Interval is a time (in seconds) between the spawns.
+	// It's only used by the [SubEmitWhileAlive] trigger.
+	Interval float64
+
+	// MaxParticles limits the number of the alive sub-emitter particles
+	// (per parent emitter). When this limit is reached, spawns are ignored.
+	// This protects from the runaway particle counts.
+	//
+	// A zero value means [DefaultSubEmitterMaxParticles].
+	MaxParticles int
+}
+
+// AddSubEmitter adds a template that is emitted by this template particles.
+// Sub-emitter templates can have their own sub-emitters.
+//
+// The sub-emitters are bound to the [Emitter] during its creation,
+// so they should be added before the template is used by [NewEmitter].
+// An emitter renders its sub-emitter particles as a part of the [Renderer]
+// it's added to.
+//
+// It panics if the sub-emitter config is invalid or it creates
+// a template cycle (a template emits itself, directly or indirectly).
+func (tmpl *Template) AddSubEmitter(sub SubEmitter) {
+	if sub.Template == nil {
+		panic("sub-emitter template is nil")
+	}
+	if sub.Trigger > SubEmitWhileAlive {
+		panic("invalid sub-emitter trigger")
+	}
+	if sub.Trigger == SubEmitWhileAlive && sub.Interval <= 0 {
+		panic("sub-emitter interval should be positive")
+	}
+	if sub.MaxParticles < 0 {
+		panic("sub-emitter max particles can't be negative")
+	}
+	if sub.Template.emitsTemplate(tmpl) {
+		panic("sub-emitter creates a template cycle")
+	}
+	if sub.MaxParticles == 0 {
+		sub.MaxParticles = DefaultSubEmitterMaxParticles
+	}
+	tmpl.subEmitters = append(tmpl.subEmitters, sub)
+}
+
+// ClearSubEmitters removes all sub-emitters from the template.
+// It doesn't affect the already created emitters.
+func (tmpl *Template) ClearSubEmitters() {
+	tmpl.subEmitters = nil
+}
+
+// GetSubEmitters returns the template sub-emitters.
+// The returned slice should not be modified.
+func (tmpl *Template) GetSubEmitters() []SubEmitter {
+	return tmpl.subEmitters
+}
+
+// emitsTemplate reports whether tmpl is target or
+// it has target among its sub-emitters (recursively).
+func (tmpl *Template) emitsTemplate(target *Template) bool {
+	if tmpl == target {
+		return true
+	}
+	for _, sub := range tmpl.subEmitters {
+		if sub.Template.emitsTemplate(target) {
+			return true
+		}
+	}
+	return false
+}
+
+// subEmitterInstance is a sub-emitter bound to its parent emitter.
+// The config is copied, so the template changes made after
+// the emitter creation don't affect it.
+type subEmitterInstance struct {
+	config  SubEmitter
+	emitter *Emitter
+}
+
+func (e *Emitter) initSubEmitters() {
+	if len(e.tmpl.subEmitters) == 0 {
+		return
+	}
+	e.subEmitters = make([]subEmitterInstance, len(e.tmpl.subEmitters))
+	for i, sub := range e.tmpl.subEmitters {
+		child := NewEmitter(sub.Template)
+		child.maxParticles = sub.MaxParticles
+		e.subEmitters[i] = subEmitterInstance{config: sub, emitter: child}
+		if sub.Trigger == SubEmitWhileAlive {
+			e.hasContinuousSubEmitters = true
+		}
+	}
+}
+
+// triggerSubEmitters spawns the sub-emitter particles for the specified event.
+// The t is a time (in seconds) since the parent particle birth;
+// the overshoot is a time that already passed since the event.
+func (e *Emitter) triggerSubEmitters(trigger SubEmitTrigger, p *particle, i int, t, overshoot float32) {
+	hasPos := false
+	var pos gmath.Vec
+	var dir gmath.Rad
+	for _, sub := range e.subEmitters {
+		if sub.config.Trigger != trigger {
+			continue
+		}
+		child := sub.emitter
+		if len(child.particles) >= child.maxParticles {
+			continue
+		}
+		if !hasPos {
+			pos, dir = e.toWorld(e.particleMotion(p, i, t))
+			hasPos = true
+		}
+		child.emitFrom(child.centeredOrigin(pos), &dir, overshoot)
+	}
+}
+
+// updateContinuousSubEmitters is called for every particle that
+// moved from prevCounter to its current counter value.
+//
+// A single update can cross several interval boundaries (when the
+// interval is shorter than the update delta), every crossed boundary
+// is a separate spawn.
+// The spawns happen at the parent particle position at the boundary time,
+// their overshoot is a time passed since that boundary.
+func (e *Emitter) updateContinuousSubEmitters(p *particle, i int, prevCounter uint16) {
+	for _, sub := range e.subEmitters {
+		if sub.config.Trigger != SubEmitWhileAlive {
+			continue
+		}
+		interval := int(gmath.Clamp(sub.config.Interval*1000, 1, math.MaxUint16))
+		child := sub.emitter
+		for boundary := (int(prevCounter)/interval + 1) * interval; boundary <= int(p.counter); boundary += interval {
+			if len(child.particles) >= child.maxParticles {
+				break
+			}
+			pos, dir := e.toWorld(e.particleMotion(p, i, float32(boundary)*0.001))
+			overshoot := float32(int(p.counter)-boundary) * 0.001
+			child.emitFrom(child.centeredOrigin(pos), &dir, overshoot)
+		}
+	}
+}
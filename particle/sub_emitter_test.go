@@ -0,0 +1,187 @@
+package particle
+
+import (
+	"testing"
+)
+
+func newSubEmitterTestEmitter(sub SubEmitter, parentBurst int, parentLifetime float64) (*Emitter, *Emitter) {
+	tmpl := NewTemplate()
+	tmpl.SetEmitBurst(parentBurst, parentBurst)
+	tmpl.SetParticleLifetime(parentLifetime)
+	tmpl.AddSubEmitter(sub)
+
+	e := NewEmitter(tmpl)
+	e.SetOneShot(true)
+	e.SetEmitting(true)
+	return e, e.subEmitters[0].emitter
+}
+
+func newSubTemplate(burst int) *Template {
+	tmpl := NewTemplate()
+	tmpl.SetEmitBurst(burst, burst)
+	tmpl.SetParticleLifetime(10)
+	return tmpl
+}
+
+func TestSubEmitterOnBirth(t *testing.T) {
+	e, child := newSubEmitterTestEmitter(SubEmitter{
+		Template: newSubTemplate(3),
+		Trigger:  SubEmitOnBirth,
+	}, 2, 1)
+
+	e.Update()
+	if e.NumParticles() != 2 {
+		t.Fatalf("have %d parent particles, want 2", e.NumParticles())
+	}
+	if child.NumParticles() != 6 {
+		t.Fatalf("have %d sub-emitter particles, want 6", child.NumParticles())
+	}
+
+	// No more spawns while the parents are alive.
+	for i := 0; i < 10; i++ {
+		e.Update()
+	}
+	if child.NumParticles() != 6 {
+		t.Fatalf("have %d sub-emitter particles after updates, want 6", child.NumParticles())
+	}
+}
+
+func TestSubEmitterOnDeath(t *testing.T) {
+	e, child := newSubEmitterTestEmitter(SubEmitter{
+		Template: newSubTemplate(3),
+		Trigger:  SubEmitOnDeath,
+	}, 2, 0.1)
+
+	e.Update()
+	if child.NumParticles() != 0 {
+		t.Fatalf("have %d sub-emitter particles before the death", child.NumParticles())
+	}
+	for i := 0; i < 10; i++ {
+		e.Update()
+	}
+	if e.NumParticles() != 0 {
+		t.Fatalf("have %d parent particles, want 0", e.NumParticles())
+	}
+	if child.NumParticles() != 6 {
+		t.Fatalf("have %d sub-emitter particles, want 6", child.NumParticles())
+	}
+	if e.IsFinished() {
+		t.Fatal("the emitter is finished while the sub-emitter particles are alive")
+	}
+}
+
+func TestSubEmitterWhileAlive(t *testing.T) {
+	tests := []struct {
+		interval float64
+		want     int
+	}{
+		{interval: 0.032, want: 5},
+		{interval: 0.016, want: 10},
+		{interval: 0.008, want: 20},
+		// The interval is shorter than the update delta:
+		// every crossed interval is a separate spawn.
+		{interval: 0.004, want: 40},
+		{interval: 0.001, want: 160},
+	}
+
+	for _, test := range tests {
+		e, child := newSubEmitterTestEmitter(SubEmitter{
+			Template: newSubTemplate(1),
+			Trigger:  SubEmitWhileAlive,
+			Interval: test.interval,
+		}, 1, 1)
+
+		e.UpdateWithDelta(0.016)
+		start := child.NumParticles()
+		maxPerUpdate := max(1, int(0.016/test.interval+0.5))
+		for i := 0; i < 10; i++ {
+			prev := child.NumParticles()
+			e.UpdateWithDelta(0.016)
+			if n := child.NumParticles() - prev; n > maxPerUpdate {
+				t.Fatalf("interval=%v update %d: have %d spawns, want at most %d", test.interval, i, n, maxPerUpdate)
+			}
+		}
+		// 10 updates are 160ms of the parent particle lifetime.
+		if have := child.NumParticles() - start; have != test.want {
+			t.Fatalf("interval=%v: have %d spawns, want %d", test.interval, have, test.want)
+		}
+	}
+}
+
+func TestSubEmitterMaxParticles(t *testing.T) {
+	tests := []struct {
+		trigger  SubEmitTrigger
+		interval float64
+	}{
+		{trigger: SubEmitOnBirth},
+		{trigger: SubEmitOnDeath},
+		{trigger: SubEmitWhileAlive, interval: 0.001},
+	}
+
+	for _, test := range tests {
+		e, child := newSubEmitterTestEmitter(SubEmitter{
+			Template:     newSubTemplate(3),
+			Trigger:      test.trigger,
+			Interval:     test.interval,
+			MaxParticles: 10,
+		}, 8, 0.1)
+
+		for i := 0; i < 20; i++ {
+			e.Update()
+			if child.NumParticles() > 10 {
+				t.Fatalf("trigger=%d: have %d sub-emitter particles, want at most 10", test.trigger, child.NumParticles())
+			}
+		}
+		if child.NumParticles() != 10 {
+			t.Fatalf("trigger=%d: have %d sub-emitter particles, want 10", test.trigger, child.NumParticles())
+		}
+	}
+}
+
+func TestAddSubEmitterPanics(t *testing.T) {
+	expectPanic := func(name, want string, f func()) {
+		t.Helper()
+		defer func() {
+			r := recover()
+			if r == nil {
+				t.Fatalf("%s: expected a panic", name)
+			}
+			if r != want {
+				t.Fatalf("%s: have %q panic, want %q", name, r, want)
+			}
+		}()
+		f()
+	}
+
+	a := NewTemplate()
+	b := NewTemplate()
+	c := NewTemplate()
+	a.AddSubEmitter(SubEmitter{Template: b})
+	b.AddSubEmitter(SubEmitter{Template: c})
+
+	expectPanic("self", "sub-emitter creates a template cycle", func() {
+		a.AddSubEmitter(SubEmitter{Template: a})
+	})
+	expectPanic("indirect cycle", "sub-emitter creates a template cycle", func() {
+		c.AddSubEmitter(SubEmitter{Template: a})
+	})
+	expectPanic("nil template", "sub-emitter template is nil", func() {
+		a.AddSubEmitter(SubEmitter{})
+	})
+	expectPanic("zero interval", "sub-emitter interval should be positive", func() {
+		a.AddSubEmitter(SubEmitter{Template: c, Trigger: SubEmitWhileAlive})
+	})
+	expectPanic("negative max particles", "sub-emitter max particles can't be negative", func() {
+		a.AddSubEmitter(SubEmitter{Template: c, MaxParticles: -1})
+	})
+
+	// The failed calls don't modify the template.
+	if len(a.GetSubEmitters()) != 1 || len(c.GetSubEmitters()) != 0 {
+		t.Fatal("a failed AddSubEmitter modified the template")
+	}
+	// The same template can be used by several sub-emitters (it's not a cycle).
+	a.AddSubEmitter(SubEmitter{Template: c})
+	if have := a.GetSubEmitters()[1].MaxParticles; have != DefaultSubEmitterMaxParticles {
+		t.Fatalf("have %d max particles, want the default", have)
+	}
+}
@@ -2,6 +2,7 @@ package particle
 
 import (
 	"math"
+	"slices"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	graphics "github.com/quasilyte/ebitengine-graphics"
@@ -15,6 +16,10 @@ const (
 	angleRandBit
 	lifetimeRandBit
 	scalingRandBit
+	turnRateRandBit
+	shapeRandBit
+	frameRandBit
+	spinRandBit
 )
 
 var defaultPalette = []graphics.ColorScale{
@@ -45,7 +50,7 @@ func (ctx *SpawnContext) Generation() int {
 // randomness may be inferior. It should be good enough
 // for the purposes of generating varying particles.
 func (ctx *SpawnContext) Rand() float64 {
-	return fastrandFloat(randseed1, uint64(ctx.id))
+	return fastrandFloat(ctx.emitter.contextSeed(), uint64(ctx.id))
 }
 
 // RandRange returns a pseudo-random value in [min, max] range.
@@ -54,7 +59,7 @@ func (ctx *SpawnContext) RandRange(min, max float64) float64 {
 }
 
 func (ctx *SpawnContext) RandUint() uint64 {
-	return fastrand(randseed1, uint64(ctx.id))
+	return fastrand(ctx.emitter.contextSeed(), uint64(ctx.id))
 }
 
 type UpdateContext struct {
@@ -94,7 +99,49 @@ type Template struct {
 	maxEmitBurst       uint8
 	emitBurstRangeSize uint16
 
-	needsRandBits uint8
+	needsRandBits uint16
+
+	gravity                gmath.Vec32
+	damping                float32
+	acceleration           float32
+	radialAcceleration     float32
+	tangentialAcceleration float32
+
+	particleMinTurnRate  float32
+	particleMaxTurnRate  float32
+	particleTurnRateStep float32
+	hasTurnRate          bool
+
+	physics physicsMode
+
+	// The lookup tables are allocated only when the curves are set.
+	colorGradient ColorGradient
+	colorLUT      *[curveLUTSize]graphics.ColorScale
+	scaleCurve    Curve
+	scaleLUT      *[curveLUTSize]float32
+	alphaFadeIn   float32
+	alphaFadeOut  float32
+
+	// numFrames is zero for the non-animated particles.
+	animation SpriteAnimation
+	frameCols int
+	numFrames int
+
+	particleMinSpin  float32
+	particleMaxSpin  float32
+	particleSpinStep float32
+	hasSpin          bool
+
+	space SimulationSpace
+
+	blendMode BlendMode
+	shader    *graphics.Shader
+
+	emissionShape   EmissionShape
+	shapeSampler    *shapeSampler
+	emitAlongNormal bool
+
+	subEmitters []SubEmitter
 
 	palette []graphics.ColorScale
 
@@ -127,6 +174,11 @@ func (tmpl *Template) Clone() *Template {
 		cloned.palette = make([]graphics.ColorScale, len(tmpl.palette))
 		copy(cloned.palette, tmpl.palette)
 	}
+	// The lookup tables are never modified, so they can be shared.
+	cloned.colorGradient.Stops = slices.Clone(tmpl.colorGradient.Stops)
+	cloned.scaleCurve.Keys = slices.Clone(tmpl.scaleCurve.Keys)
+	cloned.emissionShape.Points = slices.Clone(tmpl.emissionShape.Points)
+	cloned.subEmitters = slices.Clone(tmpl.subEmitters)
 	return &cloned
 }
 
@@ -156,6 +208,7 @@ func (tmpl *Template) SetUpdateScalingFunc(fn func(ctx UpdateContext) gmath.Vec3
 
 func (tmpl *Template) SetImage(img *ebiten.Image) {
 	tmpl.img = img
+	tmpl.updateAnimation()
 }
 
 func (tmpl *Template) SetEmitBurst(minAmount, maxAmount int) {
@@ -0,0 +1,502 @@
+package particle
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	graphics "github.com/quasilyte/ebitengine-graphics"
+	"github.com/quasilyte/gmath"
+)
+
+// TemplateDataVersion is the current [TemplateData] schema version.
+const TemplateDataVersion = 1
+
+// TemplateData is a serializable [Template] representation.
+//
+// It's intended to be stored as JSON, see [DecodeTemplate] and [EncodeTemplate].
+// All fields are optional, the missing fields get the [NewTemplate] defaults.
+//
+// The Go callbacks (like SetSpawnOffsetFunc), shaders and sub-emitters can't be serialized,
+// they should be assigned after the template is loaded.
+//
+// An example of the JSON document:
+//
+//	{
+//	  "version": 1,
+//	  "image": "smoke",
+//	  "lifetime": {"min": 1, "max": 2},
+//	  "speed": 40,
+//	  "direction": -1.57,
+//	  "spread": 0.5,
+//	  "burst": {"min": 2, "max": 4},
+//	  "emit_interval": 0.1,
+//	  "palette": ["aaaaaa", "cccccc80"],
+//	  "scale_curve": {"keys": [{"time": 0, "value": 0.5, "easing": "out"}, {"time": 1, "value": 2}]},
+//	  "alpha_fade": {"in": 0.1, "out": 0.5}
+//	}
+type TemplateData struct {
+	Version int `json:"version"`
+
+	// Image is a name that is resolved by the image lookup function.
+	// An empty name means "use the default white pixel image".
+	Image string `json:"image,omitempty"`
+
+	Lifetime     FloatRange   `json:"lifetime"`
+	Speed        FloatRange   `json:"speed"`
+	Scaling      ScalingRange `json:"scaling"`
+	Direction    float64      `json:"direction"`
+	Spread       float64      `json:"spread"`
+	Burst        IntRange     `json:"burst"`
+	EmitInterval float64      `json:"emit_interval"`
+
+	// Palette colors are "rrggbb" or "rrggbbaa" hex strings.
+	Palette []string `json:"palette,omitempty"`
+
+	Blend BlendMode `json:"blend,omitempty"`
+
+	Space SimulationSpace `json:"space,omitempty"`
+
+	Gravity                gmath.Vec  `json:"gravity"`
+	Damping                float64    `json:"damping,omitempty"`
+	Acceleration           float64    `json:"acceleration,omitempty"`
+	RadialAcceleration     float64    `json:"radial_acceleration,omitempty"`
+	TangentialAcceleration float64    `json:"tangential_acceleration,omitempty"`
+	TurnRate               FloatRange `json:"turn_rate"`
+	Spin                   FloatRange `json:"spin"`
+
+	Animation *SpriteAnimation `json:"animation,omitempty"`
+
+	ColorGradient []ColorStopData `json:"color_gradient,omitempty"`
+	ScaleCurve    *Curve          `json:"scale_curve,omitempty"`
+	AlphaFade     AlphaFadeData   `json:"alpha_fade"`
+
+	EmissionShape   *EmissionShape `json:"emission_shape,omitempty"`
+	EmitAlongNormal bool           `json:"emit_along_normal,omitempty"`
+}
+
+// ColorStopData is a serializable [ColorStop].
+type ColorStopData struct {
+	Time float32 `json:"time"`
+
+	// Color is a "rrggbb" or "rrggbbaa" hex string.
+	Color string `json:"color"`
+}
+
+// AlphaFadeData describes the [Template.SetAlphaFade] arguments.
+type AlphaFadeData struct {
+	In  float64 `json:"in,omitempty"`
+	Out float64 `json:"out,omitempty"`
+}
+
+// FloatRange is a [min, max] range.
+// In JSON, it can be written as a single number if min=max.
+type FloatRange struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// IntRange is a [min, max] range.
+// In JSON, it can be written as a single number if min=max.
+type IntRange struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
+
+// ScalingRange is a [min, max] particle scaling range.
+// In JSON, it can be written as a single number for a uniform scaling.
+type ScalingRange struct {
+	Min gmath.Vec `json:"min"`
+	Max gmath.Vec `json:"max"`
+}
+
+func (r FloatRange) MarshalJSON() ([]byte, error) {
+	if r.Min == r.Max {
+		return json.Marshal(r.Min)
+	}
+	type plain FloatRange
+	return json.Marshal(plain(r))
+}
+
+func (r *FloatRange) UnmarshalJSON(data []byte) error {
+	if !isJSONObject(data) {
+		if err := json.Unmarshal(data, &r.Min); err != nil {
+			return err
+		}
+		r.Max = r.Min
+		return nil
+	}
+	type plain FloatRange
+	return json.Unmarshal(data, (*plain)(r))
+}
+
+func (r IntRange) MarshalJSON() ([]byte, error) {
+	if r.Min == r.Max {
+		return json.Marshal(r.Min)
+	}
+	type plain IntRange
+	return json.Marshal(plain(r))
+}
+
+func (r *IntRange) UnmarshalJSON(data []byte) error {
+	if !isJSONObject(data) {
+		if err := json.Unmarshal(data, &r.Min); err != nil {
+			return err
+		}
+		r.Max = r.Min
+		return nil
+	}
+	type plain IntRange
+	return json.Unmarshal(data, (*plain)(r))
+}
+
+func (r ScalingRange) MarshalJSON() ([]byte, error) {
+	if r.Min == r.Max && r.Min.X == r.Min.Y {
+		return json.Marshal(r.Min.X)
+	}
+	type plain ScalingRange
+	return json.Marshal(plain(r))
+}
+
+func (r *ScalingRange) UnmarshalJSON(data []byte) error {
+	if !isJSONObject(data) {
+		var v float64
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		r.Min = gmath.Vec{X: v, Y: v}
+		r.Max = r.Min
+		return nil
+	}
+	type plain ScalingRange
+	return json.Unmarshal(data, (*plain)(r))
+}
+
+func isJSONObject(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) != 0 && data[0] == '{'
+}
+
+// NewTemplateData returns the data that describes the [NewTemplate] defaults.
+func NewTemplateData() TemplateData {
+	return TemplateData{
+		Version:      TemplateDataVersion,
+		Lifetime:     FloatRange{Min: 3, Max: 3},
+		Speed:        FloatRange{Min: 32, Max: 32},
+		Scaling:      ScalingRange{Min: gmath.Vec{X: 1, Y: 1}, Max: gmath.Vec{X: 1, Y: 1}},
+		Burst:        IntRange{Min: 1, Max: 1},
+		EmitInterval: 0.5,
+	}
+}
+
+// Validate reports all problems found in the data.
+// The returned error is nil if the data can be used to create a template.
+//
+// The Template setters panic on the invalid arguments,
+// so the data should be validated before being applied.
+func (d *TemplateData) Validate() error {
+	var errs []error
+	addErr := func(field, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", field, fmt.Sprintf(format, args...)))
+	}
+
+	if d.Version < 1 || d.Version > TemplateDataVersion {
+		addErr("version", "unsupported version %d", d.Version)
+	}
+	if d.Lifetime.Min < 0 || d.Lifetime.Max < d.Lifetime.Min {
+		addErr("lifetime", "invalid range [%v, %v]", d.Lifetime.Min, d.Lifetime.Max)
+	} else if int(d.Lifetime.Max*1000) > math.MaxUint16 {
+		addErr("lifetime", "max lifetime can't exceed ~65 seconds")
+	}
+	if d.Speed.Max < d.Speed.Min {
+		addErr("speed", "invalid range [%v, %v]", d.Speed.Min, d.Speed.Max)
+	}
+	if d.Scaling.Max.X < d.Scaling.Min.X || d.Scaling.Max.Y < d.Scaling.Min.Y {
+		addErr("scaling", "invalid range [%v, %v]", d.Scaling.Min, d.Scaling.Max)
+	}
+	if d.Burst.Min < 0 || d.Burst.Max < d.Burst.Min || d.Burst.Max > math.MaxUint8 {
+		addErr("burst", "invalid range [%v, %v] (should be within [0, 255])", d.Burst.Min, d.Burst.Max)
+	}
+	if d.EmitInterval <= 0 {
+		addErr("emit_interval", "should be positive")
+	}
+	for i, s := range d.Palette {
+		if _, err := parseHexColor(s); err != nil {
+			addErr(fmt.Sprintf("palette[%d]", i), "%v", err)
+		}
+	}
+	if d.Blend > BlendMultiply {
+		addErr("blend", "invalid blend mode value %d", d.Blend)
+	}
+	if d.Space > SpaceLocal {
+		addErr("space", "invalid simulation space value %d", d.Space)
+	}
+	if d.Damping < 0 {
+		addErr("damping", "can't be negative")
+	}
+	if d.TurnRate.Max < d.TurnRate.Min {
+		addErr("turn_rate", "invalid range [%v, %v]", d.TurnRate.Min, d.TurnRate.Max)
+	}
+	if d.Spin.Max < d.Spin.Min {
+		addErr("spin", "invalid range [%v, %v]", d.Spin.Min, d.Spin.Max)
+	}
+	if d.Animation != nil {
+		// The image size is unknown until the image is loaded,
+		// so the frames layout is checked later.
+		if err := d.Animation.validate(math.MaxInt32, math.MaxInt32); err != nil {
+			addErr("animation", "%v", err)
+		}
+	}
+	gradient := ColorGradient{Stops: make([]ColorStop, 0, len(d.ColorGradient))}
+	for i, s := range d.ColorGradient {
+		clr, err := parseHexColor(s.Color)
+		if err != nil {
+			addErr(fmt.Sprintf("color_gradient[%d]", i), "%v", err)
+		}
+		gradient.Stops = append(gradient.Stops, ColorStop{Time: s.Time, Color: clr})
+	}
+	if err := gradient.validate(); err != nil {
+		addErr("color_gradient", "%v", err)
+	}
+	if d.ScaleCurve != nil {
+		if err := d.ScaleCurve.validate(); err != nil {
+			addErr("scale_curve", "%v", err)
+		}
+	}
+	if d.AlphaFade.In < 0 || d.AlphaFade.In > 1 || d.AlphaFade.Out < 0 || d.AlphaFade.Out > 1 {
+		addErr("alpha_fade", "values should be in [0, 1] range")
+	}
+	if d.EmissionShape != nil {
+		if err := d.EmissionShape.validate(); err != nil {
+			addErr("emission_shape", "%v", err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// NewTemplateFromData creates a template using the provided data.
+//
+// The lookupImage function is used to resolve the image names.
+// It's not called for an empty image name.
+// A nil image returned without an error is reported as an error too.
+//
+// Unlike the Template setters, it returns an error instead of panicking.
+func NewTemplateFromData(d TemplateData, lookupImage func(name string) (*ebiten.Image, error)) (*Template, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+
+	tmpl := NewTemplate()
+	if d.Image != "" {
+		img, err := lookupImage(d.Image)
+		if err != nil {
+			return nil, fmt.Errorf("image: %w", err)
+		}
+		if img == nil {
+			return nil, fmt.Errorf("image: lookup returned nil for %q", d.Image)
+		}
+		tmpl.SetImage(img)
+	}
+
+	tmpl.SetParticleLifetimeRange(d.Lifetime.Min, d.Lifetime.Max)
+	tmpl.SetParticleSpeedRange(d.Speed.Min, d.Speed.Max)
+	tmpl.SetParticleScalingRange(d.Scaling.Min, d.Scaling.Max)
+	tmpl.SetParticleDirection(gmath.Rad(d.Direction), gmath.Rad(d.Spread))
+	tmpl.SetEmitBurst(d.Burst.Min, d.Burst.Max)
+	tmpl.SetEmitInterval(d.EmitInterval)
+
+	if len(d.Palette) != 0 {
+		palette := make([]graphics.ColorScale, len(d.Palette))
+		for i, s := range d.Palette {
+			palette[i], _ = parseHexColor(s)
+		}
+		tmpl.SetPalette(palette)
+	}
+
+	tmpl.SetBlendMode(d.Blend)
+	tmpl.SetSimulationSpace(d.Space)
+
+	tmpl.SetGravity(d.Gravity)
+	tmpl.SetDamping(d.Damping)
+	tmpl.SetParticleAcceleration(d.Acceleration)
+	tmpl.SetRadialAcceleration(d.RadialAcceleration)
+	tmpl.SetTangentialAcceleration(d.TangentialAcceleration)
+	tmpl.SetParticleTurnRateRange(gmath.Rad(d.TurnRate.Min), gmath.Rad(d.TurnRate.Max))
+	tmpl.SetParticleSpinRange(gmath.Rad(d.Spin.Min), gmath.Rad(d.Spin.Max))
+
+	if d.Animation != nil {
+		bounds := tmpl.img.Bounds()
+		if err := d.Animation.validate(bounds.Dx(), bounds.Dy()); err != nil {
+			return nil, fmt.Errorf("animation: %w", err)
+		}
+		tmpl.SetAnimation(*d.Animation)
+	}
+
+	if len(d.ColorGradient) != 0 {
+		stops := make([]ColorStop, len(d.ColorGradient))
+		for i, s := range d.ColorGradient {
+			clr, _ := parseHexColor(s.Color)
+			stops[i] = ColorStop{Time: s.Time, Color: clr}
+		}
+		tmpl.SetColorGradient(ColorGradient{Stops: stops})
+	}
+	if d.ScaleCurve != nil {
+		tmpl.SetScaleCurve(*d.ScaleCurve)
+	}
+	tmpl.SetAlphaFade(d.AlphaFade.In, d.AlphaFade.Out)
+
+	if d.EmissionShape != nil {
+		tmpl.SetEmissionShape(*d.EmissionShape)
+	}
+	tmpl.SetEmitAlongNormal(d.EmitAlongNormal)
+
+	return tmpl, nil
+}
+
+// ToData returns a serializable template representation.
+// The image name can't be inferred from the template,
+// so the caller needs to provide it.
+//
+// The colors are stored as hex strings, so their
+// components are rounded to the 1/255 steps.
+// The template stores most values as float32, they're converted
+// using the shortest decimal representation (0.3 stays 0.3).
+func (tmpl *Template) ToData(imageName string) TemplateData {
+	d := TemplateData{
+		Version: TemplateDataVersion,
+		Image:   imageName,
+		Lifetime: FloatRange{
+			Min: widenFloat32(tmpl.particleMinLifetime),
+			Max: widenFloat32(tmpl.particleMaxLifetime),
+		},
+		Speed: FloatRange{
+			Min: widenFloat32(tmpl.particleMinSpeed),
+			Max: widenFloat32(tmpl.particleMaxSpeed),
+		},
+		Scaling: ScalingRange{
+			Min: gmath.Vec{X: widenFloat32(tmpl.particleMinScaling.X), Y: widenFloat32(tmpl.particleMinScaling.Y)},
+			Max: gmath.Vec{X: widenFloat32(tmpl.particleMaxScaling.X), Y: widenFloat32(tmpl.particleMaxScaling.Y)},
+		},
+		Direction:    (tmpl.particleMinAngle + tmpl.particleMaxAngle) * 0.5,
+		Spread:       tmpl.particleMaxAngle - tmpl.particleMinAngle,
+		Burst:        IntRange{Min: int(tmpl.minEmitBurst), Max: int(tmpl.maxEmitBurst)},
+		EmitInterval: widenFloat32(tmpl.emitInterval),
+
+		Blend: tmpl.blendMode,
+		Space: tmpl.space,
+
+		Gravity:                gmath.Vec{X: widenFloat32(tmpl.gravity.X), Y: widenFloat32(tmpl.gravity.Y)},
+		Damping:                widenFloat32(tmpl.damping),
+		Acceleration:           widenFloat32(tmpl.acceleration),
+		RadialAcceleration:     widenFloat32(tmpl.radialAcceleration),
+		TangentialAcceleration: widenFloat32(tmpl.tangentialAcceleration),
+		TurnRate: FloatRange{
+			Min: widenFloat32(tmpl.particleMinTurnRate),
+			Max: widenFloat32(tmpl.particleMaxTurnRate),
+		},
+
+		Spin: FloatRange{
+			Min: widenFloat32(tmpl.particleMinSpin),
+			Max: widenFloat32(tmpl.particleMaxSpin),
+		},
+
+		AlphaFade: AlphaFadeData{
+			In:  widenFloat32(tmpl.alphaFadeIn),
+			Out: widenFloat32(tmpl.alphaFadeOut),
+		},
+		EmitAlongNormal: tmpl.emitAlongNormal,
+	}
+
+	if !isDefaultPalette(tmpl.palette) {
+		d.Palette = make([]string, len(tmpl.palette))
+		for i, clr := range tmpl.palette {
+			d.Palette[i] = formatHexColor(clr)
+		}
+	}
+	for _, s := range tmpl.colorGradient.Stops {
+		d.ColorGradient = append(d.ColorGradient, ColorStopData{
+			Time:  s.Time,
+			Color: formatHexColor(s.Color),
+		})
+	}
+	if tmpl.animation != (SpriteAnimation{}) {
+		a := tmpl.animation
+		d.Animation = &a
+	}
+	if len(tmpl.scaleCurve.Keys) != 0 {
+		c := tmpl.scaleCurve
+		d.ScaleCurve = &c
+	}
+	if tmpl.emissionShape.Kind != ShapePoint {
+		shape := tmpl.emissionShape
+		d.EmissionShape = &shape
+	}
+
+	return d
+}
+
+// DecodeTemplate parses the JSON-encoded [TemplateData] and creates a template from it.
+// The unknown JSON fields are reported as errors.
+//
+// See [NewTemplateFromData] for more info.
+func DecodeTemplate(data []byte, lookupImage func(name string) (*ebiten.Image, error)) (*Template, error) {
+	d := NewTemplateData()
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&d); err != nil {
+		return nil, err
+	}
+	return NewTemplateFromData(d, lookupImage)
+}
+
+// EncodeTemplate returns the JSON-encoded template data.
+// The result can be loaded back using [DecodeTemplate].
+//
+// See [Template.ToData] for more info.
+func EncodeTemplate(tmpl *Template, imageName string) ([]byte, error) {
+	return json.MarshalIndent(tmpl.ToData(imageName), "", "  ")
+}
+
+// widenFloat32 converts x to float64 preserving its shortest decimal form.
+// A plain float64(x) conversion would turn 0.3 into 0.30000001192092896.
+func widenFloat32(x float32) float64 {
+	v, _ := strconv.ParseFloat(strconv.FormatFloat(float64(x), 'g', -1, 32), 64)
+	return v
+}
+
+func isDefaultPalette(palette []graphics.ColorScale) bool {
+	return len(palette) == 1 && palette[0] == defaultPalette[0]
+}
+
+func parseHexColor(s string) (graphics.ColorScale, error) {
+	if len(s) != 6 && len(s) != 8 {
+		return graphics.ColorScale{}, fmt.Errorf("%q: expected rrggbb or rrggbbaa hex color", s)
+	}
+	if _, err := strconv.ParseUint(s, 16, 32); err != nil {
+		return graphics.ColorScale{}, fmt.Errorf("%q: invalid hex color", s)
+	}
+	clr := graphics.ParseRGB(s)
+	if len(s) == 8 {
+		a, _ := strconv.ParseUint(s[6:], 16, 8)
+		clr.A = uint8(a)
+	}
+	return graphics.ColorScaleFromColor(clr), nil
+}
+
+func formatHexColor(cs graphics.ColorScale) string {
+	clr := graphics.ColorScale{
+		R: gmath.Clamp(cs.R, 0, 1),
+		G: gmath.Clamp(cs.G, 0, 1),
+		B: gmath.Clamp(cs.B, 0, 1),
+		A: gmath.Clamp(cs.A, 0, 1),
+	}.Color()
+	if clr.A == 0xff {
+		return graphics.FormatRGB(clr)
+	}
+	return graphics.FormatRGBA(clr)
+}
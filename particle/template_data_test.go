@@ -0,0 +1,353 @@
+package particle
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	graphics "github.com/quasilyte/ebitengine-graphics"
+	"github.com/quasilyte/gmath"
+)
+
+func TestTemplateDataRoundTrip(t *testing.T) {
+	img := ebiten.NewImage(4, 4)
+	lookupImage := func(name string) (*ebiten.Image, error) {
+		if name != "smoke" {
+			t.Fatalf("unexpected image %q lookup", name)
+		}
+		return img, nil
+	}
+
+	// See TestTemplateDataDecimalRoundTrip for the values
+	// that are not exactly representable as float32.
+	tmpl := NewTemplate()
+	tmpl.SetImage(img)
+	tmpl.SetParticleLifetimeRange(0.5, 2)
+	tmpl.SetParticleSpeedRange(16, 64)
+	tmpl.SetParticleScalingRange(gmath.Vec{X: 0.5, Y: 0.25}, gmath.Vec{X: 2, Y: 4})
+	tmpl.SetParticleDirection(0.5, 1)
+	tmpl.SetEmitBurst(2, 8)
+	tmpl.SetEmitInterval(0.25)
+	tmpl.SetPalette([]graphics.ColorScale{
+		graphics.ColorScaleFromRGBA(0xff, 0x00, 0x00, 0xff),
+		graphics.ColorScaleFromRGBA(0x00, 0x80, 0xff, 0x80),
+	})
+	tmpl.SetBlendMode(BlendAdditive)
+	tmpl.SetSimulationSpace(SpaceLocal)
+	tmpl.SetGravity(gmath.Vec{X: 1.5, Y: 98})
+	tmpl.SetDamping(0.75)
+	tmpl.SetParticleAcceleration(-4)
+	tmpl.SetRadialAcceleration(8)
+	tmpl.SetTangentialAcceleration(-2.5)
+	tmpl.SetParticleTurnRateRange(-1, -1+255.0/128)
+	tmpl.SetParticleSpinRange(0, 255.0/64)
+	tmpl.SetColorGradient(ColorGradient{Stops: []ColorStop{
+		{Time: 0, Color: graphics.ColorScaleFromRGBA(0xff, 0xff, 0xff, 0xff)},
+		{Time: 0.5, Color: graphics.ColorScaleFromRGBA(0xff, 0x80, 0x00, 0xff)},
+		{Time: 1, Color: graphics.ColorScaleFromRGBA(0x40, 0x40, 0x40, 0x00)},
+	}})
+	tmpl.SetScaleCurve(Curve{Keys: []Keyframe{
+		{Time: 0, Value: 0.5, Easing: EaseOut},
+		{Time: 0.75, Value: 2, Easing: EaseStep},
+		{Time: 1, Value: 1},
+	}})
+	tmpl.SetAlphaFade(0.125, 0.5)
+	tmpl.SetEmissionShape(ArcShape(24, 0.5, 2, true))
+	tmpl.SetEmitAlongNormal(true)
+
+	encoded, err := EncodeTemplate(tmpl, "smoke")
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	decoded, err := DecodeTemplate(encoded, lookupImage)
+	if err != nil {
+		t.Fatalf("decode: %v\n%s", err, encoded)
+	}
+	reencoded, err := EncodeTemplate(decoded, "smoke")
+	if err != nil {
+		t.Fatalf("re-encode: %v", err)
+	}
+	if !bytes.Equal(encoded, reencoded) {
+		t.Fatalf("round trip mismatch:\nhave: %s\nwant: %s", reencoded, encoded)
+	}
+
+	// Check that the data is not only encoded, but applied as well.
+	if decoded.img != img {
+		t.Fatalf("the image is not assigned")
+	}
+	if len(decoded.palette) != 2 || decoded.palette[1] != tmpl.palette[1] {
+		t.Fatalf("palette:\nhave: %v\nwant: %v", decoded.palette, tmpl.palette)
+	}
+	if decoded.blendMode != BlendAdditive || decoded.space != SpaceLocal {
+		t.Fatalf("have blend=%v space=%v", decoded.blendMode, decoded.space)
+	}
+	if decoded.gravity != tmpl.gravity || decoded.damping != tmpl.damping {
+		t.Fatalf("have gravity=%v damping=%v", decoded.gravity, decoded.damping)
+	}
+	if len(decoded.colorGradient.Stops) != 3 || len(decoded.scaleCurve.Keys) != 3 {
+		t.Fatalf("the gradient or the curve is lost")
+	}
+	if decoded.emissionShape.Kind != ShapeArc || !decoded.emissionShape.Edge || !decoded.emitAlongNormal {
+		t.Fatalf("emission shape: %+v", decoded.emissionShape)
+	}
+}
+
+func TestTemplateDataDecimalRoundTrip(t *testing.T) {
+	input := TemplateData{
+		Version:                TemplateDataVersion,
+		Lifetime:               FloatRange{Min: 0.3, Max: 1.1},
+		Speed:                  FloatRange{Min: 0.1, Max: 12.7},
+		Scaling:                ScalingRange{Min: gmath.Vec{X: 0.3, Y: 0.1}, Max: gmath.Vec{X: 1.3, Y: 0.7}},
+		Burst:                  IntRange{Min: 1, Max: 3},
+		EmitInterval:           0.1,
+		Gravity:                gmath.Vec{X: 0.1, Y: 9.8},
+		Damping:                0.3,
+		Acceleration:           -0.7,
+		RadialAcceleration:     0.1,
+		TangentialAcceleration: 1.2,
+		TurnRate:               FloatRange{Min: -0.3, Max: 1.2},
+		Spin:                   FloatRange{Min: 0.3, Max: 0.7},
+		AlphaFade:              AlphaFadeData{In: 0.1, Out: 0.3},
+	}
+
+	tmpl, err := NewTemplateFromData(input, nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if have := tmpl.ToData(""); !reflect.DeepEqual(have, input) {
+		t.Fatalf("ToData:\nhave: %+v\nwant: %+v", have, input)
+	}
+
+	encoded, err := EncodeTemplate(tmpl, "")
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	// Something like 0.30000001192092896 would be there otherwise.
+	if bytes.Contains(encoded, []byte("0000")) {
+		t.Fatalf("the float32 values are not rounded:\n%s", encoded)
+	}
+
+	decoded, err := DecodeTemplate(encoded, nil)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	reencoded, err := EncodeTemplate(decoded, "")
+	if err != nil {
+		t.Fatalf("re-encode: %v", err)
+	}
+	if !bytes.Equal(encoded, reencoded) {
+		t.Fatalf("round trip mismatch:\nhave: %s\nwant: %s", reencoded, encoded)
+	}
+}
+
+func TestTemplateDataDefaults(t *testing.T) {
+	d := NewTemplateData()
+	if err := d.Validate(); err != nil {
+		t.Fatalf("the default data is invalid: %v", err)
+	}
+
+	tmpl, err := DecodeTemplate([]byte(`{"version": 1}`), nil)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	have, err := EncodeTemplate(tmpl, "")
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	want, err := EncodeTemplate(NewTemplate(), "")
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if !bytes.Equal(have, want) {
+		t.Fatalf("defaults mismatch:\nhave: %s\nwant: %s", have, want)
+	}
+}
+
+func TestTemplateDataValidate(t *testing.T) {
+	tests := []struct {
+		field  string
+		modify func(d *TemplateData)
+	}{
+		{"version", func(d *TemplateData) { d.Version = 0 }},
+		{"version", func(d *TemplateData) { d.Version = TemplateDataVersion + 1 }},
+		{"lifetime", func(d *TemplateData) { d.Lifetime = FloatRange{Min: -1, Max: 1} }},
+		{"lifetime", func(d *TemplateData) { d.Lifetime = FloatRange{Min: 2, Max: 1} }},
+		{"lifetime", func(d *TemplateData) { d.Lifetime = FloatRange{Min: 1, Max: 100} }},
+		{"speed", func(d *TemplateData) { d.Speed = FloatRange{Min: 2, Max: 1} }},
+		{"scaling", func(d *TemplateData) { d.Scaling.Min = gmath.Vec{X: 1, Y: 3} }},
+		{"burst", func(d *TemplateData) { d.Burst = IntRange{Min: -1, Max: 1} }},
+		{"burst", func(d *TemplateData) { d.Burst = IntRange{Min: 2, Max: 1} }},
+		{"burst", func(d *TemplateData) { d.Burst = IntRange{Min: 1, Max: 256} }},
+		{"emit_interval", func(d *TemplateData) { d.EmitInterval = 0 }},
+		{"palette[1]", func(d *TemplateData) { d.Palette = []string{"ffffff", "fff"} }},
+		{"palette[0]", func(d *TemplateData) { d.Palette = []string{"gggggg"} }},
+		{"blend", func(d *TemplateData) { d.Blend = BlendMultiply + 1 }},
+		{"space", func(d *TemplateData) { d.Space = SpaceLocal + 1 }},
+		{"damping", func(d *TemplateData) { d.Damping = -1 }},
+		{"turn_rate", func(d *TemplateData) { d.TurnRate = FloatRange{Min: 1, Max: -1} }},
+		{"spin", func(d *TemplateData) { d.Spin = FloatRange{Min: 1, Max: -1} }},
+		{"animation", func(d *TemplateData) { d.Animation = &SpriteAnimation{FrameWidth: -1} }},
+		{"color_gradient[0]", func(d *TemplateData) {
+			d.ColorGradient = []ColorStopData{{Time: 0, Color: "red"}}
+		}},
+		{"color_gradient", func(d *TemplateData) {
+			d.ColorGradient = []ColorStopData{{Time: 1, Color: "ffffff"}, {Time: 0, Color: "000000"}}
+		}},
+		{"scale_curve", func(d *TemplateData) {
+			d.ScaleCurve = &Curve{Keys: []Keyframe{{Time: 2, Value: 1}}}
+		}},
+		{"scale_curve", func(d *TemplateData) {
+			d.ScaleCurve = &Curve{Keys: []Keyframe{{Time: 0, Value: 1, Easing: EaseStep + 1}}}
+		}},
+		{"alpha_fade", func(d *TemplateData) { d.AlphaFade = AlphaFadeData{In: -0.5} }},
+		{"alpha_fade", func(d *TemplateData) { d.AlphaFade = AlphaFadeData{Out: 1.5} }},
+		{"emission_shape", func(d *TemplateData) { d.EmissionShape = &EmissionShape{Kind: ShapeCircle, Radius: -1} }},
+		{"emission_shape", func(d *TemplateData) { d.EmissionShape = &EmissionShape{Kind: ShapeLine} }},
+		{"emission_shape", func(d *TemplateData) { d.EmissionShape = &EmissionShape{Kind: ShapePolygon + 1} }},
+	}
+
+	for i, test := range tests {
+		d := NewTemplateData()
+		test.modify(&d)
+		err := d.Validate()
+		if err == nil {
+			t.Errorf("test%d: %s: expected a validation error", i, test.field)
+			continue
+		}
+		if !strings.HasPrefix(err.Error(), test.field+": ") {
+			t.Errorf("test%d: error is not about %s: %v", i, test.field, err)
+		}
+		// The setters panic on the invalid values,
+		// the data-driven API should report an error instead.
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Errorf("test%d: %s: unexpected panic: %v", i, test.field, r)
+				}
+			}()
+			tmpl, err := NewTemplateFromData(d, nil)
+			if err == nil || tmpl != nil {
+				t.Errorf("test%d: %s: NewTemplateFromData accepted invalid data", i, test.field)
+			}
+		}()
+	}
+}
+
+func TestTemplateDataValidateAllErrors(t *testing.T) {
+	d := NewTemplateData()
+	d.Speed = FloatRange{Min: 2, Max: 1}
+	d.Damping = -1
+	d.Palette = []string{"?"}
+
+	err := d.Validate()
+	if err == nil {
+		t.Fatal("expected a validation error")
+	}
+	for _, field := range []string{"speed", "damping", "palette[0]"} {
+		if !strings.Contains(err.Error(), field+": ") {
+			t.Errorf("the %s error is not reported: %v", field, err)
+		}
+	}
+}
+
+func TestDecodeTemplateErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{`{"version": 2}`, "version: unsupported version 2"},
+		{`{"version": -1}`, "version: unsupported version -1"},
+		{`{"version": 1, "speedd": 10}`, `unknown field "speedd"`},
+		{`{"version": 1, "blend": "screen"}`, "screen"},
+		{`{"version": 1, "scale_curve": {"keys": [{"easing": "bounce"}]}}`, "bounce"},
+		{`{"version": 1, "emission_shape": {"kind": "star"}}`, "star"},
+		{`{"version": 1`, "unexpected EOF"},
+	}
+
+	for _, test := range tests {
+		tmpl, err := DecodeTemplate([]byte(test.input), nil)
+		if err == nil {
+			t.Errorf("%s: expected an error", test.input)
+			continue
+		}
+		if tmpl != nil {
+			t.Errorf("%s: a template is returned with an error", test.input)
+		}
+		if !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: error %q doesn't contain %q", test.input, err, test.err)
+		}
+	}
+}
+
+func TestDecodeTemplateImageLookup(t *testing.T) {
+	errNotFound := errors.New("not found")
+	numLookups := 0
+	lookupImage := func(name string) (*ebiten.Image, error) {
+		numLookups++
+		return nil, errNotFound
+	}
+
+	// The empty image name is not resolved.
+	if _, err := DecodeTemplate([]byte(`{"version": 1}`), lookupImage); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if numLookups != 0 {
+		t.Fatalf("lookupImage is called for an empty image name")
+	}
+
+	tmpl, err := DecodeTemplate([]byte(`{"version": 1, "image": "missing"}`), lookupImage)
+	if numLookups != 1 {
+		t.Fatalf("lookupImage is called %d times, want 1", numLookups)
+	}
+	if err == nil || tmpl != nil {
+		t.Fatalf("expected an error, have tmpl=%v err=%v", tmpl, err)
+	}
+	if !errors.Is(err, errNotFound) {
+		t.Fatalf("the lookup error is not wrapped: %v", err)
+	}
+	if !strings.HasPrefix(err.Error(), "image: ") {
+		t.Fatalf("unexpected error message: %v", err)
+	}
+
+	// A nil image without an error is not accepted either.
+	lookupNil := func(name string) (*ebiten.Image, error) {
+		return nil, nil
+	}
+	input := `{"version": 1, "image": "missing", "animation": {"frame_width": 8, "frame_height": 8}}`
+	tmpl, err = DecodeTemplate([]byte(input), lookupNil)
+	if err == nil || tmpl != nil {
+		t.Fatalf("expected an error, have tmpl=%v err=%v", tmpl, err)
+	}
+	if !strings.HasPrefix(err.Error(), "image: lookup returned nil") {
+		t.Fatalf("unexpected error message: %v", err)
+	}
+}
+
+func TestDecodeTemplateAnimationImageSize(t *testing.T) {
+	img := ebiten.NewImage(16, 8)
+	lookupImage := func(name string) (*ebiten.Image, error) {
+		return img, nil
+	}
+
+	// The frames layout can only be checked after the image is resolved.
+	input := `{"version": 1, "image": "sheet", "animation": {"frame_width": 8, "frame_height": 16}}`
+	tmpl, err := DecodeTemplate([]byte(input), lookupImage)
+	if err == nil || tmpl != nil {
+		t.Fatalf("expected an error, have tmpl=%v err=%v", tmpl, err)
+	}
+	if !strings.HasPrefix(err.Error(), "animation: ") {
+		t.Fatalf("unexpected error message: %v", err)
+	}
+
+	input = `{"version": 1, "image": "sheet", "animation": {"frame_width": 8, "frame_height": 8}}`
+	tmpl, err = DecodeTemplate([]byte(input), lookupImage)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if tmpl.animation.FrameWidth != 8 {
+		t.Fatalf("the animation is not assigned")
+	}
+}
@@ -0,0 +1,126 @@
+package graphics
+
+import (
+	"github.com/quasilyte/gmath"
+)
+
+// HitTester is implemented by the objects that can be picked.
+//
+// All built-in shapes that have an area implement it:
+// [Rect], [Circle], [Sprite], [Label] and [Container].
+// The objects that don't implement this interface are never hit.
+//
+// See [SceneDrawer.HitTest] and [Layer.HitTest].
+type HitTester interface {
+	// ContainsPoint reports whether the object shape contains the pos.
+	// The pos is in the same coordinates as the object Pos.
+	//
+	// An invisible object should not contain any points.
+	ContainsPoint(pos gmath.Vec) bool
+}
+
+// hitTestLayer is implemented by the layers that support the picking.
+type hitTestLayer interface {
+	// appendHits appends the layer objects that contain the pos to dst.
+	// The pos is in the dst image coordinates, the opts are
+	// the same as the layer would get in DrawWithOptions.
+	//
+	// The topmost objects go first.
+	appendHits(dst []Object, pos gmath.Vec, opts DrawOptions) []Object
+}
+
+// HitTest appends the objects that contain the screenPos to dst.
+// The topmost objects go first: the layers are visited from the last to the first
+// and the objects inside every layer are visited in the reversed draw order.
+//
+// The screenPos is in screen coordinates (like a cursor position).
+// A nil camera means "the default camera" (see [NewSceneDrawer]).
+// The point outside of the camera viewport rect can't hit anything.
+// The layers that are excluded by the camera layer mask are ignored.
+//
+// These layers support the hit testing: [Layer], [StaticLayer]
+// and [ParallaxLayer] (if its wrapped layer does).
+// The objects should implement [HitTester] interface to be pickable.
+func (d *SceneDrawer) HitTest(dst []Object, screenPos gmath.Vec, camera *Camera) []Object {
+	if camera == nil {
+		camera = d.defaultCamera[0].c
+	}
+	if !camera.areaRect.Contains(screenPos) {
+		return dst
+	}
+
+	// This should be consistent with the Draw method:
+	// the camera with an offscreen buffer has its own (0, 0) origin.
+	pos := screenPos
+	if camera.areaRect != d.viewportRect || camera.pp != nil {
+		pos = pos.Sub(camera.areaRect.Min)
+	}
+	options := DrawOptions{
+		Offset: camera.getDrawOffset(),
+	}
+
+	for i := len(d.layers) - 1; i >= 0; i-- {
+		if i < 64 {
+			if uint64(1<<i)&camera.layerMask == 0 {
+				continue
+			}
+		}
+		l, ok := d.layers[i].(hitTestLayer)
+		if !ok {
+			continue
+		}
+		dst = l.appendHits(dst, pos, options)
+	}
+
+	return dst
+}
+
+// HitTest appends the layer objects that contain the pos to dst.
+// The topmost (the last added) objects go first.
+//
+// The pos is in world coordinates.
+// The objects should implement [HitTester] interface to be pickable.
+//
+// To perform a picking using the screen coordinates,
+// use [SceneDrawer.HitTest].
+func (l *Layer) HitTest(dst []Object, pos gmath.Vec) []Object {
+	return l.appendHits(dst, pos, DrawOptions{})
+}
+
+func (l *Layer) appendHits(dst []Object, pos gmath.Vec, opts DrawOptions) []Object {
+	localPos := opts.inverseTransformPos(pos)
+	for i := len(l.objects) - 1; i >= 0; i-- {
+		o := l.objects[i]
+		if hitObject(o, localPos) {
+			dst = append(dst, o)
+		}
+	}
+	return dst
+}
+
+func (l *StaticLayer) appendHits(dst []Object, pos gmath.Vec, _ DrawOptions) []Object {
+	// The static layer objects ignore the camera transformation.
+	for i := len(l.objects) - 1; i >= 0; i-- {
+		o, ok := l.objects[i].(Object)
+		if ok && hitObject(o, pos) {
+			dst = append(dst, o)
+		}
+	}
+	return dst
+}
+
+func (l *ParallaxLayer) appendHits(dst []Object, pos gmath.Vec, opts DrawOptions) []Object {
+	wrapped, ok := l.layer.(hitTestLayer)
+	if !ok {
+		return dst
+	}
+	return wrapped.appendHits(dst, pos, l.adjustOptions(opts))
+}
+
+func hitObject(o Object, pos gmath.Vec) bool {
+	if o.IsDisposed() {
+		return false
+	}
+	h, ok := o.(HitTester)
+	return ok && h.ContainsPoint(pos)
+}
@@ -0,0 +1,197 @@
+package graphics
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/quasilyte/gmath"
+)
+
+func newTestRect(x, y, size float64) *Rect {
+	r := NewRect(size, size)
+	r.SetCentered(false)
+	r.Pos.Offset = gmath.Vec{X: x, Y: y}
+	return r
+}
+
+func TestSpriteContainsPointRotated(t *testing.T) {
+	s := NewSprite()
+	s.SetImage(ebiten.NewImage(20, 10))
+	s.Pos.Offset = gmath.Vec{X: 100, Y: 100}
+	rotation := gmath.Rad(math.Pi / 4)
+	s.Rotation = &rotation
+
+	// toWorld maps the sprite-local (centered) point to the world coordinates.
+	toWorld := func(local gmath.Vec) gmath.Vec {
+		return local.Rotated(rotation).Add(s.Pos.Offset)
+	}
+
+	tests := []struct {
+		name  string
+		local gmath.Vec
+		want  bool
+	}{
+		{"center", gmath.Vec{}, true},
+		{"inside bottom-right corner", gmath.Vec{X: 9.5, Y: 4.5}, true},
+		{"outside bottom-right corner", gmath.Vec{X: 10.5, Y: 5.5}, false},
+		{"inside top-left corner", gmath.Vec{X: -9.5, Y: -4.5}, true},
+		{"outside top-left corner", gmath.Vec{X: -10.5, Y: -5.5}, false},
+		{"inside top-right corner", gmath.Vec{X: 9.5, Y: -4.5}, true},
+		{"outside top-right corner", gmath.Vec{X: 9.5, Y: -5.5}, false},
+		{"outside the right edge", gmath.Vec{X: 10.5}, false},
+	}
+	for _, test := range tests {
+		pos := toWorld(test.local)
+		if have := s.ContainsPoint(pos); have != test.want {
+			t.Errorf("%s: ContainsPoint(%v): have %v, want %v", test.name, pos, have, test.want)
+		}
+	}
+
+	// This point is inside the unrotated frame (and inside the AABB),
+	// but it's outside of the rotated one.
+	if s.ContainsPoint(gmath.Vec{X: 109.9, Y: 104.9}) {
+		t.Errorf("rotated sprite contains the unrotated frame corner")
+	}
+
+	s.SetVisibility(false)
+	if s.ContainsPoint(s.Pos.Offset) {
+		t.Errorf("invisible sprite contains a point")
+	}
+}
+
+func TestCircleContainsPoint(t *testing.T) {
+	CompileShaders()
+
+	c := NewCircle(10)
+	c.Pos.Offset = gmath.Vec{X: 50, Y: 50}
+
+	tests := []struct {
+		pos  gmath.Vec
+		want bool
+	}{
+		{gmath.Vec{X: 50, Y: 50}, true},
+		{gmath.Vec{X: 60, Y: 50}, true},
+		{gmath.Vec{X: 50, Y: 40}, true},
+		{gmath.Vec{X: 60.01, Y: 50}, false},
+		{gmath.Vec{X: 50, Y: 39.99}, false},
+		{gmath.Vec{X: 57, Y: 57}, true},
+		{gmath.Vec{X: 58, Y: 58}, false},
+	}
+	for _, test := range tests {
+		if have := c.ContainsPoint(test.pos); have != test.want {
+			t.Errorf("centered: ContainsPoint(%v): have %v, want %v", test.pos, have, test.want)
+		}
+	}
+
+	// A non-centered circle Pos is its bounding box top-left corner.
+	c.SetCentered(false)
+	if !c.ContainsPoint(gmath.Vec{X: 60, Y: 70}) {
+		t.Errorf("non-centered: the circle bottom edge is not hit")
+	}
+	if c.ContainsPoint(gmath.Vec{X: 51, Y: 51}) {
+		t.Errorf("non-centered: the bounding box corner is hit")
+	}
+}
+
+func TestSceneDrawerHitTest(t *testing.T) {
+	CompileShaders()
+
+	bottom := newTestRect(0, 0, 20)
+	middle1 := newTestRect(5, 5, 20)
+	middle2 := newTestRect(-5, -5, 20)
+	containerRect := newTestRect(0, 0, 20)
+	containerCircle := NewCircle(5)
+	container := NewContainer()
+	container.Pos.Offset = gmath.Vec{X: 5, Y: 5}
+	container.AddChild(containerRect)
+	container.AddChild(containerCircle)
+	top := newTestRect(100, 100, 20)
+
+	layer0 := NewLayer()
+	layer0.AddChild(bottom)
+	layer1 := NewLayer()
+	layer1.AddChild(middle1)
+	layer1.AddChild(middle2)
+	layer1.AddChild(container)
+	layer2 := NewLayer()
+	layer2.AddChild(top)
+
+	d := NewSceneDrawer([]SceneLayerDrawer{layer0, layer1, layer2})
+	viewport := gmath.Rect{Max: gmath.Vec{X: 320, Y: 240}}
+	d.SetViewportRect(viewport)
+	camera := NewCamera()
+	camera.SetViewportRect(viewport)
+	camera.SetOffset(gmath.Vec{X: -50, Y: -50})
+	d.AddCamera(camera)
+
+	// World (8, 8) is on the screen at (58, 58).
+	screenPos := gmath.Vec{X: 58, Y: 58}
+	have := d.HitTest(nil, screenPos, camera)
+	want := []Object{container, middle2, middle1, bottom}
+	if !slices.Equal(have, want) {
+		t.Fatalf("hits:\nhave: %v\nwant: %v", have, want)
+	}
+
+	// The container children are ordered topmost-first too.
+	containerHits := container.HitTest(nil, gmath.Vec{X: 8, Y: 8})
+	wantContainerHits := []Object{containerCircle, containerRect}
+	if !slices.Equal(containerHits, wantContainerHits) {
+		t.Fatalf("container hits:\nhave: %v\nwant: %v", containerHits, wantContainerHits)
+	}
+
+	// The layers excluded by the camera layer mask are ignored.
+	camera.SetLayerMask(0b101)
+	have = d.HitTest(nil, screenPos, camera)
+	if !slices.Equal(have, []Object{bottom}) {
+		t.Fatalf("masked hits: %v", have)
+	}
+	have = d.HitTest(nil, gmath.Vec{X: 160, Y: 160}, camera)
+	if !slices.Equal(have, []Object{top}) {
+		t.Fatalf("masked hits: %v", have)
+	}
+	camera.SetLayerMask(^uint64(0))
+
+	// A point outside of the camera viewport can't hit anything.
+	camera.SetViewportRect(gmath.Rect{Min: gmath.Vec{X: 100, Y: 100}, Max: gmath.Vec{X: 200, Y: 200}})
+	if have := d.HitTest(nil, screenPos, camera); len(have) != 0 {
+		t.Fatalf("hits outside of the viewport: %v", have)
+	}
+}
+
+func TestContainerHitTestHidden(t *testing.T) {
+	r := newTestRect(0, 0, 20)
+	c := NewContainer()
+	c.AddChild(r)
+	layer := NewLayer()
+	layer.AddChild(c)
+
+	pos := gmath.Vec{X: 10, Y: 10}
+	if have := layer.HitTest(nil, pos); !slices.Equal(have, []Object{c}) {
+		t.Fatalf("visible container hits: %v", have)
+	}
+
+	c.SetVisibility(false)
+	if have := layer.HitTest(nil, pos); len(have) != 0 {
+		t.Fatalf("invisible container hits: %v", have)
+	}
+	if c.HitTest(nil, pos) != nil {
+		t.Fatal("invisible container children are hit")
+	}
+
+	c.SetVisibility(true)
+	c.SetAlpha(0)
+	if have := layer.HitTest(nil, pos); len(have) != 0 {
+		t.Fatalf("zero-alpha container hits: %v", have)
+	}
+	if c.ContainsPoint(pos) {
+		t.Fatal("zero-alpha container contains a point")
+	}
+
+	c.SetAlpha(1)
+	r.SetVisibility(false)
+	if have := layer.HitTest(nil, pos); len(have) != 0 {
+		t.Fatalf("container with invisible children hits: %v", have)
+	}
+}
@@ -0,0 +1,38 @@
+package graphics
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// pooledBuf is a lazily allocated offscreen buffer.
+//
+// The underlying image is re-used as long as it's big enough,
+// the requested size is provided as a cached sub-image.
+type pooledBuf struct {
+	img *ebiten.Image
+
+	// sub is a cached sub-image of img.
+	sub *ebiten.Image
+}
+
+// get returns the buffer of the specified size.
+// It's up to the caller to clear it.
+func (buf *pooledBuf) get(size image.Point) *ebiten.Image {
+	if buf.img != nil {
+		imgSize := buf.img.Bounds().Size()
+		if imgSize.X < size.X || imgSize.Y < size.Y {
+			buf.img.Deallocate()
+			buf.img = nil
+		}
+	}
+	if buf.img == nil {
+		buf.img = ebiten.NewImage(size.X, size.Y)
+		buf.sub = nil
+	}
+	if buf.sub == nil || buf.sub.Bounds().Size() != size {
+		buf.sub = buf.img.SubImage(image.Rectangle{Max: size}).(*ebiten.Image)
+	}
+	return buf.sub
+}
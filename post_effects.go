@@ -0,0 +1,425 @@
+package graphics
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/quasilyte/ebitengine-graphics/internal/cache"
+	"github.com/quasilyte/gmath"
+)
+
+// This file contains the built-in post-processing effects.
+//
+// Every effect implements the [PostProcessor] interface,
+// so it can be used with [Camera.SetPostProcessor],
+// [SceneDrawer.SetLayerPostProcessors] and [PostProcessorChain].
+//
+// The effect parameters are stored as the underlying [Shader] uniforms.
+// You need to call [CompileShaders] before creating any of the effects.
+
+var (
+	blurDirectionHorizontal = []float32{1, 0}
+	blurDirectionVertical   = []float32{0, 1}
+
+	toneMatrixGrayscale = []float32{
+		0.299, 0.299, 0.299,
+		0.587, 0.587, 0.587,
+		0.114, 0.114, 0.114,
+	}
+	toneMatrixSepia = []float32{
+		0.393, 0.349, 0.272,
+		0.769, 0.686, 0.534,
+		0.189, 0.168, 0.131,
+	}
+)
+
+// maxBlurRadius is a blur shader loop limit.
+const maxBlurRadius = 16
+
+// postEffect is a base for the simple single-pass effects.
+type postEffect struct {
+	shader *Shader
+}
+
+func newPostEffect(compiled *ebiten.Shader) postEffect {
+	requireShaders()
+	return postEffect{shader: NewShader(compiled)}
+}
+
+func (e *postEffect) PostProcess(dst, src *ebiten.Image, opts DrawOptions) {
+	drawPostEffect(dst, src, e.shader, opts)
+}
+
+func (e *postEffect) getFloat(key string) float64 {
+	return float64(e.shader.GetValue(key).(float32))
+}
+
+// BlurEffect is a gaussian blur post-processor.
+//
+// It's implemented as a two-pass (separable) blur,
+// so it needs an extra offscreen buffer.
+type BlurEffect struct {
+	postEffect
+
+	buf pooledBuf
+}
+
+// NewBlurEffect returns a blur effect of the specified radius.
+// See [BlurEffect.SetRadius].
+func NewBlurEffect(radius float64) *BlurEffect {
+	e := &BlurEffect{postEffect: newPostEffect(cache.Global.BlurShader)}
+	e.SetRadius(radius)
+	return e
+}
+
+// GetRadius returns the current blur radius.
+// Use SetRadius to change it.
+func (e *BlurEffect) GetRadius() float64 { return e.getFloat("Radius") }
+
+// SetRadius changes the blur radius (in pixels).
+// Use GetRadius to retrieve the current value.
+//
+// The radius is clamped to [0, 16] range.
+func (e *BlurEffect) SetRadius(r float64) {
+	e.shader.SetFloatValue("Radius", float32(gmath.Clamp(r, 0, maxBlurRadius)))
+}
+
+func (e *BlurEffect) PostProcess(dst, src *ebiten.Image, opts DrawOptions) {
+	tmp := e.buf.get(src.Bounds().Size())
+	tmp.Clear()
+	drawBlurPasses(dst, tmp, src, e.shader, opts)
+}
+
+// BloomEffect makes the bright parts of the image glow.
+//
+// The bright parts are extracted, blurred and then
+// added on top of the original image.
+type BloomEffect struct {
+	brightPass *Shader
+	blur       *Shader
+
+	intensity float32
+
+	bufs [2]pooledBuf
+}
+
+// NewBloomEffect returns a bloom effect.
+//
+// By default, a bloom has these properties:
+// * Threshold is 0.7
+// * Radius is 8
+// * Intensity is 1
+func NewBloomEffect() *BloomEffect {
+	requireShaders()
+	e := &BloomEffect{
+		brightPass: NewShader(cache.Global.BrightPassShader),
+		blur:       NewShader(cache.Global.BlurShader),
+		intensity:  1,
+	}
+	e.SetThreshold(0.7)
+	e.SetRadius(8)
+	return e
+}
+
+// GetThreshold returns the current brightness threshold.
+// Use SetThreshold to change it.
+func (e *BloomEffect) GetThreshold() float64 {
+	return float64(e.brightPass.GetValue("Threshold").(float32))
+}
+
+// SetThreshold changes the brightness threshold.
+// Only the colors whose brightest channel exceeds this
+// value will glow. The threshold is in [0, 1] range.
+func (e *BloomEffect) SetThreshold(threshold float64) {
+	e.brightPass.SetFloatValue("Threshold", float32(threshold))
+}
+
+// GetRadius returns the current glow blur radius.
+// Use SetRadius to change it.
+func (e *BloomEffect) GetRadius() float64 {
+	return float64(e.blur.GetValue("Radius").(float32))
+}
+
+// SetRadius changes the glow blur radius (in pixels).
+// The radius is clamped to [0, 16] range.
+func (e *BloomEffect) SetRadius(r float64) {
+	e.blur.SetFloatValue("Radius", float32(gmath.Clamp(r, 0, maxBlurRadius)))
+}
+
+// GetIntensity returns the current glow intensity.
+// Use SetIntensity to change it.
+func (e *BloomEffect) GetIntensity() float64 { return float64(e.intensity) }
+
+// SetIntensity changes the glow intensity multiplier.
+func (e *BloomEffect) SetIntensity(intensity float64) { e.intensity = float32(intensity) }
+
+func (e *BloomEffect) PostProcess(dst, src *ebiten.Image, opts DrawOptions) {
+	size := src.Bounds().Size()
+	bright := e.bufs[0].get(size)
+	tmp := e.bufs[1].get(size)
+
+	// Draw the original image first.
+	{
+		var drawOptions ebiten.DrawImageOptions
+		if opts.Blend != nil {
+			drawOptions.Blend = *opts.Blend
+		}
+		drawOptions.GeoM.Translate(opts.Offset.X, opts.Offset.Y)
+		dst.DrawImage(src, &drawOptions)
+	}
+
+	bright.Clear()
+	drawPostEffect(bright, src, e.brightPass, DrawOptions{})
+	tmp.Clear()
+	// The bright buffer is consumed by the first blur pass,
+	// so it can be reused as the second pass output.
+	glow := bright
+	drawBlurPasses(glow, tmp, bright, e.blur, DrawOptions{Blend: &ebiten.BlendCopy})
+
+	// Add the glow on top of it.
+	var drawOptions ebiten.DrawImageOptions
+	drawOptions.Blend = ebiten.BlendLighter
+	drawOptions.ColorScale.Scale(e.intensity, e.intensity, e.intensity, e.intensity)
+	drawOptions.GeoM.Translate(opts.Offset.X, opts.Offset.Y)
+	dst.DrawImage(glow, &drawOptions)
+}
+
+// VignetteEffect darkens the image edges.
+type VignetteEffect struct {
+	postEffect
+}
+
+// NewVignetteEffect returns a vignette effect.
+//
+// By default, a vignette has these properties:
+// * Radius is 0.5
+// * Softness is 0.5
+// * Strength is 1
+func NewVignetteEffect() *VignetteEffect {
+	e := &VignetteEffect{postEffect: newPostEffect(cache.Global.VignetteShader)}
+	e.SetRadius(0.5)
+	e.SetSoftness(0.5)
+	e.SetStrength(1)
+	return e
+}
+
+// GetRadius returns the current vignette radius.
+// Use SetRadius to change it.
+func (e *VignetteEffect) GetRadius() float64 { return e.getFloat("Radius") }
+
+// SetRadius changes the radius of the unaffected area.
+// The radius is normalized: 0 is the image center, 1 is the image corner.
+func (e *VignetteEffect) SetRadius(r float64) { e.shader.SetFloatValue("Radius", float32(r)) }
+
+// GetSoftness returns the current vignette softness.
+// Use SetSoftness to change it.
+func (e *VignetteEffect) GetSoftness() float64 { return e.getFloat("Softness") }
+
+// SetSoftness changes the length of the darkening gradient.
+// It uses the same normalized units as the radius.
+func (e *VignetteEffect) SetSoftness(softness float64) {
+	e.shader.SetFloatValue("Softness", float32(softness))
+}
+
+// GetStrength returns the current vignette strength.
+// Use SetStrength to change it.
+func (e *VignetteEffect) GetStrength() float64 { return e.getFloat("Strength") }
+
+// SetStrength changes the darkening strength.
+// 0 means no darkening, 1 means the corners are completely black.
+func (e *VignetteEffect) SetStrength(strength float64) {
+	e.shader.SetFloatValue("Strength", float32(strength))
+}
+
+// ChromaticAberrationEffect shifts the red and blue channels
+// in the opposite directions.
+// The shift grows from the image center towards its edges.
+type ChromaticAberrationEffect struct {
+	postEffect
+}
+
+// NewChromaticAberrationEffect returns a chromatic aberration effect
+// with the specified max channel offset.
+// See [ChromaticAberrationEffect.SetOffset].
+func NewChromaticAberrationEffect(offset float64) *ChromaticAberrationEffect {
+	e := &ChromaticAberrationEffect{postEffect: newPostEffect(cache.Global.ChromaticAberrationShader)}
+	e.SetOffset(offset)
+	return e
+}
+
+// GetOffset returns the current channel offset.
+// Use SetOffset to change it.
+func (e *ChromaticAberrationEffect) GetOffset() float64 { return e.getFloat("Offset") }
+
+// SetOffset changes the channel offset (in pixels) at the image edges.
+func (e *ChromaticAberrationEffect) SetOffset(offset float64) {
+	e.shader.SetFloatValue("Offset", float32(offset))
+}
+
+// ScanlinesEffect implements CRT-like scanlines.
+type ScanlinesEffect struct {
+	postEffect
+}
+
+// NewScanlinesEffect returns a scanlines effect.
+//
+// By default, scanlines have these properties:
+// * Spacing is 2
+// * Intensity is 0.25
+func NewScanlinesEffect() *ScanlinesEffect {
+	e := &ScanlinesEffect{postEffect: newPostEffect(cache.Global.ScanlinesShader)}
+	e.SetSpacing(2)
+	e.SetIntensity(0.25)
+	return e
+}
+
+// GetSpacing returns the current scanlines spacing.
+// Use SetSpacing to change it.
+func (e *ScanlinesEffect) GetSpacing() float64 { return e.getFloat("Spacing") }
+
+// SetSpacing changes the distance between the scanlines (in pixels).
+func (e *ScanlinesEffect) SetSpacing(spacing float64) {
+	e.shader.SetFloatValue("Spacing", float32(spacing))
+}
+
+// GetIntensity returns the current scanlines intensity.
+// Use SetIntensity to change it.
+func (e *ScanlinesEffect) GetIntensity() float64 { return e.getFloat("Intensity") }
+
+// SetIntensity changes how dark the scanlines are.
+// The value is in [0, 1] range.
+func (e *ScanlinesEffect) SetIntensity(intensity float64) {
+	e.shader.SetFloatValue("Intensity", float32(intensity))
+}
+
+// ColorLUTEffect implements a color grading via the lookup table (LUT).
+type ColorLUTEffect struct {
+	postEffect
+}
+
+// NewColorLUTEffect returns a color grading effect that uses the provided LUT.
+//
+// The LUT image is expected to be a horizontal strip of N cells,
+// where every cell is a NxN image. The cell index selects the blue value,
+// while the pixel position inside the cell selects the red (X) and green (Y) values.
+// A typical LUT sizes are 256x16 (N=16) and 1024x32 (N=32).
+func NewColorLUTEffect(lut *ebiten.Image) *ColorLUTEffect {
+	e := &ColorLUTEffect{postEffect: newPostEffect(cache.Global.ColorLUTShader)}
+	e.SetLUT(lut)
+	e.SetIntensity(1)
+	return e
+}
+
+// SetLUT assigns a new lookup table image.
+// See [NewColorLUTEffect] for the expected image layout.
+func (e *ColorLUTEffect) SetLUT(lut *ebiten.Image) {
+	size := lut.Bounds().Size()
+	if size.X != size.Y*size.Y {
+		panic("invalid LUT image size: width should be height*height")
+	}
+	e.shader.Texture1 = lut
+	e.shader.SetFloatValue("CellSize", float32(size.Y))
+}
+
+// GetIntensity returns the current grading intensity.
+// Use SetIntensity to change it.
+func (e *ColorLUTEffect) GetIntensity() float64 { return e.getFloat("Intensity") }
+
+// SetIntensity changes how much of the graded color is used.
+// 0 means "original colors", 1 means "fully graded colors".
+func (e *ColorLUTEffect) SetIntensity(intensity float64) {
+	e.shader.SetFloatValue("Intensity", float32(intensity))
+}
+
+// ToneEffect maps the image colors using a fixed color matrix.
+// Use [NewGrayscaleEffect] or [NewSepiaEffect] to create it.
+type ToneEffect struct {
+	postEffect
+}
+
+// NewGrayscaleEffect returns a tone effect that makes the image grayscale.
+func NewGrayscaleEffect() *ToneEffect {
+	return newToneEffect(toneMatrixGrayscale)
+}
+
+// NewSepiaEffect returns a tone effect that gives the image a sepia tone.
+func NewSepiaEffect() *ToneEffect {
+	return newToneEffect(toneMatrixSepia)
+}
+
+func newToneEffect(m []float32) *ToneEffect {
+	e := &ToneEffect{postEffect: newPostEffect(cache.Global.ToneShader)}
+	e.shader.setFloat32SliceValue("Matrix", m)
+	e.SetIntensity(1)
+	return e
+}
+
+// GetIntensity returns the current tone intensity.
+// Use SetIntensity to change it.
+func (e *ToneEffect) GetIntensity() float64 { return e.getFloat("Intensity") }
+
+// SetIntensity changes how much of the toned color is used.
+// 0 means "original colors", 1 means "fully toned colors".
+func (e *ToneEffect) SetIntensity(intensity float64) {
+	e.shader.SetFloatValue("Intensity", float32(intensity))
+}
+
+// PixelateEffect makes the image look like it has a lower resolution.
+type PixelateEffect struct {
+	postEffect
+}
+
+// NewPixelateEffect returns a pixelate effect of the specified pixel size.
+// See [PixelateEffect.SetPixelSize].
+func NewPixelateEffect(pixelSize float64) *PixelateEffect {
+	e := &PixelateEffect{postEffect: newPostEffect(cache.Global.PixelateShader)}
+	e.SetPixelSize(pixelSize)
+	return e
+}
+
+// GetPixelSize returns the current pixel size.
+// Use SetPixelSize to change it.
+func (e *PixelateEffect) GetPixelSize() float64 { return e.getFloat("PixelSize") }
+
+// SetPixelSize changes the size of the resulting "big" pixels.
+// The size is clamped to be at least 1.
+func (e *PixelateEffect) SetPixelSize(size float64) {
+	e.shader.SetFloatValue("PixelSize", float32(max(size, 1)))
+}
+
+// drawBlurPasses renders a separable blur: src => tmp => dst.
+func drawBlurPasses(dst, tmp, src *ebiten.Image, shader *Shader, opts DrawOptions) {
+	shader.SetVec2Value("Direction", blurDirectionHorizontal)
+	drawPostEffect(tmp, src, shader, DrawOptions{})
+	shader.SetVec2Value("Direction", blurDirectionVertical)
+	drawPostEffect(dst, tmp, shader, opts)
+}
+
+// drawPostEffect renders src onto dst using the effect shader.
+//
+// Unlike DrawRectShader, DrawTrianglesShader allows the
+// extra images (like LUT) to have a different size.
+func drawPostEffect(dst, src *ebiten.Image, shader *Shader, opts DrawOptions) {
+	vertices := cache.Global.ScratchVertices[:0]
+	defer func() {
+		cache.Global.ScratchVertices = vertices[:0]
+	}()
+
+	bounds := src.Bounds()
+	x := float32(opts.Offset.X)
+	y := float32(opts.Offset.Y)
+	w := float32(bounds.Dx())
+	h := float32(bounds.Dy())
+	srcX := float32(bounds.Min.X)
+	srcY := float32(bounds.Min.Y)
+	vertices = append(vertices,
+		ebiten.Vertex{DstX: x, DstY: y, SrcX: srcX, SrcY: srcY, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+		ebiten.Vertex{DstX: x + w, DstY: y, SrcX: srcX + w, SrcY: srcY, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+		ebiten.Vertex{DstX: x, DstY: y + h, SrcX: srcX, SrcY: srcY + h, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+		ebiten.Vertex{DstX: x + w, DstY: y + h, SrcX: srcX + w, SrcY: srcY + h, ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1},
+	)
+
+	var blend ebiten.Blend
+	if opts.Blend != nil {
+		blend = *opts.Blend
+	}
+	shader.DrawTriangles(dst, vertices, quadIndices, src, blend)
+}
@@ -0,0 +1,73 @@
+package graphics
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// PostProcessorChain is a [PostProcessor] that applies
+// several post-processors in order.
+//
+// The output of one post-processor becomes the input of the next one.
+// The intermediate results are stored in two offscreen buffers
+// that are used in a ping-pong manner, so the chain of any length
+// needs at most two extra images.
+// Only the last post-processor receives the original DrawOptions;
+// the intermediate steps are rendered with zero options.
+//
+// An empty chain simply draws the src image onto dst.
+//
+// A chain is a post-processor too, so it can be nested or
+// passed to [Camera.SetPostProcessor].
+type PostProcessorChain struct {
+	list []PostProcessor
+
+	bufs [2]pooledBuf
+}
+
+// NewPostProcessorChain returns a chain of the specified post-processors.
+func NewPostProcessorChain(pp ...PostProcessor) *PostProcessorChain {
+	return &PostProcessorChain{list: pp}
+}
+
+// Add appends a post-processor to the end of the chain.
+func (c *PostProcessorChain) Add(pp PostProcessor) {
+	c.list = append(c.list, pp)
+}
+
+// Remove deletes the post-processor from the chain.
+// It does nothing if pp is not a part of this chain.
+func (c *PostProcessorChain) Remove(pp PostProcessor) {
+	for i, x := range c.list {
+		if x == pp {
+			c.list = append(c.list[:i], c.list[i+1:]...)
+			return
+		}
+	}
+}
+
+// Len reports the number of post-processors inside this chain.
+func (c *PostProcessorChain) Len() int {
+	return len(c.list)
+}
+
+func (c *PostProcessorChain) PostProcess(dst, src *ebiten.Image, opts DrawOptions) {
+	if len(c.list) == 0 {
+		var drawOptions ebiten.DrawImageOptions
+		if opts.Blend != nil {
+			drawOptions.Blend = *opts.Blend
+		}
+		drawOptions.GeoM.Translate(opts.Offset.X, opts.Offset.Y)
+		dst.DrawImage(src, &drawOptions)
+		return
+	}
+
+	size := src.Bounds().Size()
+	lastIndex := len(c.list) - 1
+	for i, pp := range c.list[:lastIndex] {
+		tmp := c.bufs[i%2].get(size)
+		tmp.Clear()
+		pp.PostProcess(tmp, src, DrawOptions{})
+		src = tmp
+	}
+	c.list[lastIndex].PostProcess(dst, src, opts)
+}
@@ -0,0 +1,102 @@
+package graphics
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/quasilyte/gmath"
+)
+
+func callNames(calls []postProcessCall) []string {
+	names := make([]string, len(calls))
+	for i, c := range calls {
+		names[i] = c.name
+	}
+	return names
+}
+
+func TestPostProcessorChainOrder(t *testing.T) {
+	var calls []postProcessCall
+	newPP := func(name string) *recordingPostProcessor {
+		return &recordingPostProcessor{name: name, calls: &calls}
+	}
+	a := newPP("a")
+	b := newPP("b")
+	c := newPP("c")
+	d := newPP("d")
+	chain := NewPostProcessorChain(a, b, c)
+	chain.Add(d)
+
+	dst := ebiten.NewImage(16, 16)
+	src := ebiten.NewImage(16, 16)
+	opts := DrawOptions{Offset: gmath.Vec{X: 3, Y: 4}, Blend: &ebiten.BlendLighter}
+	chain.PostProcess(dst, src, opts)
+
+	if have := callNames(calls); !slices.Equal(have, []string{"a", "b", "c", "d"}) {
+		t.Fatalf("order: %v", have)
+	}
+	if calls[0].src != src {
+		t.Fatal("the first post-processor doesn't receive src")
+	}
+	buffers := map[*ebiten.Image]struct{}{}
+	for i := 1; i < len(calls); i++ {
+		prev := calls[i-1]
+		if calls[i].src != prev.dst {
+			t.Fatalf("%s doesn't receive the %s output", calls[i].name, prev.name)
+		}
+		// Only the last post-processor gets the original options.
+		if prev.opts != (DrawOptions{}) {
+			t.Fatalf("%s has non-zero intermediate options: %+v", prev.name, prev.opts)
+		}
+		buffers[prev.dst] = struct{}{}
+	}
+	if len(buffers) != 2 {
+		t.Fatalf("have %d intermediate buffers, want 2", len(buffers))
+	}
+	last := calls[len(calls)-1]
+	if last.dst != dst || last.opts != opts {
+		t.Fatalf("the last post-processor has dst=%p opts=%+v", last.dst, last.opts)
+	}
+
+	// Remove keeps the order of the remaining post-processors.
+	chain.Remove(b)
+	chain.Remove(b)
+	if chain.Len() != 3 {
+		t.Fatalf("have %d post-processors after Remove, want 3", chain.Len())
+	}
+	calls = calls[:0]
+	chain.PostProcess(dst, src, opts)
+	if have := callNames(calls); !slices.Equal(have, []string{"a", "c", "d"}) {
+		t.Fatalf("order after Remove: %v", have)
+	}
+}
+
+func TestPostProcessorChainNested(t *testing.T) {
+	var calls []postProcessCall
+	newPP := func(name string) *recordingPostProcessor {
+		return &recordingPostProcessor{name: name, calls: &calls}
+	}
+	inner := NewPostProcessorChain(newPP("b"), newPP("c"))
+	chain := NewPostProcessorChain(newPP("a"), inner, newPP("d"))
+
+	dst := ebiten.NewImage(16, 16)
+	chain.PostProcess(dst, ebiten.NewImage(16, 16), DrawOptions{})
+	if have := callNames(calls); !slices.Equal(have, []string{"a", "b", "c", "d"}) {
+		t.Fatalf("order: %v", have)
+	}
+	if calls[3].dst != dst {
+		t.Fatal("the last post-processor doesn't draw onto dst")
+	}
+}
+
+func TestPostProcessorChainEmpty(t *testing.T) {
+	chain := NewPostProcessorChain()
+	if chain.Len() != 0 {
+		t.Fatalf("have %d post-processors, want 0", chain.Len())
+	}
+	// An empty chain draws src as is.
+	chain.PostProcess(ebiten.NewImage(16, 16), ebiten.NewImage(8, 8), DrawOptions{
+		Offset: gmath.Vec{X: 2, Y: 2},
+	})
+}
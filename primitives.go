@@ -6,6 +6,7 @@ import (
 	"math"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/quasilyte/ebitengine-graphics/internal/cache"
 	"github.com/quasilyte/gmath"
 )
 
@@ -13,6 +14,8 @@ var emptyImage = ebiten.NewImage(3, 3)
 
 var whitePixel = emptyImage.SubImage(image.Rect(1, 1, 2, 2)).(*ebiten.Image)
 
+var quadIndices = []uint16{0, 1, 2, 1, 2, 3}
+
 func init() {
 	emptyImage.Fill(color.White)
 }
@@ -39,3 +42,38 @@ func drawLine(dst *ebiten.Image, blend *ebiten.Blend, pos1, pos2 gmath.Vec, widt
 
 	dst.DrawImage(whitePixel, &drawOptions)
 }
+
+// drawPixelated is like dst.DrawImage, but it uses the pixelated filter shader.
+func drawPixelated(dst, src *ebiten.Image, drawOptions *ebiten.DrawImageOptions) {
+	// The scaling factor is needed to figure out
+	// how many dst pixels are covered by a single texel.
+	geom := &drawOptions.GeoM
+	scale := cache.Global.PixelatedShaderScale[:]
+	scale[0] = float32(math.Hypot(geom.Element(0, 0), geom.Element(1, 0)))
+	scale[1] = float32(math.Hypot(geom.Element(0, 1), geom.Element(1, 1)))
+
+	bounds := src.Bounds()
+	var options ebiten.DrawRectShaderOptions
+	options.Blend = drawOptions.Blend
+	options.GeoM = drawOptions.GeoM
+	options.ColorScale = drawOptions.ColorScale
+	options.Images[0] = src
+	options.Uniforms = cache.Global.PixelatedShaderUniforms
+	dst.DrawRectShader(bounds.Dx(), bounds.Dy(), cache.Global.PixelatedShader, &options)
+}
+
+// drawPixelatedRepeated is like drawPixelated, but it renders the vertices
+// with the texture tiled over the src image region.
+// The vertex colors are expected to be premultiplied.
+func drawPixelatedRepeated(dst, src *ebiten.Image, vertices []ebiten.Vertex, drawOptions *ebiten.DrawImageOptions) {
+	geom := &drawOptions.GeoM
+	scale := cache.Global.PixelatedShaderScale[:]
+	scale[0] = float32(math.Hypot(geom.Element(0, 0), geom.Element(1, 0)))
+	scale[1] = float32(math.Hypot(geom.Element(0, 1), geom.Element(1, 1)))
+
+	var options ebiten.DrawTrianglesShaderOptions
+	options.Blend = drawOptions.Blend
+	options.Images[0] = src
+	options.Uniforms = cache.Global.PixelatedRepeatShaderUniforms
+	dst.DrawTrianglesShader(vertices, quadIndices, cache.Global.PixelatedShader, &options)
+}
@@ -78,6 +78,13 @@ func (rect *Rect) BoundsRect() gmath.Rect {
 	}
 }
 
+// ContainsPoint reports whether the rect contains the pos.
+// The pos is in the same coordinates as the rect Pos.
+// An invisible rect contains no points.
+func (rect *Rect) ContainsPoint(pos gmath.Vec) bool {
+	return rect.visible && rect.BoundsRect().Contains(pos)
+}
+
 // Dispose marks this rect for deletion.
 // After calling this method, IsDisposed will report true.
 func (rect *Rect) Dispose() {
@@ -185,11 +192,18 @@ func (rect *Rect) DrawWithOptions(dst *ebiten.Image, opts DrawOptions) {
 	}
 
 	// TODO: compare the peformance of this method with vector package.
-	// TODO: implement the rotation.
-	// TODO: implement the scaling.
 	// TODO: maybe add a special case for opaque rectangles.
 
+	// When there is no parent transformation, the offset
+	// can be added to the position directly.
+	transformed := opts.hasTransform()
 	finalOffset := rect.calculateFinalOffset(opts.Offset)
+	if transformed {
+		finalOffset = rect.calculateFinalOffset(gmath.Vec{})
+	}
+
+	fillColorScale := rect.fillColorScale.ToEbitenColorScale()
+	opts.applyColorScale(&fillColorScale)
 
 	if rect.outlineColorScale.A == 0 || rect.outlineWidth < 1 {
 		// Fill-only mode.
@@ -198,18 +212,21 @@ func (rect *Rect) DrawWithOptions(dst *ebiten.Image, opts DrawOptions) {
 			drawOptions.Blend = *opts.Blend
 		}
 		drawOptions.GeoM = rect.calculateGeom(rect.width, rect.height, finalOffset)
-		drawOptions.ColorScale = rect.fillColorScale.ToEbitenColorScale()
+		if transformed {
+			opts.applyTransform(&drawOptions.GeoM)
+		}
+		drawOptions.ColorScale = fillColorScale
 		dst.DrawImage(whitePixel, &drawOptions)
 		return
 	}
 
 	if rect.fillColorScale.A == 0 && rect.outlineWidth >= 1 {
 		// Outline-only mode.
-		rect.drawOutline(dst, opts.Blend, finalOffset)
+		rect.drawOutline(dst, &opts, finalOffset, transformed)
 		return
 	}
 
-	rect.drawOutline(dst, opts.Blend, finalOffset)
+	rect.drawOutline(dst, &opts, finalOffset, transformed)
 
 	var drawOptions ebiten.DrawImageOptions
 	if opts.Blend != nil {
@@ -217,11 +234,14 @@ func (rect *Rect) DrawWithOptions(dst *ebiten.Image, opts DrawOptions) {
 	}
 	drawOptions.GeoM.Scale(rect.width-rect.outlineWidth*2, rect.height-rect.outlineWidth*2)
 	drawOptions.GeoM.Translate(rect.outlineWidth+finalOffset.X, rect.outlineWidth+finalOffset.Y)
-	drawOptions.ColorScale = rect.fillColorScale.ToEbitenColorScale()
+	if transformed {
+		opts.applyTransform(&drawOptions.GeoM)
+	}
+	drawOptions.ColorScale = fillColorScale
 	dst.DrawImage(whitePixel, &drawOptions)
 }
 
-func (rect *Rect) drawOutline(dst *ebiten.Image, blend *ebiten.Blend, offset gmath.Vec) {
+func (rect *Rect) drawOutline(dst *ebiten.Image, opts *DrawOptions, offset gmath.Vec, transformed bool) {
 	if rect.outlineVertices == nil {
 		// Allocate these vertices lazily when we need them and then re-use them.
 		rect.outlineVertices = new([8]ebiten.Vertex)
@@ -230,10 +250,11 @@ func (rect *Rect) drawOutline(dst *ebiten.Image, blend *ebiten.Blend, offset gma
 	borderWidth := float32(rect.outlineWidth)
 	x := float32(offset.X)
 	y := float32(offset.Y)
-	r := rect.outlineColorScale.R
-	g := rect.outlineColorScale.G
-	b := rect.outlineColorScale.B
-	a := rect.outlineColorScale.A
+	outlineColorScale := opts.multiplyColorScale(rect.outlineColorScale)
+	r := outlineColorScale.R
+	g := outlineColorScale.G
+	b := outlineColorScale.B
+	a := outlineColorScale.A
 	width := float32(rect.width)
 	height := float32(rect.height)
 
@@ -302,11 +323,20 @@ func (rect *Rect) drawOutline(dst *ebiten.Image, blend *ebiten.Blend, offset gma
 		SrcY: 1,
 	}
 
+	if transformed {
+		for i := range rect.outlineVertices {
+			v := &rect.outlineVertices[i]
+			p := opts.transformPos(gmath.Vec{X: float64(v.DstX), Y: float64(v.DstY)})
+			v.DstX = float32(p.X)
+			v.DstY = float32(p.Y)
+		}
+	}
+
 	options := ebiten.DrawTrianglesOptions{
 		FillRule: ebiten.FillRuleEvenOdd,
 	}
-	if blend != nil {
-		options.Blend = *blend
+	if opts.Blend != nil {
+		options.Blend = *opts.Blend
 	}
 	dst.DrawTriangles(rect.outlineVertices[:], borderBoxIndices, whitePixel, &options)
 }
@@ -19,6 +19,19 @@ type SceneDrawer struct {
 
 	layers []SceneLayerDrawer
 	buf    *ebiten.Image
+
+	// layerEffects is allocated lazily.
+	// Most layers don't have any effects, so their entries are nil.
+	layerEffects []*layerEffect
+
+	// layerBuf is an offscreen buffer used to render the layers with effects.
+	// It's shared between all layers.
+	layerBuf pooledBuf
+}
+
+type layerEffect struct {
+	chain PostProcessorChain
+	blend *ebiten.Blend
 }
 
 type installedCamera struct {
@@ -88,6 +101,54 @@ func (d *SceneDrawer) RemoveCamera(camera *Camera) {
 	d.cameras = slices.Delete(d.cameras, index, index+1)
 }
 
+// SetLayerPostProcessors assigns a list of post-processors to the specified layer.
+// Calling this method without any post-processors removes them from the layer.
+//
+// A layer with post-processors (or a blend, see [SceneDrawer.SetLayerBlend])
+// is rendered to an offscreen buffer first.
+// Then the post-processors are applied in order: the output of
+// one post-processor becomes the input of the next one.
+// The last post-processor draws the result onto the camera's image.
+//
+// This is useful for the effects that should only affect
+// some of the layers, like a bloom for the effects layer.
+// To apply a post-processor to all layers, use [Camera.SetPostProcessor].
+//
+// See [PostProcessorChain] to learn more about the chaining.
+//
+// It panics if the layer index is out of the scene layers range.
+func (d *SceneDrawer) SetLayerPostProcessors(layer int, pp ...PostProcessor) {
+	fx := d.getLayerEffect(layer)
+	fx.chain.list = append(fx.chain.list[:0], pp...)
+}
+
+// SetLayerBlend assigns a blend mode that is used to composite
+// the offscreen layer's result with the layers rendered before it.
+// A nil blend means "use the default blend mode".
+//
+// Setting a non-nil blend makes the layer use the offscreen
+// rendering even if it has no post-processors.
+//
+// It panics if the layer index is out of the scene layers range.
+func (d *SceneDrawer) SetLayerBlend(layer int, blend *ebiten.Blend) {
+	d.getLayerEffect(layer).blend = blend
+}
+
+func (d *SceneDrawer) getLayerEffect(layer int) *layerEffect {
+	if layer < 0 || layer >= len(d.layers) {
+		panic("layer index is not in [0, len(layers)) bounds")
+	}
+	if d.layerEffects == nil {
+		d.layerEffects = make([]*layerEffect, len(d.layers))
+	}
+	fx := d.layerEffects[layer]
+	if fx == nil {
+		fx = &layerEffect{}
+		d.layerEffects[layer] = fx
+	}
+	return fx
+}
+
 func (d *SceneDrawer) AddGraphics(o gsceneGraphics, layer int) {
 	l := d.layers[layer]
 	l.AddChild(o)
@@ -123,6 +184,12 @@ func (d *SceneDrawer) Draw(dst *ebiten.Image) {
 					continue
 				}
 			}
+			if d.layerEffects != nil {
+				if fx := d.layerEffects[i]; fx != nil && (fx.chain.Len() != 0 || fx.blend != nil) {
+					d.drawLayerWithEffects(cameraDst, l, options, fx)
+					continue
+				}
+			}
 			l.DrawWithOptions(cameraDst, options)
 		}
 
@@ -145,6 +212,18 @@ func (d *SceneDrawer) Draw(dst *ebiten.Image) {
 	}
 }
 
+func (d *SceneDrawer) drawLayerWithEffects(dst *ebiten.Image, l SceneLayerDrawer, options DrawOptions, fx *layerEffect) {
+	src := d.layerBuf.get(dst.Bounds().Size())
+	src.Clear()
+	l.DrawWithOptions(src, options)
+
+	// An empty chain simply draws the src onto dst.
+	fx.chain.PostProcess(dst, src, DrawOptions{
+		Offset: gmath.VecFromStd(dst.Bounds().Min),
+		Blend:  fx.blend,
+	})
+}
+
 func (d *SceneDrawer) cameraAdjustedBuf(camera *installedCamera, buf *ebiten.Image) *ebiten.Image {
 	// Maybe we already have a suitable subimage?
 	// If camera viewport sizes are the same, use it.
@@ -0,0 +1,130 @@
+package graphics
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/quasilyte/gmath"
+)
+
+type postProcessCall struct {
+	name     string
+	dst, src *ebiten.Image
+	opts     DrawOptions
+}
+
+type recordingPostProcessor struct {
+	name  string
+	calls *[]postProcessCall
+}
+
+func (pp *recordingPostProcessor) PostProcess(dst, src *ebiten.Image, opts DrawOptions) {
+	*pp.calls = append(*pp.calls, postProcessCall{name: pp.name, dst: dst, src: src, opts: opts})
+}
+
+type imageRecordingLayer struct {
+	images []*ebiten.Image
+}
+
+func (l *imageRecordingLayer) Update(delta float64)      {}
+func (l *imageRecordingLayer) AddChild(o gsceneGraphics) {}
+
+func (l *imageRecordingLayer) DrawWithOptions(dst *ebiten.Image, opts DrawOptions) {
+	l.images = append(l.images, dst)
+}
+
+func newTestSceneDrawer(layers ...SceneLayerDrawer) *SceneDrawer {
+	viewport := gmath.Rect{Max: gmath.Vec{X: 64, Y: 32}}
+	d := NewSceneDrawer(layers)
+	d.SetViewportRect(viewport)
+	camera := NewCamera()
+	camera.SetViewportRect(viewport)
+	d.AddCamera(camera)
+	return d
+}
+
+func TestSceneDrawerLayerEffectIndex(t *testing.T) {
+	d := newTestSceneDrawer(NewLayer(), NewLayer())
+
+	for _, layer := range []int{-1, 2, 100} {
+		expectPanic(t, fmt.Sprintf("post-processors for layer %d", layer), func() {
+			d.SetLayerPostProcessors(layer, NewPostProcessorChain())
+		})
+		expectPanic(t, fmt.Sprintf("blend for layer %d", layer), func() {
+			d.SetLayerBlend(layer, &ebiten.BlendLighter)
+		})
+	}
+
+	// The valid indexes are accepted.
+	d.SetLayerPostProcessors(0, NewPostProcessorChain())
+	d.SetLayerBlend(1, &ebiten.BlendLighter)
+}
+
+func TestSceneDrawerLayerPostProcessors(t *testing.T) {
+	layer0 := &imageRecordingLayer{}
+	layer1 := &imageRecordingLayer{}
+	layer2 := &imageRecordingLayer{}
+	d := newTestSceneDrawer(layer0, layer1, layer2)
+
+	var calls []postProcessCall
+	d.SetLayerPostProcessors(1,
+		&recordingPostProcessor{name: "a", calls: &calls},
+		&recordingPostProcessor{name: "b", calls: &calls},
+		&recordingPostProcessor{name: "c", calls: &calls},
+	)
+
+	dst := ebiten.NewImage(64, 32)
+	d.Draw(dst)
+
+	// The layers without effects are drawn directly.
+	if len(layer0.images) != 1 || layer0.images[0] != dst {
+		t.Fatal("layer 0 is not drawn onto dst")
+	}
+	if len(layer2.images) != 1 || layer2.images[0] != dst {
+		t.Fatal("layer 2 is not drawn onto dst")
+	}
+
+	// The layer with effects is drawn offscreen first.
+	if len(layer1.images) != 1 || layer1.images[0] == dst {
+		t.Fatal("layer 1 is not drawn offscreen")
+	}
+	layerBuf := layer1.images[0]
+
+	if names := callNames(calls); !slices.Equal(names, []string{"a", "b", "c"}) {
+		t.Fatalf("post-processors order: %v", names)
+	}
+	if calls[0].src != layerBuf {
+		t.Fatal("the first post-processor doesn't receive the layer image")
+	}
+	for i := 1; i < len(calls); i++ {
+		if calls[i].src != calls[i-1].dst {
+			t.Fatalf("post-processor %s doesn't receive the %s output", calls[i].name, calls[i-1].name)
+		}
+		if calls[i-1].dst == dst || calls[i-1].dst == layerBuf {
+			t.Fatalf("post-processor %s doesn't use an intermediate buffer", calls[i-1].name)
+		}
+	}
+	if calls[2].dst != dst {
+		t.Fatal("the last post-processor doesn't draw onto dst")
+	}
+
+	// Removing the post-processors makes the layer draw directly again.
+	d.SetLayerPostProcessors(1)
+	calls = calls[:0]
+	d.Draw(dst)
+	if len(calls) != 0 {
+		t.Fatalf("removed post-processors are called %d times", len(calls))
+	}
+	if layer1.images[1] != dst {
+		t.Fatal("layer 1 is not drawn onto dst after removing its post-processors")
+	}
+
+	// A blend alone enables the offscreen rendering too.
+	d.SetLayerBlend(1, &ebiten.BlendLighter)
+	d.Draw(dst)
+	if layer1.images[2] == dst {
+		t.Fatal("layer 1 with a blend is not drawn offscreen")
+	}
+}
@@ -51,6 +51,34 @@ func (s *Shader) Clone() *Shader {
 	return &cloned
 }
 
+// DrawTriangles renders the triangles with this shader.
+//
+// This method is useful for the custom renderers
+// that need to draw a batch of shaded quads at once;
+// the package's own batched renderers (like [Trail]) use it too.
+//
+// The src is bound to Images[0], Texture1-Texture3 are bound
+// to Images[1]-Images[3] respectively.
+// The vertex Dst coordinates are in dst pixels and the
+// Src coordinates are in src pixels (the sub-image bounds are respected).
+// The textures are sampled with the same coordinates (relative to their bounds origin)
+// as src, so they should have the same size as src.
+// For the shaders that use the texels unit, the size mismatch causes a panic.
+//
+// The vertex colors are passed to the shader as is, they're not
+// premultiplied or combined with any color scale.
+// The uniforms are the values assigned to this shader, the Enabled flag is not checked.
+func (s *Shader) DrawTriangles(dst *ebiten.Image, vertices []ebiten.Vertex, indices []uint16, src *ebiten.Image, blend ebiten.Blend) {
+	var options ebiten.DrawTrianglesShaderOptions
+	options.Blend = blend
+	options.Images[0] = src
+	options.Images[1] = s.Texture1
+	options.Images[2] = s.Texture2
+	options.Images[3] = s.Texture3
+	options.Uniforms = s.shaderData
+	dst.DrawTrianglesShader(vertices, indices, s.compiled, &options)
+}
+
 // GetValue returns the current uniform value stored under the key.
 func (s *Shader) GetValue(key string) any {
 	return s.shaderData[key]
@@ -16,6 +16,39 @@ var (
 
 	//go:embed _shaders/dotted_line.go
 	shaderDottedLine []byte
+
+	//go:embed _shaders/pixelated.go
+	shaderPixelated []byte
+
+	//go:embed _shaders/blur.go
+	shaderBlur []byte
+
+	//go:embed _shaders/bright_pass.go
+	shaderBrightPass []byte
+
+	//go:embed _shaders/vignette.go
+	shaderVignette []byte
+
+	//go:embed _shaders/chromatic_aberration.go
+	shaderChromaticAberration []byte
+
+	//go:embed _shaders/scanlines.go
+	shaderScanlines []byte
+
+	//go:embed _shaders/color_lut.go
+	shaderColorLUT []byte
+
+	//go:embed _shaders/tone.go
+	shaderTone []byte
+
+	//go:embed _shaders/pixelate.go
+	shaderPixelate []byte
+
+	//go:embed _shaders/light.go
+	shaderLight []byte
+
+	//go:embed _shaders/normal_map.go
+	shaderNormalMap []byte
 )
 
 // CompileShaders prepares shaders bundled with this package.
@@ -25,6 +58,10 @@ var (
 // Objects that require shaders so far:
 // * Circle
 // * DottedLine
+// * Sprite with FilterPixelated
+// * Post-processing effects (BlurEffect, BloomEffect, etc.)
+// * Light
+// * Sprite with a normal map
 func CompileShaders() {
 	if cache.Global.ShadersCompiled {
 		return
@@ -42,6 +79,19 @@ func CompileShaders() {
 	cache.Global.CircleOutlineShader = mustCompileShader(shaderCircleOutline)
 	cache.Global.DashedCircleOutlineShader = mustCompileShader(shaderDashedCircleOutline)
 	cache.Global.DottedLineShader = mustCompileShader(shaderDottedLine)
+	cache.Global.PixelatedShader = mustCompileShader(shaderPixelated)
+
+	cache.Global.BlurShader = mustCompileShader(shaderBlur)
+	cache.Global.BrightPassShader = mustCompileShader(shaderBrightPass)
+	cache.Global.VignetteShader = mustCompileShader(shaderVignette)
+	cache.Global.ChromaticAberrationShader = mustCompileShader(shaderChromaticAberration)
+	cache.Global.ScanlinesShader = mustCompileShader(shaderScanlines)
+	cache.Global.ColorLUTShader = mustCompileShader(shaderColorLUT)
+	cache.Global.ToneShader = mustCompileShader(shaderTone)
+	cache.Global.PixelateShader = mustCompileShader(shaderPixelate)
+
+	cache.Global.LightShader = mustCompileShader(shaderLight)
+	cache.Global.NormalMapShader = mustCompileShader(shaderNormalMap)
 }
 
 func requireShaders() {
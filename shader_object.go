@@ -126,7 +126,7 @@ func (o *ShaderObject) DrawWithOptions(dst *ebiten.Image, opts DrawOptions) {
 		cache.Global.ScratchIndices = indices[:0]
 	}()
 
-	pos := opts.Offset.Add(o.Pos.Resolve())
+	pos := o.Pos.Resolve()
 	w := float32(o.width)
 	h := float32(o.height)
 
@@ -138,19 +138,21 @@ func (o *ShaderObject) DrawWithOptions(dst *ebiten.Image, opts DrawOptions) {
 	srcHeight := h
 
 	// Maybe allow the user to provide a custom color scale?
-	clrR := o.ebitenColorScale.R()
-	clrG := o.ebitenColorScale.G()
-	clrB := o.ebitenColorScale.B()
-	clrA := o.ebitenColorScale.A()
+	cs := o.ebitenColorScale
+	opts.applyColorScale(&cs)
+	clrR := cs.R()
+	clrG := cs.G()
+	clrB := cs.B()
+	clrA := cs.A()
 
 	angle := gmath.Rad(0)
 	if o.Rotation != nil {
 		angle = *o.Rotation
 	}
 
-	if angle == 0 {
-		x := float32(pos.X)
-		y := float32(pos.Y)
+	if angle == 0 && !opts.hasTransform() {
+		x := float32(pos.X + opts.Offset.X)
+		y := float32(pos.Y + opts.Offset.Y)
 		if o.centered {
 			x -= w * 0.5
 			y -= h * 0.5
@@ -168,8 +170,17 @@ func (o *ShaderObject) DrawWithOptions(dst *ebiten.Image, opts DrawOptions) {
 		if o.centered {
 			geom.Translate(-halfWidth, -halfHeight)
 		}
-		geom.Rotate(float64(angle))
+		if angle != 0 {
+			geom.Rotate(float64(angle))
+		}
 		geom.Translate(float32(pos.X), float32(pos.Y))
+		if opts.hasScale() {
+			geom.Scale(float32(opts.Scale.X), float32(opts.Scale.Y))
+		}
+		if opts.Rotation != 0 {
+			geom.Rotate(float64(opts.Rotation))
+		}
+		geom.Translate(float32(opts.Offset.X), float32(opts.Offset.Y))
 		x := geom.Tx
 		y := geom.Ty
 		vertices = append(vertices,
@@ -4,6 +4,7 @@ import (
 	"image"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/quasilyte/ebitengine-graphics/internal/cache"
 	"github.com/quasilyte/gmath"
 )
 
@@ -44,6 +45,11 @@ type Sprite struct {
 	// If nil, no shaders will be used.
 	Shader *Shader
 
+	// extra is allocated lazily when some rarely used
+	// feature is being enabled for this sprite.
+	// This keeps the Sprite object small for the most common case.
+	extra *spriteExtraData
+
 	frameOffsetX uint16
 	frameOffsetY uint16
 
@@ -53,6 +59,38 @@ type Sprite struct {
 	flags spriteFlag
 }
 
+type spriteExtraData struct {
+	skewX gmath.Rad
+	skewY gmath.Rad
+
+	// uvOffset is only used in the repeat mode.
+	uvOffset gmath.Vec
+
+	// A zero repeat size means that the repeat mode is disabled.
+	repeatWidth  uint16
+	repeatHeight uint16
+
+	// geom is an extra transformation matrix.
+	// A zero value is an identity matrix.
+	geom ebiten.GeoM
+
+	// normalMap uses the same layout as the sprite image.
+	// normalSubImage is a cached frame of the normal map.
+	normalMap      *ebiten.Image
+	normalSubImage *ebiten.Image
+	lighting       *NormalMapLighting
+
+	pixelHitTest bool
+}
+
+func (extra *spriteExtraData) isRepeated() bool {
+	return extra.repeatWidth != 0 && extra.repeatHeight != 0
+}
+
+func (extra *spriteExtraData) hasTransform() bool {
+	return extra.skewX != 0 || extra.skewY != 0 || extra.geom != (ebiten.GeoM{})
+}
+
 type spriteFlag uint8
 
 const (
@@ -62,6 +100,33 @@ const (
 	spriteFlagVisible
 	spriteFlagSubImageChanged
 	spriteFlagDisposed
+	spriteFlagFilterBit1
+	spriteFlagFilterBit2
+)
+
+const spriteFlagFilterShift = 6
+
+// Filter is a texture filter used to render the sprite.
+type Filter uint8
+
+const (
+	// FilterNearest is a crisp-edged filter.
+	// It's a default sprite filter.
+	FilterNearest Filter = iota
+
+	// FilterLinear is a smooth filter.
+	// It's well-suited for a non-pixelart graphics.
+	FilterLinear
+
+	// FilterPixelated is like FilterNearest, but it smooths out
+	// the texel edges when the sprite is scaled by a non-integer factor.
+	// It removes the uneven pixel sizes artifact while keeping
+	// the image crisp.
+	//
+	// This filter is implemented via a bundled shader,
+	// you need to call [CompileShaders] before using it.
+	// It has no effect when the sprite Shader is enabled.
+	FilterPixelated
 )
 
 // NewSprite returns an empty sprite.
@@ -89,10 +154,18 @@ func NewSprite() *Sprite {
 //
 // The bounding rectangle can't be used for collisions since it treats
 // the frame size as an object size.
+//
+// If sprite has a skew or an extra GeoM transformation,
+// the returned rectangle is an AABB of the transformed frame.
 func (s *Sprite) BoundsRect() gmath.Rect {
+	if s.extra != nil && s.extra.hasTransform() {
+		return s.transformedBoundsRect()
+	}
+
 	pos := s.calculatePos()
+	w, h := s.drawSize()
 	if s.IsCentered() {
-		offset := gmath.Vec{X: float64(s.frameWidth / 2), Y: float64(s.frameHeight / 2)}
+		offset := gmath.Vec{X: float64(w / 2), Y: float64(h / 2)}
 		return gmath.Rect{
 			Min: pos.Sub(offset),
 			Max: pos.Add(offset),
@@ -100,7 +173,7 @@ func (s *Sprite) BoundsRect() gmath.Rect {
 	}
 	return gmath.Rect{
 		Min: pos,
-		Max: pos.Add(gmath.Vec{X: float64(s.frameWidth), Y: float64(s.frameHeight)}),
+		Max: pos.Add(gmath.Vec{X: float64(w), Y: float64(h)}),
 	}
 }
 
@@ -186,6 +259,206 @@ func (s *Sprite) SetScaleY(scale float64) {
 	s.scaleY = scale
 }
 
+// GetSkewX returns the sprite horizontal (X-axis) skew angle.
+// Use SetSkewX to change it.
+func (s *Sprite) GetSkewX() gmath.Rad {
+	if s.extra == nil {
+		return 0
+	}
+	return s.extra.skewX
+}
+
+// GetSkewY returns the sprite vertical (Y-axis) skew angle.
+// Use SetSkewY to change it.
+func (s *Sprite) GetSkewY() gmath.Rad {
+	if s.extra == nil {
+		return 0
+	}
+	return s.extra.skewY
+}
+
+// SetSkewX assigns new sprite horizontal (X-axis) skew angle.
+// Use GetSkewX to retrieve the current value.
+//
+// The skew is applied around the sprite origin,
+// see [Sprite.SetExtraGeoM] for the exact transformation order.
+func (s *Sprite) SetSkewX(skew gmath.Rad) {
+	if skew == s.GetSkewX() {
+		return
+	}
+	s.getExtra().skewX = skew
+}
+
+// SetSkewY assigns new sprite vertical (Y-axis) skew angle.
+// Use GetSkewY to retrieve the current value.
+//
+// The skew is applied around the sprite origin,
+// see [Sprite.SetExtraGeoM] for the exact transformation order.
+func (s *Sprite) SetSkewY(skew gmath.Rad) {
+	if skew == s.GetSkewY() {
+		return
+	}
+	s.getExtra().skewY = skew
+}
+
+// GetExtraGeoM returns the extra transformation matrix.
+// Use SetExtraGeoM to change it.
+func (s *Sprite) GetExtraGeoM() ebiten.GeoM {
+	if s.extra == nil {
+		return ebiten.GeoM{}
+	}
+	return s.extra.geom
+}
+
+// SetExtraGeoM assigns an extra transformation matrix to the sprite.
+// Use GetExtraGeoM to retrieve the current value.
+// An identity matrix (a zero value) removes the extra transformation.
+//
+// The final sprite transformation is calculated in this order:
+// 1. flips
+// 2. origin translation (the image is moved so its origin is at (0, 0))
+// 3. skew
+// 4. extra GeoM
+// 5. rotation
+// 6. scaling
+// 7. positioning (Pos, PivotOffset and the draw offset)
+//
+// In other words, both skew and the extra matrix are applied
+// in the sprite local space, relative to the origin point.
+func (s *Sprite) SetExtraGeoM(m ebiten.GeoM) {
+	if m == s.GetExtraGeoM() {
+		return
+	}
+	s.getExtra().geom = m
+}
+
+// GetFilter returns the sprite texture filter.
+// Use SetFilter to change it.
+func (s *Sprite) GetFilter() Filter {
+	return Filter((s.flags >> spriteFlagFilterShift) & 0b11)
+}
+
+// SetFilter changes the sprite texture filter.
+// Use GetFilter to retrieve the current value.
+//
+// See [Filter] for the available options.
+func (s *Sprite) SetFilter(f Filter) {
+	if f == FilterPixelated {
+		requireShaders()
+	}
+	s.flags &^= spriteFlagFilterBit1 | spriteFlagFilterBit2
+	s.flags |= spriteFlag(f&0b11) << spriteFlagFilterShift
+}
+
+// IsRepeated reports whether the repeat (tiling) mode is enabled.
+// Use SetRepeatSize to enable it.
+func (s *Sprite) IsRepeated() bool {
+	return s.extra != nil && s.extra.isRepeated()
+}
+
+// GetRepeatSize returns the repeat mode area size.
+// Use SetRepeatSize to change it.
+func (s *Sprite) GetRepeatSize() (w, h int) {
+	if s.extra == nil {
+		return 0, 0
+	}
+	return int(s.extra.repeatWidth), int(s.extra.repeatHeight)
+}
+
+// SetRepeatSize enables the repeat (tiling) mode.
+// Use GetRepeatSize to retrieve the current value.
+// Setting the size to (0, 0) disables the repeat mode.
+//
+// In this mode, the current frame is repeated to fill
+// the specified area instead of being drawn once.
+// The sprite is then treated as a w*h sized object:
+// the origin, flips, rotation and BoundsRect use the repeat area size.
+//
+// A repeat mode is well-suited for the scrolling backgrounds,
+// see [Sprite.SetUVOffset].
+//
+// When a Shader is used, the texture coordinates are not wrapped
+// automatically, it's up to the shader to wrap them around the
+// imageSrc0Origin() + imageSrc0Size() rectangle.
+func (s *Sprite) SetRepeatSize(w, h int) {
+	if s.extra == nil && w == 0 && h == 0 {
+		return
+	}
+	extra := s.getExtra()
+	extra.repeatWidth = uint16(w)
+	extra.repeatHeight = uint16(h)
+}
+
+// GetUVOffset returns the repeat mode texture offset.
+// Use SetUVOffset to change it.
+func (s *Sprite) GetUVOffset() gmath.Vec {
+	if s.extra == nil {
+		return gmath.Vec{}
+	}
+	return s.extra.uvOffset
+}
+
+// SetUVOffset changes the repeat mode texture offset (in texture pixels).
+// Use GetUVOffset to retrieve the current value.
+//
+// The offset wraps around the frame sizes.
+// Changing this value over time makes the texture scroll
+// inside the repeat area.
+//
+// This offset is only used in the repeat mode, see [Sprite.SetRepeatSize].
+func (s *Sprite) SetUVOffset(offset gmath.Vec) {
+	if offset == s.GetUVOffset() {
+		return
+	}
+	s.getExtra().uvOffset = offset
+}
+
+// GetNormalMap returns the current sprite normal map.
+// Use SetNormalMap to change it.
+func (s *Sprite) GetNormalMap() *ebiten.Image {
+	if s.extra == nil {
+		return nil
+	}
+	return s.extra.normalMap
+}
+
+// SetNormalMap assigns a normal map to the sprite
+// which makes it shaded by the provided lighting.
+// A nil normal map disables the normal mapping.
+//
+// The normal map image should have the same size and layout
+// as the sprite image: the current frame rect (see [Sprite.SetFrameOffsetX])
+// is used to sample both textures.
+// The normal map is expected to use the "Y up" convention (OpenGL style).
+// The sprite transformation (flips, rotation, skew, the extra GeoM
+// and the non-uniform scaling) is applied to the normals as well.
+//
+// The lights are positioned in the sprite parent space:
+// for a layer sprite it's the world space, but for a [Container]
+// child it's the container local space.
+//
+// When a normal map is set, the sprite Shader is not used.
+// The normal mapping is not supported in the repeat mode.
+//
+// The lighting object is usually shared between several sprites,
+// see [NormalMapLighting].
+//
+// It panics if the normal map size doesn't match the sprite image size.
+func (s *Sprite) SetNormalMap(normalMap *ebiten.Image, lighting *NormalMapLighting) {
+	if normalMap != nil && lighting == nil {
+		panic("a normal map requires a non-nil lighting")
+	}
+	checkNormalMapSize(s.image, normalMap)
+	if s.extra == nil && normalMap == nil {
+		return
+	}
+	extra := s.getExtra()
+	extra.normalMap = normalMap
+	extra.normalSubImage = nil
+	extra.lighting = lighting
+	s.flags |= spriteFlagSubImageChanged
+}
+
 // GetColorScale is used to retrieve the current color scale value of the sprite.
 // Use SetColorScale to change it.
 func (s *Sprite) GetColorScale() ColorScale {
@@ -319,7 +592,12 @@ func (s *Sprite) SetFrameOffsetY(y int) {
 //
 // Assigning an image sets the frame offsets to {0, 0}.
 // The default frame width/height are image sizes.
+//
+// If the sprite has a normal map, the new image should have the same size.
 func (s *Sprite) SetImage(img *ebiten.Image) {
+	if s.extra != nil {
+		checkNormalMapSize(img, s.extra.normalMap)
+	}
 	s.image = img
 
 	imageBounds := img.Bounds()
@@ -331,6 +609,15 @@ func (s *Sprite) SetImage(img *ebiten.Image) {
 	s.flags |= spriteFlagSubImageChanged
 }
 
+func checkNormalMapSize(img, normalMap *ebiten.Image) {
+	if img == nil || normalMap == nil {
+		return
+	}
+	if img.Bounds().Size() != normalMap.Bounds().Size() {
+		panic("the normal map size doesn't match the sprite image size")
+	}
+}
+
 // GetImage returns the sprite's current texture image.
 func (s *Sprite) GetImage() *ebiten.Image {
 	return s.image
@@ -375,37 +662,15 @@ func (s *Sprite) DrawWithOptions(dst *ebiten.Image, opts DrawOptions) {
 		drawOptions.Blend = *opts.Blend
 	}
 	drawOptions.ColorScale = s.ebitenColorScale
-
-	if s.IsHorizontallyFlipped() {
-		drawOptions.GeoM.Scale(-1, 1)
-		drawOptions.GeoM.Translate(float64(s.frameWidth), 0)
-	}
-	if s.IsVerticallyFlipped() {
-		drawOptions.GeoM.Scale(1, -1)
-		drawOptions.GeoM.Translate(0, float64(s.frameHeight))
+	opts.applyColorScale(&drawOptions.ColorScale)
+	filter := s.GetFilter()
+	if filter == FilterLinear {
+		drawOptions.Filter = ebiten.FilterLinear
 	}
 
-	origin := gmath.Vec{}
-	if s.IsCentered() {
-		origin = gmath.Vec{X: float64(s.frameWidth / 2), Y: float64(s.frameHeight / 2)}
-	}
-
-	targetRotation := opts.Rotation
-	if s.Rotation != nil {
-		targetRotation += *s.Rotation
-	}
-
-	// The rotation and scaling should be done around the origin point.
-	drawOptions.GeoM.Translate(-origin.X, -origin.Y)
-	if targetRotation != 0 {
-		drawOptions.GeoM.Rotate(float64(targetRotation))
-	}
-	if s.scaleX != 1 || s.scaleY != 1 {
-		drawOptions.GeoM.Scale(s.scaleX, s.scaleY)
-	}
-
-	pos := s.calculatePos().Add(opts.Offset)
-	drawOptions.GeoM.Translate(pos.X, pos.Y)
+	s.buildGeoM(&drawOptions.GeoM)
+	// The parent transformation goes last.
+	opts.applyTransform(&drawOptions.GeoM)
 
 	// Making a sub-image can be more expensive than we would like it
 	// to be, therefore we cache the subimage result and update it
@@ -421,7 +686,22 @@ func (s *Sprite) DrawWithOptions(dst *ebiten.Image, opts DrawOptions) {
 		srcImage = s.image
 	}
 
+	if s.extra != nil {
+		if s.extra.isRepeated() {
+			s.drawRepeated(dst, srcImage, &drawOptions)
+			return
+		}
+		if s.extra.normalMap != nil {
+			s.drawNormalMapped(dst, srcImage, &drawOptions, &opts)
+			return
+		}
+	}
+
 	if s.Shader == nil || !s.Shader.Enabled {
+		if filter == FilterPixelated {
+			drawPixelated(dst, srcImage, &drawOptions)
+			return
+		}
 		dst.DrawImage(srcImage, &drawOptions)
 		return
 	}
@@ -441,6 +721,241 @@ func (s *Sprite) DrawWithOptions(dst *ebiten.Image, opts DrawOptions) {
 	dst.DrawRectShader(srcImageBounds.Dx(), srcImageBounds.Dy(), s.Shader.compiled, &options)
 }
 
+// IsPixelHitTest reports whether ContainsPoint checks the frame pixels alpha.
+// Use SetPixelHitTest to change this flag value.
+func (s *Sprite) IsPixelHitTest() bool {
+	return s.extra != nil && s.extra.pixelHitTest
+}
+
+// SetPixelHitTest changes the PixelHitTest flag value.
+// Use IsPixelHitTest to get the current flag value.
+//
+// By default, any point inside the (transformed) sprite frame is a hit.
+// With this flag set, the transparent frame pixels are ignored.
+// Reading the image pixels is relatively slow, so it's
+// better to use it for the cursor picking only.
+func (s *Sprite) SetPixelHitTest(enabled bool) {
+	if s.extra == nil && !enabled {
+		return
+	}
+	s.getExtra().pixelHitTest = enabled
+}
+
+// ContainsPoint reports whether the sprite frame contains the pos.
+// The pos is in the same coordinates as the sprite Pos.
+//
+// The test is exact: the sprite rotation, scaling, skew,
+// flips and the extra GeoM are taken into account.
+// An invisible sprite contains no points.
+//
+// See also [Sprite.SetPixelHitTest].
+func (s *Sprite) ContainsPoint(pos gmath.Vec) bool {
+	if !s.IsVisible() || s.image == nil || s.colorScale.A == 0 {
+		return false
+	}
+	w, h := s.drawSize()
+	if w == 0 || h == 0 {
+		return false
+	}
+
+	var geom ebiten.GeoM
+	s.buildGeoM(&geom)
+	if !geom.IsInvertible() {
+		return false
+	}
+	geom.Invert()
+	x, y := geom.Apply(pos.X, pos.Y)
+	if x < 0 || y < 0 || x >= float64(w) || y >= float64(h) {
+		return false
+	}
+
+	if s.extra == nil || !s.extra.pixelHitTest {
+		return true
+	}
+	if s.extra.isRepeated() {
+		x = fposmod(x+s.extra.uvOffset.X, float64(s.frameWidth))
+		y = fposmod(y+s.extra.uvOffset.Y, float64(s.frameHeight))
+	}
+	clr := s.image.At(int(s.frameOffsetX)+int(x), int(s.frameOffsetY)+int(y))
+	_, _, _, a := clr.RGBA()
+	return a != 0
+}
+
+// buildGeoM appends the sprite own transformation to the geom.
+// The result maps the frame (or the repeat area) pixels to the sprite parent coordinates.
+func (s *Sprite) buildGeoM(geom *ebiten.GeoM) {
+	// In the repeat mode, the sprite is treated as an object of the repeat area size.
+	w, h := s.drawSize()
+
+	if s.IsHorizontallyFlipped() {
+		geom.Scale(-1, 1)
+		geom.Translate(float64(w), 0)
+	}
+	if s.IsVerticallyFlipped() {
+		geom.Scale(1, -1)
+		geom.Translate(0, float64(h))
+	}
+
+	origin := gmath.Vec{}
+	if s.IsCentered() {
+		origin = gmath.Vec{X: float64(w / 2), Y: float64(h / 2)}
+	}
+
+	// The rotation and scaling should be done around the origin point.
+	geom.Translate(-origin.X, -origin.Y)
+	if s.extra != nil {
+		s.extra.applyTransform(geom)
+	}
+	if s.Rotation != nil && *s.Rotation != 0 {
+		geom.Rotate(float64(*s.Rotation))
+	}
+	if s.scaleX != 1 || s.scaleY != 1 {
+		geom.Scale(s.scaleX, s.scaleY)
+	}
+
+	pos := s.calculatePos()
+	geom.Translate(pos.X, pos.Y)
+}
+
+// drawRepeated renders the frame repeated over the repeat area.
+// The GeoM inside drawOptions should be computed using the repeat area size.
+func (s *Sprite) drawRepeated(dst, srcImage *ebiten.Image, drawOptions *ebiten.DrawImageOptions) {
+	// Use pre-allocated slices.
+	vertices := cache.Global.ScratchVertices[:0]
+	defer func() {
+		cache.Global.ScratchVertices = vertices[:0]
+	}()
+
+	// Vertex Src coordinates are in the original image space,
+	// so we need to take the sub-image offset into account.
+	// This is why the cached subImage should be up-to-date here:
+	// its bounds define both the src origin and the wrapping area.
+	srcBounds := srcImage.Bounds()
+	frameWidth := float64(srcBounds.Dx())
+	frameHeight := float64(srcBounds.Dy())
+	uv := s.extra.uvOffset
+	srcX := float32(float64(srcBounds.Min.X) + fposmod(uv.X, frameWidth))
+	srcY := float32(float64(srcBounds.Min.Y) + fposmod(uv.Y, frameHeight))
+
+	w := float64(s.extra.repeatWidth)
+	h := float64(s.extra.repeatHeight)
+	geom := &drawOptions.GeoM
+	clr := &drawOptions.ColorScale
+	r, g, b, a := clr.R(), clr.G(), clr.B(), clr.A()
+	for _, corner := range [...]gmath.Vec{{}, {X: w}, {Y: h}, {X: w, Y: h}} {
+		x, y := geom.Apply(corner.X, corner.Y)
+		vertices = append(vertices, ebiten.Vertex{
+			DstX:   float32(x),
+			DstY:   float32(y),
+			SrcX:   srcX + float32(corner.X),
+			SrcY:   srcY + float32(corner.Y),
+			ColorR: r,
+			ColorG: g,
+			ColorB: b,
+			ColorA: a,
+		})
+	}
+
+	if s.Shader == nil || !s.Shader.Enabled {
+		if s.GetFilter() == FilterPixelated {
+			drawPixelatedRepeated(dst, srcImage, vertices, drawOptions)
+			return
+		}
+		var options ebiten.DrawTrianglesOptions
+		options.Blend = drawOptions.Blend
+		options.Filter = drawOptions.Filter
+		options.Address = ebiten.AddressRepeat
+		options.ColorScaleMode = ebiten.ColorScaleModePremultipliedAlpha
+		dst.DrawTriangles(vertices, quadIndices, srcImage, &options)
+		return
+	}
+
+	var options ebiten.DrawTrianglesShaderOptions
+	options.Blend = drawOptions.Blend
+	options.Images[0] = srcImage
+	options.Images[1] = s.Shader.Texture1
+	options.Images[2] = s.Shader.Texture2
+	options.Images[3] = s.Shader.Texture3
+	options.Uniforms = s.Shader.shaderData
+	dst.DrawTrianglesShader(vertices, quadIndices, s.Shader.compiled, &options)
+}
+
+// drawNormalMapped renders the frame using the normal map lighting shader.
+// The GeoM inside drawOptions is used as is;
+// opts are used to map the dst pixels back to the world coordinates.
+func (s *Sprite) drawNormalMapped(dst, srcImage *ebiten.Image, drawOptions *ebiten.DrawImageOptions, opts *DrawOptions) {
+	lighting := s.extra.lighting
+	if !lighting.updateTransform(drawOptions.GeoM, opts, dst.Bounds().Min) {
+		return
+	}
+	lighting.updateLights()
+
+	normalImage := s.extra.normalSubImage
+	if normalImage == nil {
+		normalImage = s.extra.normalMap
+	}
+
+	srcImageBounds := srcImage.Bounds()
+	var options ebiten.DrawRectShaderOptions
+	options.Blend = drawOptions.Blend
+	options.GeoM = drawOptions.GeoM
+	options.ColorScale = drawOptions.ColorScale
+	options.Images[0] = srcImage
+	options.Images[1] = normalImage
+	options.Uniforms = lighting.shader.shaderData
+	dst.DrawRectShader(srcImageBounds.Dx(), srcImageBounds.Dy(), lighting.shader.compiled, &options)
+}
+
+// drawSize returns the sprite logical size.
+// It's a frame size unless the repeat mode is enabled.
+func (s *Sprite) drawSize() (w, h uint16) {
+	if s.extra != nil && s.extra.isRepeated() {
+		return s.extra.repeatWidth, s.extra.repeatHeight
+	}
+	return s.frameWidth, s.frameHeight
+}
+
+func (extra *spriteExtraData) applyTransform(geom *ebiten.GeoM) {
+	if extra.skewX != 0 || extra.skewY != 0 {
+		geom.Skew(float64(extra.skewX), float64(extra.skewY))
+	}
+	if extra.geom != (ebiten.GeoM{}) {
+		geom.Concat(extra.geom)
+	}
+}
+
+func (s *Sprite) transformedBoundsRect() gmath.Rect {
+	sizeX, sizeY := s.drawSize()
+	w := float64(sizeX)
+	h := float64(sizeY)
+
+	// The flips map the frame rect onto itself,
+	// so the corners can be transformed as is.
+	var geom ebiten.GeoM
+	s.buildGeoM(&geom)
+
+	x, y := geom.Apply(0, 0)
+	bounds := gmath.Rect{
+		Min: gmath.Vec{X: x, Y: y},
+		Max: gmath.Vec{X: x, Y: y},
+	}
+	for _, corner := range [...]gmath.Vec{{X: w}, {Y: h}, {X: w, Y: h}} {
+		x, y := geom.Apply(corner.X, corner.Y)
+		bounds.Min.X = min(bounds.Min.X, x)
+		bounds.Min.Y = min(bounds.Min.Y, y)
+		bounds.Max.X = max(bounds.Max.X, x)
+		bounds.Max.Y = max(bounds.Max.Y, y)
+	}
+	return bounds
+}
+
+func (s *Sprite) getExtra() *spriteExtraData {
+	if s.extra == nil {
+		s.extra = &spriteExtraData{}
+	}
+	return s.extra
+}
+
 func (s *Sprite) calculatePos() gmath.Vec {
 	pos := s.Pos.Resolve()
 	if !s.PivotOffset.IsZero() {
@@ -461,6 +976,9 @@ func (s *Sprite) updateSubImage() {
 		s.frameHeight != uint16(imageBounds.Dy())
 	if !needSubImage {
 		s.subImage = nil
+		if s.extra != nil {
+			s.extra.normalSubImage = nil
+		}
 		return
 	}
 
@@ -475,6 +993,9 @@ func (s *Sprite) updateSubImage() {
 		},
 	}
 	s.subImage = s.image.SubImage(subImageBounds).(*ebiten.Image)
+	if s.extra != nil && s.extra.normalMap != nil {
+		s.extra.normalSubImage = s.extra.normalMap.SubImage(subImageBounds).(*ebiten.Image)
+	}
 }
 
 func (s *Sprite) getFlag(f spriteFlag) bool {
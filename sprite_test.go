@@ -1,10 +1,13 @@
 package graphics_test
 
 import (
+	"math"
 	"testing"
 	"unsafe"
 
+	"github.com/hajimehoshi/ebiten/v2"
 	graphics "github.com/quasilyte/ebitengine-graphics"
+	"github.com/quasilyte/gmath"
 )
 
 func TestSpriteSize(t *testing.T) {
@@ -15,9 +18,106 @@ func TestSpriteSize(t *testing.T) {
 	// Sprite objects are heap-allocated in 99.9% of cases.
 	// They're also one of the most common used type of graphics in an average game.
 	// This means that their size matters.
-	wantSize := uintptr(136)
+	wantSize := uintptr(144)
 	haveSize := unsafe.Sizeof(graphics.Sprite{})
 	if wantSize != haveSize {
 		t.Fatalf("sizeof(Sprite):\nhave: %d\nwant: %d", haveSize, wantSize)
 	}
 }
+
+func TestSpriteTransformedBounds(t *testing.T) {
+	newSprite := func() *graphics.Sprite {
+		s := graphics.NewSprite()
+		s.SetImage(ebiten.NewImage(20, 10))
+		s.Pos.Offset = gmath.Vec{X: 100, Y: 100}
+		return s
+	}
+
+	tests := []struct {
+		name  string
+		setup func(s *graphics.Sprite)
+		want  gmath.Rect
+	}{
+		{
+			name: "translate",
+			setup: func(s *graphics.Sprite) {
+				var m ebiten.GeoM
+				m.Translate(5, 0)
+				s.SetExtraGeoM(m)
+			},
+			want: gmath.Rect{Min: gmath.Vec{X: 95, Y: 95}, Max: gmath.Vec{X: 115, Y: 105}},
+		},
+		{
+			name: "translate flipped",
+			setup: func(s *graphics.Sprite) {
+				var m ebiten.GeoM
+				m.Translate(5, 0)
+				s.SetExtraGeoM(m)
+				s.SetHorizontalFlip(true)
+				s.SetVerticalFlip(true)
+			},
+			want: gmath.Rect{Min: gmath.Vec{X: 95, Y: 95}, Max: gmath.Vec{X: 115, Y: 105}},
+		},
+		{
+			name: "scale not centered",
+			setup: func(s *graphics.Sprite) {
+				var m ebiten.GeoM
+				m.Scale(2, 1)
+				s.SetExtraGeoM(m)
+				s.SetCentered(false)
+				s.SetScaleY(2)
+			},
+			want: gmath.Rect{Min: gmath.Vec{X: 100, Y: 100}, Max: gmath.Vec{X: 140, Y: 120}},
+		},
+		{
+			name: "skew",
+			setup: func(s *graphics.Sprite) {
+				s.SetSkewX(math.Pi / 4)
+			},
+			want: gmath.Rect{Min: gmath.Vec{X: 85, Y: 95}, Max: gmath.Vec{X: 115, Y: 105}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := newSprite()
+			test.setup(s)
+			have := s.BoundsRect()
+			if !rectApproxEqual(have, test.want) {
+				t.Fatalf("bounds:\nhave: %v\nwant: %v", have, test.want)
+			}
+		})
+	}
+}
+
+func rectApproxEqual(a, b gmath.Rect) bool {
+	const epsilon = 1e-9
+	return math.Abs(a.Min.X-b.Min.X) < epsilon &&
+		math.Abs(a.Min.Y-b.Min.Y) < epsilon &&
+		math.Abs(a.Max.X-b.Max.X) < epsilon &&
+		math.Abs(a.Max.Y-b.Max.Y) < epsilon
+}
+
+func TestSpriteDrawRepeated(t *testing.T) {
+	graphics.CompileShaders()
+
+	filters := []graphics.Filter{
+		graphics.FilterNearest,
+		graphics.FilterLinear,
+		graphics.FilterPixelated,
+	}
+	dst := ebiten.NewImage(64, 64)
+	for _, f := range filters {
+		s := graphics.NewSprite()
+		s.SetImage(ebiten.NewImage(8, 8))
+		s.SetRepeatSize(40, 20)
+		s.SetUVOffset(gmath.Vec{X: -3, Y: 11})
+		s.SetScaleX(1.5)
+		s.SetFilter(f)
+		s.Pos.Offset = gmath.Vec{X: 32, Y: 32}
+		s.Draw(dst)
+		if s.GetFilter() != f {
+			t.Fatalf("have filter %v, want %v", s.GetFilter(), f)
+		}
+	}
+}
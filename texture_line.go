@@ -9,6 +9,20 @@ import (
 	"github.com/quasilyte/gmath"
 )
 
+// TextureLineMode controls how the texture is mapped onto the line body.
+type TextureLineMode uint8
+
+const (
+	// TextureLineModeRepeat repeats the texture in texture-width steps.
+	// The last segment is cut to fit the line length.
+	// This is the default mode.
+	TextureLineModeRepeat TextureLineMode = iota
+
+	// TextureLineModeStretch stretches the texture to fit the line length.
+	// This mode is well-suited for things like laser beams.
+	TextureLineModeStretch
+)
+
 type TextureLine struct {
 	BeginPos gmath.Pos
 	EndPos   gmath.Pos
@@ -24,6 +38,13 @@ type TextureLine struct {
 	texture    *ebiten.Image
 	texturePad float64
 
+	beginCap *ebiten.Image
+	endCap   *ebiten.Image
+
+	uvOffset float64
+
+	mode TextureLineMode
+
 	visible  bool
 	disposed bool
 }
@@ -89,21 +110,69 @@ func (l *TextureLine) IsVisible() bool { return l.visible }
 // Use IsVisible to get the current flag value.
 func (l *TextureLine) SetVisibility(visible bool) { l.visible = visible }
 
+// GetMode returns the current texture mapping mode.
+// Use SetMode to change it.
+func (l *TextureLine) GetMode() TextureLineMode { return l.mode }
+
+// SetMode changes the texture mapping mode.
+// Use GetMode to retrieve the current value.
+//
+// See [TextureLineMode] for the available options.
+func (l *TextureLine) SetMode(mode TextureLineMode) { l.mode = mode }
+
+// GetUVOffset returns the current texture offset.
+// Use SetUVOffset to change it.
+func (l *TextureLine) GetUVOffset() float64 { return l.uvOffset }
+
+// SetUVOffset changes the texture offset along the line (in texture pixels).
+// Use GetUVOffset to retrieve the current value.
+//
+// The offset wraps around the texture width.
+// Changing this value over time makes the texture scroll along the line;
+// increasing it moves the texture towards the BeginPos.
+func (l *TextureLine) SetUVOffset(offset float64) { l.uvOffset = offset }
+
 // SetTexture assigns the repeatable line texture.
 //
 // This texture should loop well if the line's length can be higher
 // than the texture's width.
 func (l *TextureLine) SetTexture(texture *ebiten.Image) {
 	l.texture = texture
-
-	bounds := texture.Bounds()
-	l.texturePad = 2 + math.Ceil((0.5 * float64(bounds.Dy())))
+	l.updateTexturePad()
 }
 
 func (l *TextureLine) GetTexture() *ebiten.Image {
 	return l.texture
 }
 
+// SetBeginCap assigns a texture that is drawn at the BeginPos.
+// A nil value removes the cap.
+//
+// The cap is drawn as is (it's never repeated or stretched),
+// the line body starts right after it.
+func (l *TextureLine) SetBeginCap(texture *ebiten.Image) {
+	l.beginCap = texture
+	l.updateTexturePad()
+}
+
+func (l *TextureLine) GetBeginCap() *ebiten.Image {
+	return l.beginCap
+}
+
+// SetEndCap assigns a texture that is drawn at the EndPos.
+// A nil value removes the cap.
+//
+// The cap is drawn as is (it's never repeated or stretched),
+// the line body ends right before it.
+func (l *TextureLine) SetEndCap(texture *ebiten.Image) {
+	l.endCap = texture
+	l.updateTexturePad()
+}
+
+func (l *TextureLine) GetEndCap() *ebiten.Image {
+	return l.endCap
+}
+
 // Draw renders the texture line onto the provided dst image.
 //
 // This method is a shorthand to DrawWithOptions(dst, {})
@@ -117,15 +186,91 @@ func (l *TextureLine) Draw(dst *ebiten.Image) {
 // DrawWithOptions renders the texture line onto the provided dst image
 // while also using the extra provided offset and other options.
 //
-// The offset is applied to both begin and end positions.
+// The options transform (like a parent [Container] transform) is applied
+// to both begin and end positions, so the rotation and scaling happen
+// around the parent origin, not around the BeginPos.
+// The parent scaling also affects the line thickness and the texture scale
+// (a non-uniform scaling is reduced to a single factor, sqrt(|x*y|)).
 func (l *TextureLine) DrawWithOptions(dst *ebiten.Image, opts DrawOptions) {
 	if !l.visible || l.colorScale.A == 0 {
 		return
 	}
 
-	beginVec := l.BeginPos.Resolve().Add(opts.Offset).AsVec32()
-	endVec := l.EndPos.Resolve().Add(opts.Offset).AsVec32()
+	beginVec := opts.transformPos(l.BeginPos.Resolve()).AsVec32()
+	endVec := opts.transformPos(l.EndPos.Resolve()).AsVec32()
+
+	length := beginVec.DistanceTo(endVec)
+	if length == 0 {
+		return
+	}
+	dir := beginVec.DirectionTo(endVec)
+
+	// Since rotation is identical for every quad, precompute
+	// the rotation here and copy this data for every quad.
+	// Rotation involves operations like Sincos and several
+	// multiplications, so copying is faster.
+	// The parent scaling affects the line thickness and the texture scale.
+	scale := float32(opts.getUniformScale())
+	var geomBase xmath.Geom32
+	if scale != 1 {
+		geomBase.Scale(1, scale)
+	}
+	geomBase.Rotate(float64(dir.Angle()))
+
+	clr := opts.multiplyColorScale(l.colorScale)
+	clr = clr.premultiplyAlpha()
+
+	layout := l.layout(beginVec, endVec, scale)
+	if layout.bodyLength > 0 {
+		l.drawBody(dst, opts.Blend, geomBase, layout.bodyPos, dir, layout.bodyLength, scale, clr)
+	}
+	if l.beginCap != nil {
+		l.drawCap(dst, opts.Blend, l.beginCap, geomBase, beginVec, layout.beginCapWidth, clr)
+	}
+	if l.endCap != nil {
+		l.drawCap(dst, opts.Blend, l.endCap, geomBase, layout.endCapPos, layout.endCapWidth, clr)
+	}
+}
+
+// textureLineLayout describes the line parts placement.
+// The begin cap always starts at the line begin pos.
+type textureLineLayout struct {
+	beginCapWidth float32
+	endCapWidth   float32
+	endCapPos     gmath.Vec32
 
+	bodyPos    gmath.Vec32
+	bodyLength float32
+}
+
+func (l *TextureLine) layout(beginVec, endVec gmath.Vec32, scale float32) textureLineLayout {
+	length := beginVec.DistanceTo(endVec)
+	dir := beginVec.DirectionTo(endVec)
+
+	// Caps are never scaled unless the line is too short to fit them.
+	// In that case, both caps are shrinked proportionally.
+	var result textureLineLayout
+	if l.beginCap != nil {
+		result.beginCapWidth = float32(l.beginCap.Bounds().Dx()) * scale
+	}
+	if l.endCap != nil {
+		result.endCapWidth = float32(l.endCap.Bounds().Dx()) * scale
+	}
+	capsWidth := result.beginCapWidth + result.endCapWidth
+	if capsWidth > length {
+		k := length / capsWidth
+		result.beginCapWidth *= k
+		result.endCapWidth *= k
+		capsWidth = length
+	}
+
+	result.endCapPos = endVec.Sub(dir.Mulf(result.endCapWidth))
+	result.bodyPos = beginVec.Add(dir.Mulf(result.beginCapWidth))
+	result.bodyLength = length - capsWidth
+	return result
+}
+
+func (l *TextureLine) drawBody(dst *ebiten.Image, blend *ebiten.Blend, geomBase xmath.Geom32, pos, dir gmath.Vec32, length, scale float32, clr ColorScale) {
 	vertices := cache.Global.ScratchVertices[:0]
 	indices := cache.Global.ScratchIndices[:0]
 	defer func() {
@@ -133,73 +278,108 @@ func (l *TextureLine) DrawWithOptions(dst *ebiten.Image, opts DrawOptions) {
 		cache.Global.ScratchIndices = indices[:0]
 	}()
 
+	vertices, indices = l.appendBody(vertices, indices, geomBase, pos, dir, length, scale, clr)
+	l.drawTriangles(dst, blend, l.texture, vertices, indices)
+}
+
+func (l *TextureLine) appendBody(vertices []ebiten.Vertex, indices []uint16, geomBase xmath.Geom32, pos, dir gmath.Vec32, length, scale float32, clr ColorScale) ([]ebiten.Vertex, []uint16) {
 	textureWidth := float32(l.texture.Bounds().Dx())
 	textureHeight := float32(l.texture.Bounds().Dy())
 
-	halfHeight := textureHeight * 0.5
-
-	clr := l.colorScale.premultiplyAlpha()
-
-	step := beginVec.DirectionTo(endVec).Mulf(textureWidth)
-	numSteps := int(beginVec.DistanceTo(endVec)/textureWidth) + 1
-	lastStep := numSteps - 1
+	// srcLength is a number of texture pixels that needs to be mapped
+	// onto the line body; dstScale is a number of line pixels per texture pixel.
+	srcLength := length / scale
+	dstScale := scale
+	if l.mode == TextureLineModeStretch {
+		srcLength = textureWidth
+		dstScale = length / textureWidth
+	}
 
-	angle := float64(step.Angle())
+	srcX := float32(fposmod(l.uvOffset, float64(textureWidth)))
 
-	// Since rotation is identical for every quad, precompute
-	// the rotation here and copy this data for every quad.
-	// Rotation involves operations like Sincos and several
-	// multiplications, so copying is faster.
-	var geomBase xmath.Geom32
-	geomBase.Translate(0, -halfHeight)
-	geomBase.Rotate(angle)
-
-	currentPos := beginVec
-	idx := uint16(0)
-	for i := 0; i < numSteps; i++ {
-		geom := geomBase
-		geom.Translate(currentPos.X, currentPos.Y)
-
-		x := geom.Tx
-		y := geom.Ty
-		w := textureWidth
-		h := textureHeight
-		if i == lastStep {
-			w = currentPos.DistanceTo(endVec)
-		}
+	// Every quad maps a [srcX, srcX+w] texture range onto the line.
+	// A quad never crosses the texture's right edge, so the
+	// texture offset produces a partial first quad.
+	idx := uint16(len(vertices))
+	for srcLength > 0 {
+		w := min(textureWidth-srcX, srcLength)
+		dstWidth := w * dstScale
 
-		vertices = append(vertices,
-			ebiten.Vertex{DstX: x, DstY: y, SrcX: 0, SrcY: 0, ColorR: clr.R, ColorG: clr.G, ColorB: clr.B, ColorA: clr.A},
-			ebiten.Vertex{DstX: (geom.A1+1)*w + x, DstY: geom.C*w + y, SrcX: w, SrcY: 0, ColorR: clr.R, ColorG: clr.G, ColorB: clr.B, ColorA: clr.A},
-			ebiten.Vertex{DstX: geom.B*h + x, DstY: (geom.D1+1)*h + y, SrcX: 0, SrcY: h, ColorR: clr.R, ColorG: clr.G, ColorB: clr.B, ColorA: clr.A},
-			ebiten.Vertex{DstX: geom.ApplyX(w, h), DstY: geom.ApplyY(w, h), SrcX: w, SrcY: h, ColorR: clr.R, ColorG: clr.G, ColorB: clr.B, ColorA: clr.A},
-		)
+		vertices = appendTextureLineQuad(vertices, geomBase, pos, srcX, w, dstWidth, textureHeight, clr)
 		indices = append(indices,
 			idx+0, idx+1, idx+2,
 			idx+1, idx+2, idx+3,
 		)
-
-		currentPos = currentPos.Add(step)
 		idx += 4
+
+		pos = pos.Add(dir.Mulf(dstWidth))
+		srcLength -= w
+		srcX = 0
 	}
 
+	return vertices, indices
+}
+
+func (l *TextureLine) drawCap(dst *ebiten.Image, blend *ebiten.Blend, texture *ebiten.Image, geomBase xmath.Geom32, pos gmath.Vec32, dstWidth float32, clr ColorScale) {
+	vertices := cache.Global.ScratchVertices[:0]
+	defer func() {
+		cache.Global.ScratchVertices = vertices[:0]
+	}()
+
+	bounds := texture.Bounds()
+	vertices = appendTextureLineQuad(vertices, geomBase, pos, 0, float32(bounds.Dx()), dstWidth, float32(bounds.Dy()), clr)
+	l.drawTriangles(dst, blend, texture, vertices, quadIndices)
+}
+
+func (l *TextureLine) drawTriangles(dst *ebiten.Image, blend *ebiten.Blend, texture *ebiten.Image, vertices []ebiten.Vertex, indices []uint16) {
 	if l.Shader == nil || !l.Shader.Enabled {
 		var drawOptions ebiten.DrawTrianglesOptions
-		if opts.Blend != nil {
-			drawOptions.Blend = *opts.Blend
+		if blend != nil {
+			drawOptions.Blend = *blend
 		}
-		dst.DrawTriangles(vertices, indices, l.texture, &drawOptions)
+		dst.DrawTriangles(vertices, indices, texture, &drawOptions)
 		return
 	}
 
 	var drawOptions ebiten.DrawTrianglesShaderOptions
-	if opts.Blend != nil {
-		drawOptions.Blend = *opts.Blend
+	if blend != nil {
+		drawOptions.Blend = *blend
 	}
-	drawOptions.Images[0] = l.texture
+	drawOptions.Images[0] = texture
 	drawOptions.Images[1] = l.Shader.Texture1
 	drawOptions.Images[2] = l.Shader.Texture2
 	drawOptions.Images[3] = l.Shader.Texture3
 	drawOptions.Uniforms = l.Shader.shaderData
 	dst.DrawTrianglesShader(vertices, indices, l.Shader.compiled, &drawOptions)
 }
+
+func (l *TextureLine) updateTexturePad() {
+	h := 0
+	for _, img := range [...]*ebiten.Image{l.texture, l.beginCap, l.endCap} {
+		if img != nil {
+			h = max(h, img.Bounds().Dy())
+		}
+	}
+	l.texturePad = 2 + math.Ceil((0.5 * float64(h)))
+}
+
+// appendTextureLineQuad appends a quad that maps [srcX, srcX+srcWidth] texture
+// range onto the line segment of dstWidth length that starts at pos.
+// The quad is centered vertically around the line.
+func appendTextureLineQuad(vertices []ebiten.Vertex, geomBase xmath.Geom32, pos gmath.Vec32, srcX, srcWidth, dstWidth, h float32, clr ColorScale) []ebiten.Vertex {
+	geom := geomBase
+	// Instead of translating by -h/2 before the rotation,
+	// apply the rotated vertical offset here.
+	geom.Translate(pos.X-geom.B*h*0.5, pos.Y-(geom.D1+1)*h*0.5)
+
+	x := geom.Tx
+	y := geom.Ty
+	w := dstWidth
+	srcX1 := srcX + srcWidth
+	return append(vertices,
+		ebiten.Vertex{DstX: x, DstY: y, SrcX: srcX, SrcY: 0, ColorR: clr.R, ColorG: clr.G, ColorB: clr.B, ColorA: clr.A},
+		ebiten.Vertex{DstX: (geom.A1+1)*w + x, DstY: geom.C*w + y, SrcX: srcX1, SrcY: 0, ColorR: clr.R, ColorG: clr.G, ColorB: clr.B, ColorA: clr.A},
+		ebiten.Vertex{DstX: geom.B*h + x, DstY: (geom.D1+1)*h + y, SrcX: srcX, SrcY: h, ColorR: clr.R, ColorG: clr.G, ColorB: clr.B, ColorA: clr.A},
+		ebiten.Vertex{DstX: geom.ApplyX(w, h), DstY: geom.ApplyY(w, h), SrcX: srcX1, SrcY: h, ColorR: clr.R, ColorG: clr.G, ColorB: clr.B, ColorA: clr.A},
+	)
+}
@@ -0,0 +1,296 @@
+package graphics
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/quasilyte/ebitengine-graphics/internal/xmath"
+	"github.com/quasilyte/gmath"
+)
+
+func float32ApproxEqual(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 1e-4
+}
+
+func TestTextureLineCapsLayout(t *testing.T) {
+	tests := []struct {
+		name     string
+		begin    gmath.Vec32
+		end      gmath.Vec32
+		scale    float32
+		beginCap int
+		endCap   int
+		want     textureLineLayout
+	}{
+		{
+			name:     "both caps",
+			end:      gmath.Vec32{X: 100},
+			scale:    1,
+			beginCap: 10,
+			endCap:   6,
+			want: textureLineLayout{
+				beginCapWidth: 10,
+				endCapWidth:   6,
+				endCapPos:     gmath.Vec32{X: 94},
+				bodyPos:       gmath.Vec32{X: 10},
+				bodyLength:    84,
+			},
+		},
+		{
+			name:     "scaled caps",
+			end:      gmath.Vec32{X: 100},
+			scale:    2,
+			beginCap: 10,
+			endCap:   6,
+			want: textureLineLayout{
+				beginCapWidth: 20,
+				endCapWidth:   12,
+				endCapPos:     gmath.Vec32{X: 88},
+				bodyPos:       gmath.Vec32{X: 20},
+				bodyLength:    68,
+			},
+		},
+		{
+			name:     "diagonal",
+			begin:    gmath.Vec32{X: 10, Y: 10},
+			end:      gmath.Vec32{X: 40, Y: 50},
+			scale:    1,
+			beginCap: 10,
+			endCap:   5,
+			want: textureLineLayout{
+				beginCapWidth: 10,
+				endCapWidth:   5,
+				endCapPos:     gmath.Vec32{X: 37, Y: 46},
+				bodyPos:       gmath.Vec32{X: 16, Y: 18},
+				bodyLength:    35,
+			},
+		},
+		{
+			name:     "begin cap only",
+			end:      gmath.Vec32{X: 50},
+			scale:    1,
+			beginCap: 10,
+			want: textureLineLayout{
+				beginCapWidth: 10,
+				endCapPos:     gmath.Vec32{X: 50},
+				bodyPos:       gmath.Vec32{X: 10},
+				bodyLength:    40,
+			},
+		},
+		{
+			name:   "end cap only",
+			end:    gmath.Vec32{X: 50},
+			scale:  1,
+			endCap: 10,
+			want: textureLineLayout{
+				endCapWidth: 10,
+				endCapPos:   gmath.Vec32{X: 40},
+				bodyPos:     gmath.Vec32{},
+				bodyLength:  40,
+			},
+		},
+		{
+			name:     "caps fill the line",
+			end:      gmath.Vec32{X: 16},
+			scale:    1,
+			beginCap: 10,
+			endCap:   6,
+			want: textureLineLayout{
+				beginCapWidth: 10,
+				endCapWidth:   6,
+				endCapPos:     gmath.Vec32{X: 10},
+				bodyPos:       gmath.Vec32{X: 10},
+			},
+		},
+		{
+			// The caps are shrinked proportionally and the body is not drawn.
+			name:     "shrinked caps",
+			end:      gmath.Vec32{X: 8},
+			scale:    1,
+			beginCap: 10,
+			endCap:   6,
+			want: textureLineLayout{
+				beginCapWidth: 5,
+				endCapWidth:   3,
+				endCapPos:     gmath.Vec32{X: 5},
+				bodyPos:       gmath.Vec32{X: 5},
+			},
+		},
+		{
+			name:     "shrinked scaled caps",
+			end:      gmath.Vec32{Y: -16},
+			scale:    2,
+			beginCap: 10,
+			endCap:   6,
+			want: textureLineLayout{
+				beginCapWidth: 10,
+				endCapWidth:   6,
+				endCapPos:     gmath.Vec32{Y: -10},
+				bodyPos:       gmath.Vec32{Y: -10},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		l := NewTextureLine(gmath.Pos{}, gmath.Pos{})
+		l.SetTexture(ebiten.NewImage(4, 4))
+		if test.beginCap != 0 {
+			l.SetBeginCap(ebiten.NewImage(test.beginCap, 4))
+		}
+		if test.endCap != 0 {
+			l.SetEndCap(ebiten.NewImage(test.endCap, 4))
+		}
+
+		have := l.layout(test.begin, test.end, test.scale)
+		if !float32ApproxEqual(have.beginCapWidth, test.want.beginCapWidth) ||
+			!float32ApproxEqual(have.endCapWidth, test.want.endCapWidth) ||
+			!float32ApproxEqual(have.bodyLength, test.want.bodyLength) ||
+			!float32ApproxEqual(have.endCapPos.DistanceTo(test.want.endCapPos), 0) ||
+			!float32ApproxEqual(have.bodyPos.DistanceTo(test.want.bodyPos), 0) {
+			t.Errorf("%s:\nhave: %+v\nwant: %+v", test.name, have, test.want)
+		}
+	}
+}
+
+func TestTextureLineBody(t *testing.T) {
+	// textureLineQuad is a [dstX0, dstX1] line segment
+	// that is mapped to [srcX0, srcX1] texture range.
+	type textureLineQuad struct {
+		dstX0, dstX1 float32
+		srcX0, srcX1 float32
+	}
+
+	tests := []struct {
+		name     string
+		mode     TextureLineMode
+		uvOffset float64
+		length   float32
+		scale    float32
+		want     []textureLineQuad
+	}{
+		{
+			name:   "repeat",
+			length: 25,
+			scale:  1,
+			want: []textureLineQuad{
+				{0, 10, 0, 10},
+				{10, 20, 0, 10},
+				{20, 25, 0, 5},
+			},
+		},
+		{
+			name:   "repeat scaled",
+			length: 25,
+			scale:  2,
+			want: []textureLineQuad{
+				{0, 20, 0, 10},
+				{20, 25, 0, 2.5},
+			},
+		},
+		{
+			name:     "repeat uv offset",
+			uvOffset: 3,
+			length:   25,
+			scale:    1,
+			want: []textureLineQuad{
+				{0, 7, 3, 10},
+				{7, 17, 0, 10},
+				{17, 25, 0, 8},
+			},
+		},
+		{
+			name:     "repeat negative uv offset",
+			uvOffset: -3,
+			length:   25,
+			scale:    1,
+			want: []textureLineQuad{
+				{0, 3, 7, 10},
+				{3, 13, 0, 10},
+				{13, 23, 0, 10},
+				{23, 25, 0, 2},
+			},
+		},
+		{
+			name:     "repeat uv offset wraps",
+			uvOffset: 23,
+			length:   8,
+			scale:    1,
+			want: []textureLineQuad{
+				{0, 7, 3, 10},
+				{7, 8, 0, 1},
+			},
+		},
+		{
+			name:   "stretch",
+			mode:   TextureLineModeStretch,
+			length: 25,
+			scale:  1,
+			want: []textureLineQuad{
+				{0, 25, 0, 10},
+			},
+		},
+		{
+			// The scale only affects the line thickness in the stretch mode.
+			name:   "stretch scaled",
+			mode:   TextureLineModeStretch,
+			length: 25,
+			scale:  2,
+			want: []textureLineQuad{
+				{0, 25, 0, 10},
+			},
+		},
+		{
+			name:     "stretch uv offset",
+			mode:     TextureLineModeStretch,
+			uvOffset: 3,
+			length:   25,
+			scale:    1,
+			want: []textureLineQuad{
+				{0, 17.5, 3, 10},
+				{17.5, 25, 0, 3},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		l := NewTextureLine(gmath.Pos{}, gmath.Pos{})
+		l.SetTexture(ebiten.NewImage(10, 4))
+		l.SetMode(test.mode)
+		l.SetUVOffset(test.uvOffset)
+
+		var geomBase xmath.Geom32
+		geomBase.Scale(1, test.scale)
+		vertices, indices := l.appendBody(nil, nil, geomBase, gmath.Vec32{}, gmath.Vec32{X: 1}, test.length, test.scale, defaultColorScale)
+
+		if len(vertices) != len(test.want)*4 || len(indices) != len(test.want)*6 {
+			t.Errorf("%s: have %d vertices and %d indices for %d quads", test.name, len(vertices), len(indices), len(test.want))
+			continue
+		}
+		for i, want := range test.want {
+			v := vertices[i*4:]
+			have := textureLineQuad{
+				dstX0: v[0].DstX,
+				dstX1: v[1].DstX,
+				srcX0: v[0].SrcX,
+				srcX1: v[1].SrcX,
+			}
+			if !float32ApproxEqual(have.dstX0, want.dstX0) || !float32ApproxEqual(have.dstX1, want.dstX1) ||
+				!float32ApproxEqual(have.srcX0, want.srcX0) || !float32ApproxEqual(have.srcX1, want.srcX1) {
+				t.Errorf("%s: quad %d:\nhave: %+v\nwant: %+v", test.name, i, have, want)
+			}
+			if indices[i*6] != uint16(i*4) {
+				t.Errorf("%s: quad %d: have %d first index, want %d", test.name, i, indices[i*6], i*4)
+			}
+		}
+	}
+}
+
+func TestTextureLineDrawShort(t *testing.T) {
+	// A line that is shorter than its caps is drawn without a body.
+	l := NewTextureLine(gmath.Pos{Offset: gmath.Vec{X: 4, Y: 4}}, gmath.Pos{Offset: gmath.Vec{X: 10, Y: 4}})
+	l.SetTexture(ebiten.NewImage(10, 4))
+	l.SetBeginCap(ebiten.NewImage(8, 4))
+	l.SetEndCap(ebiten.NewImage(8, 4))
+	l.Draw(ebiten.NewImage(16, 16))
+}
@@ -0,0 +1,432 @@
+package graphics
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/quasilyte/ebitengine-graphics/internal/cache"
+	"github.com/quasilyte/gmath"
+)
+
+// Trail is a motion trail (ribbon) graphical primitive.
+//
+// It samples the Pos during every update and renders a triangle strip
+// that goes through the sampled points.
+// The strip width and alpha are tapered from the head (the most recent point)
+// to the tail (the oldest point).
+//
+// Trail needs to be updated, see [Trail.UpdateWithDelta].
+//
+// Trail implements gscene Graphics interface.
+type Trail struct {
+	// Pos is a trail head location binder.
+	// It's usually bound to the moving object position.
+	Pos gmath.Pos
+
+	// Shader is an shader that will be used during rendering of the trail.
+	// Use NewShader to initialize this field.
+	//
+	// The shader color argument is a premultiplied-alpha vertex color
+	// (the trail color scale with the tapered alpha applied).
+	//
+	// If nil, no shaders will be used.
+	Shader *Shader
+
+	texture *ebiten.Image
+
+	// points is a ring buffer of the sampled positions.
+	points     []trailPoint
+	drawPoints []trailPoint
+	head       int // The next write index
+	numPoints  int
+
+	colorScale ColorScale
+
+	width     float32
+	tailWidth float32
+	tailAlpha float32
+
+	maxAge      float32
+	maxLength   float32
+	minDistance float32
+
+	visible  bool
+	disposed bool
+}
+
+type trailPoint struct {
+	pos gmath.Vec32
+	age float32
+
+	// These are only used during the rendering.
+	dist float32
+}
+
+// NewTrail returns a trail that can hold up to maxPoints sampled points.
+// When the buffer is full, the oldest points are overwritten.
+//
+// By default, a trail has these properties:
+// * Visible=true
+// * The ColorScale is {1, 1, 1, 1}
+// * Width is 4, TailWidth is 0
+// * TailAlpha is 0
+// * MaxAge and MaxLength are 0 (unlimited)
+func NewTrail(maxPoints int) *Trail {
+	if maxPoints < 2 || maxPoints > maxTrailPoints {
+		panic("maxPoints is not in [2, 8192] bounds")
+	}
+	return &Trail{
+		points:     make([]trailPoint, maxPoints),
+		colorScale: defaultColorScale,
+		width:      4,
+		visible:    true,
+	}
+}
+
+// maxTrailPoints makes sure that the indices fit into uint16.
+const maxTrailPoints = 8192
+
+// BoundsRect returns a rectangle that fully contains the trail.
+//
+// This is useful when trying to calculate whether this object is contained
+// inside some area or not (like a camera view area).
+func (t *Trail) BoundsRect() gmath.Rect {
+	head := t.Pos.Resolve()
+	bounds := gmath.Rect{Min: head, Max: head}
+	for i := 0; i < t.numPoints; i++ {
+		p := t.points[t.pointIndex(i)].pos.AsVec64()
+		bounds.Min.X = min(bounds.Min.X, p.X)
+		bounds.Min.Y = min(bounds.Min.Y, p.Y)
+		bounds.Max.X = max(bounds.Max.X, p.X)
+		bounds.Max.Y = max(bounds.Max.Y, p.Y)
+	}
+	pad := 0.5 * float64(max(t.width, t.tailWidth))
+	bounds.Min = bounds.Min.Sub(gmath.Vec{X: pad, Y: pad})
+	bounds.Max = bounds.Max.Add(gmath.Vec{X: pad, Y: pad})
+	return bounds
+}
+
+// Dispose marks this trail for deletion.
+// After calling this method, IsDisposed will report true.
+func (t *Trail) Dispose() {
+	t.disposed = true
+}
+
+// IsDisposed reports whether this trail is marked for deletion.
+// IsDisposed returns true only after Disposed was called on this trail.
+func (t *Trail) IsDisposed() bool {
+	return t.disposed
+}
+
+// IsVisible reports whether this trail is visible.
+// Use SetVisibility to change this flag value.
+//
+// When trail is invisible (visible=false), it will not be rendered at all.
+// The invisible trail still samples its points during the update.
+func (t *Trail) IsVisible() bool { return t.visible }
+
+// SetVisibility changes the Visible flag value.
+// It can be used to show or hide the trail.
+// Use IsVisible to get the current flag value.
+func (t *Trail) SetVisibility(visible bool) { t.visible = visible }
+
+// GetColorScale is used to retrieve the current color scale value of the trail.
+// Use SetColorScale to change it.
+func (t *Trail) GetColorScale() ColorScale {
+	return t.colorScale
+}
+
+// SetColorScale assigns a new ColorScale to this trail.
+// Use GetColorScale to retrieve the current color scale.
+//
+// This color is used at the trail head.
+// The tail color alpha is affected by the TailAlpha setting.
+func (t *Trail) SetColorScale(cs ColorScale) {
+	t.colorScale = cs
+}
+
+// GetAlpha is a shorthand for GetColorScale().A expression.
+// It's mostly provided for a symmetry with SetAlpha.
+func (t *Trail) GetAlpha() float32 { return t.colorScale.A }
+
+// SetAlpha is a convenient way to change the alpha value of the ColorScale.
+func (t *Trail) SetAlpha(a float32) {
+	t.colorScale.A = a
+}
+
+// GetTailAlpha reports the current tail alpha multiplier.
+// Use SetTailAlpha to change it.
+func (t *Trail) GetTailAlpha() float32 { return t.tailAlpha }
+
+// SetTailAlpha changes the alpha multiplier used at the trail's tail.
+// The alpha is interpolated between the tail and the head.
+func (t *Trail) SetTailAlpha(a float32) { t.tailAlpha = a }
+
+// GetWidth reports the current trail head width.
+// Use SetWidth to change it.
+func (t *Trail) GetWidth() float64 { return float64(t.width) }
+
+// SetWidth changes the trail head width.
+// Use GetWidth to retrieve the current value.
+func (t *Trail) SetWidth(w float64) { t.width = float32(w) }
+
+// GetTailWidth reports the current trail tail width.
+// Use SetTailWidth to change it.
+func (t *Trail) GetTailWidth() float64 { return float64(t.tailWidth) }
+
+// SetTailWidth changes the trail tail width.
+// The width is interpolated between the tail and the head.
+func (t *Trail) SetTailWidth(w float64) { t.tailWidth = float32(w) }
+
+// GetMaxAge reports the current point max age.
+// Use SetMaxAge to change it.
+func (t *Trail) GetMaxAge() float64 { return float64(t.maxAge) }
+
+// SetMaxAge sets the point lifetime limit (in seconds).
+// Points that are older than that are removed during the update.
+// A zero value means "no limit".
+//
+// When max age is set, the tapering is based on the point's age.
+func (t *Trail) SetMaxAge(seconds float64) { t.maxAge = float32(seconds) }
+
+// GetMaxLength reports the current trail length limit.
+// Use SetMaxLength to change it.
+func (t *Trail) GetMaxLength() float64 { return float64(t.maxLength) }
+
+// SetMaxLength sets the trail length limit (in pixels).
+// The tail is cut to fit this length during the rendering.
+// A zero value means "no limit".
+func (t *Trail) SetMaxLength(length float64) { t.maxLength = float32(length) }
+
+// GetMinDistance reports the current sampling distance threshold.
+// Use SetMinDistance to change it.
+func (t *Trail) GetMinDistance() float64 { return float64(t.minDistance) }
+
+// SetMinDistance sets the minimal distance between the sampled points.
+// A new point is not recorded unless it's farther than that from the previous point.
+// Higher values make the trail cheaper to render, but less smooth.
+func (t *Trail) SetMinDistance(dist float64) { t.minDistance = float32(dist) }
+
+// SetTexture assigns the trail texture.
+// A nil value makes the trail a solid color strip.
+//
+// The texture is stretched along the trail: its left edge
+// is mapped to the tail and its right edge is mapped to the head.
+func (t *Trail) SetTexture(texture *ebiten.Image) {
+	t.texture = texture
+}
+
+func (t *Trail) GetTexture() *ebiten.Image {
+	return t.texture
+}
+
+// NumPoints reports the number of currently sampled points.
+func (t *Trail) NumPoints() int {
+	return t.numPoints
+}
+
+// Clear removes all sampled points.
+// It's useful when the trail source is teleported.
+func (t *Trail) Clear() {
+	t.numPoints = 0
+	t.head = 0
+}
+
+// Update is a shorthand for UpdateWithDelta(1.0/60.0).
+func (t *Trail) Update() {
+	t.UpdateWithDelta(1.0 / 60.0)
+}
+
+// UpdateWithDelta ages the sampled points and samples the current Pos.
+//
+// It should be called once per game tick.
+func (t *Trail) UpdateWithDelta(delta float64) {
+	dt := float32(delta)
+	for i := 0; i < t.numPoints; i++ {
+		t.points[t.pointIndex(i)].age += dt
+	}
+
+	// Expired points are always located at the tail.
+	if t.maxAge > 0 {
+		for t.numPoints > 0 && t.points[t.pointIndex(0)].age > t.maxAge {
+			t.numPoints--
+		}
+	}
+
+	pos := t.Pos.Resolve().AsVec32()
+	if t.numPoints != 0 {
+		last := t.points[t.pointIndex(t.numPoints-1)].pos
+		if last == pos || last.DistanceSquaredTo(pos) < t.minDistance*t.minDistance {
+			return
+		}
+	}
+
+	t.points[t.head] = trailPoint{pos: pos}
+	t.head = (t.head + 1) % len(t.points)
+	if t.numPoints < len(t.points) {
+		t.numPoints++
+	}
+}
+
+// Draw renders the trail onto the provided dst image.
+//
+// This method is a shorthand to DrawWithOptions(dst, {})
+// which also implements the gscene.Graphics interface.
+//
+// See DrawWithOptions for more info.
+func (t *Trail) Draw(dst *ebiten.Image) {
+	t.DrawWithOptions(dst, DrawOptions{})
+}
+
+// DrawWithOptions renders the trail onto the provided dst image
+// while also using the extra provided offset and other options.
+//
+// The offset is applied to every trail point.
+func (t *Trail) DrawWithOptions(dst *ebiten.Image, opts DrawOptions) {
+	if !t.visible || t.colorScale.A == 0 || t.numPoints == 0 {
+		return
+	}
+
+	points := t.collectDrawPoints()
+	if len(points) < 2 {
+		return
+	}
+	totalDist := points[len(points)-1].dist
+
+	vertices := cache.Global.ScratchVertices[:0]
+	indices := cache.Global.ScratchIndices[:0]
+	defer func() {
+		cache.Global.ScratchVertices = vertices[:0]
+		cache.Global.ScratchIndices = indices[:0]
+	}()
+
+	texture := t.texture
+	if texture == nil {
+		texture = whitePixel
+	}
+	textureBounds := texture.Bounds()
+	srcMin := gmath.Vec32{X: float32(textureBounds.Min.X), Y: float32(textureBounds.Min.Y)}
+	srcWidth := float32(textureBounds.Dx())
+	srcHeight := float32(textureBounds.Dy())
+
+	transformed := opts.hasTransform()
+	offset := opts.Offset.AsVec32()
+	lastIndex := len(points) - 1
+	for i, p := range points {
+		// The direction is calculated using both neighbours (if possible),
+		// this makes the strip joints look smoother.
+		var dir gmath.Vec32
+		switch i {
+		case 0:
+			dir = p.pos.Sub(points[1].pos)
+		case lastIndex:
+			dir = points[i-1].pos.Sub(p.pos)
+		default:
+			dir = points[i-1].pos.Sub(points[i+1].pos)
+		}
+		normal := gmath.Vec32{X: -dir.Y, Y: dir.X}.Normalized()
+
+		// f is 1 at the head and 0 at the tail.
+		f := t.taperFactor(p, i, lastIndex)
+		halfWidth := 0.5 * gmath.Lerp(t.tailWidth, t.width, f)
+		a := t.colorScale.A * gmath.Lerp(t.tailAlpha, 1, f)
+		clr := opts.multiplyColorScale(ColorScale{R: t.colorScale.R, G: t.colorScale.G, B: t.colorScale.B, A: a})
+		clr = clr.premultiplyAlpha()
+
+		// The head is mapped to the texture right edge.
+		srcX := srcMin.X + srcWidth*(1-p.dist/totalDist)
+
+		var v1, v2 gmath.Vec32
+		if transformed {
+			v1 = opts.transformPos(p.pos.Add(normal.Mulf(halfWidth)).AsVec64()).AsVec32()
+			v2 = opts.transformPos(p.pos.Sub(normal.Mulf(halfWidth)).AsVec64()).AsVec32()
+		} else {
+			pos := p.pos.Add(offset)
+			v1 = pos.Add(normal.Mulf(halfWidth))
+			v2 = pos.Sub(normal.Mulf(halfWidth))
+		}
+		vertices = append(vertices,
+			ebiten.Vertex{DstX: v1.X, DstY: v1.Y, SrcX: srcX, SrcY: srcMin.Y, ColorR: clr.R, ColorG: clr.G, ColorB: clr.B, ColorA: clr.A},
+			ebiten.Vertex{DstX: v2.X, DstY: v2.Y, SrcX: srcX, SrcY: srcMin.Y + srcHeight, ColorR: clr.R, ColorG: clr.G, ColorB: clr.B, ColorA: clr.A},
+		)
+		if i != 0 {
+			idx := uint16(2 * (i - 1))
+			indices = append(indices,
+				idx+0, idx+1, idx+2,
+				idx+1, idx+2, idx+3,
+			)
+		}
+	}
+
+	var blend ebiten.Blend
+	if opts.Blend != nil {
+		blend = *opts.Blend
+	}
+
+	// The vertex colors are premultiplied in both paths:
+	// DrawTriangles is told so via the color scale mode and
+	// the shader receives the same premultiplied values as its color argument.
+	if t.Shader == nil || !t.Shader.Enabled {
+		var drawOptions ebiten.DrawTrianglesOptions
+		drawOptions.Blend = blend
+		drawOptions.ColorScaleMode = ebiten.ColorScaleModePremultipliedAlpha
+		dst.DrawTriangles(vertices, indices, texture, &drawOptions)
+		return
+	}
+	t.Shader.DrawTriangles(dst, vertices, indices, texture, blend)
+}
+
+// collectDrawPoints returns the points in head-to-tail order.
+// The current Pos is used as a head to avoid the 1-frame lag.
+// The tail is cut if it exceeds the max length.
+func (t *Trail) collectDrawPoints() []trailPoint {
+	points := t.drawPoints[:0]
+
+	head := t.Pos.Resolve().AsVec32()
+	points = append(points, trailPoint{pos: head})
+
+	dist := float32(0)
+	prev := head
+	for i := t.numPoints - 1; i >= 0; i-- {
+		p := t.points[t.pointIndex(i)]
+		if p.pos == prev {
+			continue
+		}
+		segmentLength := prev.DistanceTo(p.pos)
+		if t.maxLength > 0 && dist+segmentLength > t.maxLength {
+			// Interpolate the last point to fit the length limit exactly.
+			k := (t.maxLength - dist) / segmentLength
+			p.pos = prev.Add(p.pos.Sub(prev).Mulf(k))
+			p.age = gmath.Lerp(points[len(points)-1].age, p.age, k)
+			p.dist = t.maxLength
+			points = append(points, p)
+			break
+		}
+		dist += segmentLength
+		p.dist = dist
+		points = append(points, p)
+		prev = p.pos
+	}
+
+	t.drawPoints = points
+	return points
+}
+
+func (t *Trail) taperFactor(p trailPoint, i, lastIndex int) float32 {
+	if t.maxAge == 0 && t.maxLength == 0 {
+		return 1 - float32(i)/float32(lastIndex)
+	}
+	f := float32(1)
+	if t.maxAge > 0 {
+		f = min(f, 1-p.age/t.maxAge)
+	}
+	if t.maxLength > 0 {
+		f = min(f, 1-p.dist/t.maxLength)
+	}
+	return gmath.Clamp(f, 0, 1)
+}
+
+// pointIndex maps the logical index (0 is the oldest point)
+// to the ring buffer index.
+func (t *Trail) pointIndex(i int) int {
+	n := len(t.points)
+	return (t.head - t.numPoints + i + n) % n
+}
@@ -0,0 +1,148 @@
+package graphics
+
+import (
+	"testing"
+
+	"github.com/quasilyte/gmath"
+)
+
+// trailPositions returns the sampled points X coords in oldest-to-newest order.
+func trailPositions(t *Trail) []float32 {
+	var result []float32
+	for i := 0; i < t.numPoints; i++ {
+		result = append(result, t.points[t.pointIndex(i)].pos.X)
+	}
+	return result
+}
+
+func equalFloats(a, b []float32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestTrailRingBuffer(t *testing.T) {
+	var pos gmath.Vec
+	trail := NewTrail(4)
+	trail.Pos.Base = &pos
+
+	for i := 0; i < 10; i++ {
+		pos.X = float64(i * 10)
+		trail.Update()
+	}
+
+	if trail.NumPoints() != 4 {
+		t.Fatalf("have %d points, want 4", trail.NumPoints())
+	}
+	want := []float32{60, 70, 80, 90}
+	if have := trailPositions(trail); !equalFloats(have, want) {
+		t.Fatalf("points:\nhave: %v\nwant: %v", have, want)
+	}
+
+	bounds := trail.BoundsRect()
+	if bounds.Min.X > 58 || bounds.Max.X < 92 || bounds.Min.X < 57 {
+		t.Fatalf("unexpected bounds %v", bounds)
+	}
+
+	trail.Clear()
+	if trail.NumPoints() != 0 {
+		t.Fatalf("have %d points after Clear", trail.NumPoints())
+	}
+	pos.X = 5
+	trail.Update()
+	if have := trailPositions(trail); !equalFloats(have, []float32{5}) {
+		t.Fatalf("points after Clear: %v", have)
+	}
+}
+
+func TestTrailPointExpiry(t *testing.T) {
+	var pos gmath.Vec
+	trail := NewTrail(16)
+	trail.Pos.Base = &pos
+	trail.SetMaxAge(0.04)
+
+	for i := 0; i < 20; i++ {
+		pos.X = float64(i)
+		trail.Update()
+		if trail.NumPoints() > 3 {
+			t.Fatalf("update %d: have %d points, want at most 3", i, trail.NumPoints())
+		}
+	}
+
+	// Only the most recent points survive; the oldest ones expire first.
+	want := []float32{17, 18, 19}
+	if have := trailPositions(trail); !equalFloats(have, want) {
+		t.Fatalf("points:\nhave: %v\nwant: %v", have, want)
+	}
+	for i := 1; i < trail.numPoints; i++ {
+		prev := trail.points[trail.pointIndex(i-1)]
+		p := trail.points[trail.pointIndex(i)]
+		if prev.age < p.age {
+			t.Fatalf("point %d is older than its predecessor", i)
+		}
+	}
+}
+
+func TestTrailMinDistance(t *testing.T) {
+	var pos gmath.Vec
+	trail := NewTrail(16)
+	trail.Pos.Base = &pos
+	trail.SetMinDistance(5)
+
+	for i := 0; i <= 8; i++ {
+		pos.X = float64(i * 2)
+		trail.Update()
+	}
+
+	// The points closer than 5 pixels to the previous point are merged.
+	want := []float32{0, 6, 12}
+	if have := trailPositions(trail); !equalFloats(have, want) {
+		t.Fatalf("points:\nhave: %v\nwant: %v", have, want)
+	}
+
+	// Standing still doesn't add new points.
+	trail.Update()
+	trail.Update()
+	if trail.NumPoints() != 3 {
+		t.Fatalf("have %d points, want 3", trail.NumPoints())
+	}
+}
+
+func TestTrailDrawPoints(t *testing.T) {
+	var pos gmath.Vec
+	trail := NewTrail(8)
+	trail.Pos.Base = &pos
+
+	for i := 0; i < 5; i++ {
+		pos.X = float64(i * 10)
+		trail.Update()
+	}
+	pos.X = 45
+
+	points := trail.collectDrawPoints()
+	wantX := []float32{45, 40, 30, 20, 10, 0}
+	if len(points) != len(wantX) {
+		t.Fatalf("have %d draw points, want %d", len(points), len(wantX))
+	}
+	for i, p := range points {
+		if p.pos.X != wantX[i] {
+			t.Fatalf("draw point %d: have x=%v, want %v", i, p.pos.X, wantX[i])
+		}
+		if p.dist != 45-wantX[i] {
+			t.Fatalf("draw point %d: have dist=%v, want %v", i, p.dist, 45-wantX[i])
+		}
+	}
+
+	trail.SetMaxLength(20)
+	points = trail.collectDrawPoints()
+	last := points[len(points)-1]
+	if last.dist != 20 || last.pos.X != 25 {
+		t.Fatalf("the tail is not cut: %+v", last)
+	}
+}
@@ -1,6 +1,8 @@
 package graphics
 
 import (
+	"math"
+
 	"golang.org/x/exp/constraints"
 )
 
@@ -19,3 +21,16 @@ func setFlag[T constraints.Integer](flags *T, bit T, value bool) {
 		clearFlag(flags, bit)
 	}
 }
+
+// fposmod is like math.Mod, but the result is always in [0, y) range.
+func fposmod(x, y float64) float64 {
+	v := math.Mod(x, y)
+	if v < 0 {
+		v += y
+		// A tiny negative v can be rounded up to y.
+		if v == y {
+			v = 0
+		}
+	}
+	return v
+}
@@ -0,0 +1,76 @@
+package graphics
+
+import (
+	"testing"
+)
+
+func TestFposmod(t *testing.T) {
+	tests := []struct {
+		x    float64
+		y    float64
+		want float64
+	}{
+		{0, 10, 0},
+		{3, 10, 3},
+		{10, 10, 0},
+		{13, 10, 3},
+		{-3, 10, 7},
+		{-10, 10, 0},
+		{-13, 10, 7},
+		{2.5, 1, 0.5},
+		{-2.5, 1, 0.5},
+		{-1e-17, 10, 0},
+	}
+
+	for _, test := range tests {
+		have := fposmod(test.x, test.y)
+		if have != test.want {
+			t.Errorf("fposmod(%v, %v): have %v, want %v", test.x, test.y, have, test.want)
+		}
+		if have < 0 || have >= test.y {
+			t.Errorf("fposmod(%v, %v)=%v is out of [0, %v) range", test.x, test.y, have, test.y)
+		}
+	}
+}
+
+func TestSpriteFilterFlags(t *testing.T) {
+	CompileShaders()
+
+	if spriteFlagFilterBit1 != 1<<spriteFlagFilterShift {
+		t.Fatalf("filter bits don't match the filter shift")
+	}
+
+	filters := []Filter{FilterNearest, FilterLinear, FilterPixelated}
+	otherFlags := []spriteFlag{
+		0,
+		spriteFlagCentered | spriteFlagVisible,
+		spriteFlagCentered | spriteFlagFlipHorizontal | spriteFlagFlipVertical |
+			spriteFlagVisible | spriteFlagSubImageChanged | spriteFlagDisposed,
+	}
+
+	for _, flags := range otherFlags {
+		for _, f := range filters {
+			s := NewSprite()
+			s.flags = flags
+			// Set a different filter first to make sure
+			// the previous filter bits are cleared.
+			s.SetFilter(FilterPixelated)
+			s.SetFilter(f)
+			if s.GetFilter() != f {
+				t.Fatalf("flags=%08b: have filter %v, want %v", flags, s.GetFilter(), f)
+			}
+			if s.flags&^(spriteFlagFilterBit1|spriteFlagFilterBit2) != flags {
+				t.Fatalf("flags=%08b: SetFilter(%v) changed other flags: %08b", flags, f, s.flags)
+			}
+		}
+	}
+
+	s := NewSprite()
+	s.SetFilter(FilterPixelated)
+	s.SetCentered(false)
+	s.SetHorizontalFlip(true)
+	s.SetVisibility(false)
+	if s.GetFilter() != FilterPixelated {
+		t.Fatalf("flag setters changed the filter: %v", s.GetFilter())
+	}
+}